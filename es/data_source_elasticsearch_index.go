@@ -0,0 +1,128 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic5 "gopkg.in/olivere/elastic.v5"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// indexReadOnlySettings are set by Elasticsearch on creation, and can't be
+// used to create another index.
+var indexReadOnlySettings = []string{
+	"creation_date",
+	"provided_name",
+	"uuid",
+	"version",
+}
+
+func dataSourceElasticsearchIndex() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_index` can be used to retrieve the settings, mappings and aliases of an existing index, e.g. to create another index with the same definition.",
+		Read:        dataSourceElasticsearchIndexRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the index, or of an alias referring to a single index.",
+			},
+			"settings": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A JSON string of the settings of the index, without the settings set by Elasticsearch on creation, e.g. `index.uuid`.",
+			},
+			"mappings": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A JSON string of the mappings of the index.",
+			},
+			"aliases": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A JSON string of the aliases of the index.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchIndexRead(d *schema.ResourceData, m interface{}) error {
+	name := d.Get("name").(string)
+
+	var index string
+	var settings, mappings, aliases map[string]interface{}
+	var count int
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	// The response is keyed by the concrete index, also for an alias
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var r map[string]*elastic7.IndicesGetResponse
+		r, err = client.IndexGet(name).Do(context.TODO())
+		for i, resp := range r {
+			if i == name || len(r) == 1 {
+				index, settings, mappings, aliases = i, resp.Settings, resp.Mappings, resp.Aliases
+			}
+		}
+		count = len(r)
+	case *elastic6.Client:
+		var r map[string]*elastic6.IndicesGetResponse
+		r, err = client.IndexGet(name).Do(context.TODO())
+		for i, resp := range r {
+			if i == name || len(r) == 1 {
+				index, settings, mappings, aliases = i, resp.Settings, resp.Mappings, resp.Aliases
+			}
+		}
+		count = len(r)
+	default:
+		elastic5Client := client.(*elastic5.Client)
+		var r map[string]*elastic5.IndicesGetResponse
+		r, err = elastic5Client.IndexGet(name).Do(context.TODO())
+		for i, resp := range r {
+			if i == name || len(r) == 1 {
+				index, settings, mappings, aliases = i, resp.Settings, resp.Mappings, resp.Aliases
+			}
+		}
+		count = len(r)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error reading index %s: %+v", name, err)
+	}
+	if index == "" {
+		return fmt.Errorf("%s refers to %d indices, please use the name of a single index", name, count)
+	}
+
+	if indexSettings, ok := settings["index"].(map[string]interface{}); ok {
+		for _, key := range indexReadOnlySettings {
+			delete(indexSettings, key)
+		}
+	}
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	mappingsJSON, err := json.Marshal(mappings)
+	if err != nil {
+		return err
+	}
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(index)
+	ds := &resourceDataSetter{d: d}
+	ds.set("settings", string(settingsJSON))
+	ds.set("mappings", string(mappingsJSON))
+	ds.set("aliases", string(aliasesJSON))
+	return ds.err
+}
@@ -0,0 +1,68 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestElasticsearchIndexDataSourceRead(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/logs" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "logs-000001": {
+    "aliases": {"logs": {"is_write_index": true}},
+    "mappings": {"properties": {"message": {"type": "text"}}},
+    "settings": {
+      "index": {
+        "number_of_shards": "2",
+        "number_of_replicas": "1",
+        "creation_date": "1609459200000",
+        "provided_name": "logs-000001",
+        "uuid": "Xq4mWQnJSwSY5ELRKqZSRg",
+        "version": {"created": "7100099"}
+      }
+    }
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, dataSourceElasticsearchIndex().Schema, map[string]interface{}{
+		"name": "logs",
+	})
+	if err := dataSourceElasticsearchIndexRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if resourceData.Id() != "logs-000001" {
+		t.Errorf("expected the ID to be the concrete index, got %s", resourceData.Id())
+	}
+	expected := map[string]string{
+		"settings": `{"index": {"number_of_shards": "2", "number_of_replicas": "1"}}`,
+		"mappings": `{"properties": {"message": {"type": "text"}}}`,
+		"aliases":  `{"logs": {"is_write_index": true}}`,
+	}
+	for key, value := range expected {
+		var actual, wanted interface{}
+		if err := json.Unmarshal([]byte(resourceData.Get(key).(string)), &actual); err != nil {
+			t.Fatalf("%s: err: %s", key, err)
+		}
+		if err := json.Unmarshal([]byte(value), &wanted); err != nil {
+			t.Fatalf("%s: err: %s", key, err)
+		}
+		if !reflect.DeepEqual(actual, wanted) {
+			t.Errorf("expected %s to be %s, got %s", key, value, resourceData.Get(key))
+		}
+	}
+}
@@ -3,7 +3,6 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 
@@ -63,7 +62,7 @@ func dataSourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m
 	case *elastic6.Client:
 		id, body, err = elastic6Search(client, DESTINATION_INDEX, destinationName)
 	default:
-		err = errors.New("destination resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
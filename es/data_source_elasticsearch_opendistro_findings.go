@@ -0,0 +1,139 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+// findingsPageSize is the number of findings requested per page.
+const findingsPageSize = 100
+
+type findingsResponse struct {
+	TotalFindings int               `json:"total_findings"`
+	Findings      []findingDocument `json:"findings"`
+}
+
+type findingDocument struct {
+	Finding finding `json:"finding"`
+}
+
+type finding struct {
+	ID            string   `json:"id"`
+	RelatedDocIDs []string `json:"related_doc_ids"`
+	MonitorID     string   `json:"monitor_id"`
+	Timestamp     int64    `json:"timestamp"`
+}
+
+func dataSourceElasticsearchOpenDistroFindings() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_opendistro_findings` can be used to retrieve the findings of a document level monitor, i.e. the documents its queries matched.",
+		Read:        dataSourceElasticsearchOpenDistroFindingsRead,
+		Schema: map[string]*schema.Schema{
+			"monitor_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the document level monitor.",
+			},
+			"findings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The findings of the monitor.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"finding_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the finding.",
+						},
+						"document_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The IDs of the documents matched by the monitor.",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The time of the finding, in RFC3339 format.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchOpenDistroFindingsRead(d *schema.ResourceData, m interface{}) error {
+	monitorID := d.Get("monitor_id").(string)
+
+	findings, err := elasticsearchOpenDistroSearchFindings(monitorID, m)
+	if err != nil {
+		return err
+	}
+
+	flattened := make([]map[string]interface{}, 0, len(findings))
+	for _, f := range findings {
+		flattened = append(flattened, map[string]interface{}{
+			"finding_id":   f.ID,
+			"document_ids": f.RelatedDocIDs,
+			"timestamp":    time.Unix(0, f.Timestamp*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+		})
+	}
+
+	d.SetId(monitorID)
+	ds := &resourceDataSetter{d: d}
+	ds.set("findings", flattened)
+	return ds.err
+}
+
+// elasticsearchOpenDistroSearchFindings returns all findings of the monitor,
+// requesting them page by page.
+func elasticsearchOpenDistroSearchFindings(monitorID string, m interface{}) ([]finding, error) {
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		return nil, errors.New("findings data source not implemented prior to Elastic v7")
+	}
+
+	var findings []finding
+	for {
+		params := url.Values{}
+		params.Set("monitorId", monitorID)
+		params.Set("startIndex", strconv.Itoa(len(findings)))
+		params.Set("size", strconv.Itoa(findingsPageSize))
+
+		res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_plugins/_alerting/findings/_search",
+			Params: params,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error searching findings of monitor %s: %+v", monitorID, err)
+		}
+
+		response := new(findingsResponse)
+		if err := json.Unmarshal(res.Body, response); err != nil {
+			return nil, fmt.Errorf("error unmarshalling findings body: %+v: %+v", err, res.Body)
+		}
+
+		for _, f := range response.Findings {
+			findings = append(findings, f.Finding)
+		}
+
+		if len(response.Findings) == 0 || len(findings) >= response.TotalFindings {
+			return findings, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroFindingsRead(t *testing.T) {
+	pages := map[string]string{
+		"0": `{
+  "total_findings": 3,
+  "findings": [
+    {"finding": {"id": "f1", "related_doc_ids": ["1"], "monitor_id": "m1", "timestamp": 1609459200000}, "document_list": []},
+    {"finding": {"id": "f2", "related_doc_ids": ["2", "3"], "monitor_id": "m1", "timestamp": 1609459260000}, "document_list": []}
+  ]
+}`,
+		"2": `{
+  "total_findings": 3,
+  "findings": [
+    {"finding": {"id": "f3", "related_doc_ids": ["4"], "monitor_id": "m1", "timestamp": 1609459320000}, "document_list": []}
+  ]
+}`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_plugins/_alerting/findings/_search" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if monitorID := r.URL.Query().Get("monitorId"); monitorID != "m1" {
+			t.Errorf("expected monitorId m1, got %s", monitorID)
+		}
+		page, ok := pages[r.URL.Query().Get("startIndex")]
+		if !ok {
+			t.Errorf("unexpected startIndex %s", r.URL.Query().Get("startIndex"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, page)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, dataSourceElasticsearchOpenDistroFindings().Schema, map[string]interface{}{
+		"monitor_id": "m1",
+	})
+	if err := dataSourceElasticsearchOpenDistroFindingsRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if resourceData.Id() != "m1" {
+		t.Errorf("expected the ID to be the monitor ID, got %s", resourceData.Id())
+	}
+	if count := resourceData.Get("findings.#"); count != 3 {
+		t.Fatalf("expected 3 findings, got %v", count)
+	}
+	expected := map[string]string{
+		"findings.0.finding_id":     "f1",
+		"findings.0.timestamp":      "2021-01-01T00:00:00Z",
+		"findings.1.document_ids.1": "3",
+		"findings.2.finding_id":     "f3",
+		"findings.2.timestamp":      "2021-01-01T00:02:00Z",
+	}
+	for key, value := range expected {
+		if actual := resourceData.Get(key); actual != value {
+			t.Errorf("expected %s to be %s, got %v", key, value, actual)
+		}
+	}
+}
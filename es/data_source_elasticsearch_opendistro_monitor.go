@@ -0,0 +1,119 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+)
+
+const MONITOR_NAME_FIELD = "monitor.name.keyword"
+
+func dataSourceElasticsearchOpenDistroMonitor() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_opendistro_monitor` can be used to retrieve a monitor managed elsewhere by its name or ID.",
+		Read:        dataSourceElasticsearchOpenDistroMonitorRead,
+		Schema: map[string]*schema.Schema{
+			"monitor_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"monitor_id", "name"},
+				Description:  "The ID of the monitor to retrieve.",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"monitor_id", "name"},
+				Description:  "The name of the monitor to retrieve. It's an error if several monitors have the name.",
+			},
+			"body": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The normalized JSON body of the monitor.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchOpenDistroMonitorRead(d *schema.ResourceData, m interface{}) error {
+	// the flavor of the cluster is known once the client is created
+	if _, err := getClient(m.(*ProviderConf)); err != nil {
+		return err
+	}
+
+	basePath := openDistroMonitorsPath
+	if m.(*ProviderConf).flavor == OpenSearch {
+		basePath = openSearchMonitorsPath
+	}
+
+	monitorID := d.Get("monitor_id").(string)
+	if monitorID == "" {
+		var err error
+		monitorID, err = dataSourceElasticsearchOpenDistroSearchMonitor(basePath, d.Get("name").(string), m)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := resourceElasticsearchOpenDistroGetMonitorFrom(basePath, monitorID, m)
+	if err != nil {
+		return err
+	}
+
+	monitorJson, err := json.Marshal(res.Monitor)
+	if err != nil {
+		return err
+	}
+	monitorJsonNormalized, err := structure.NormalizeJsonString(string(monitorJson))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(res.ID)
+	ds := &resourceDataSetter{d: d}
+	ds.set("monitor_id", res.ID)
+	ds.set("name", res.Monitor["name"])
+	ds.set("body", monitorJsonNormalized)
+	return ds.err
+}
+
+// dataSourceElasticsearchOpenDistroSearchMonitor returns the ID of the only
+// monitor with the name, searching the monitors of the alerting API.
+func dataSourceElasticsearchOpenDistroSearchMonitor(basePath string, name string, m interface{}) (string, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				MONITOR_NAME_FIELD: name,
+			},
+		},
+	}
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := resourceElasticsearchOpenDistroMonitorRequest("POST", basePath+"/_search", nil, query, &response, m); err != nil {
+		return "", fmt.Errorf("error searching monitors named %q: %+v", name, err)
+	}
+
+	var ids []string
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no monitor named %q found", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d monitors are named %q, look up one of them by its monitor_id instead: %s", len(ids), name, strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,87 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroMonitorDataSourceByName(t *testing.T) {
+	var hits []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "2.5.0", "distribution": "opensearch"}}`)
+		case r.Method == "POST" && r.URL.Path == "/_plugins/_alerting/monitors/_search":
+			var query map[string]map[string]map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			if name := query["query"]["term"][MONITOR_NAME_FIELD]; name != "errors" {
+				t.Errorf("expected to search monitors named errors, got %q", name)
+			}
+			var docs []string
+			for _, id := range hits {
+				docs = append(docs, fmt.Sprintf(`{"_id": %q, "_source": {"type": "monitor", "name": "errors"}}`, id))
+			}
+			fmt.Fprintf(w, `{"hits": {"total": {"value": %d}, "hits": [%s]}}`, len(hits), strings.Join(docs, ","))
+		case r.Method == "GET" && r.URL.Path == "/_plugins/_alerting/monitors/mhMBN3UBz5Fc7aRgo3Gl":
+			fmt.Fprint(w, `{
+  "_id": "mhMBN3UBz5Fc7aRgo3Gl",
+  "_version": 1,
+  "_seq_no": 3,
+  "_primary_term": 1,
+  "monitor": {
+    "type": "monitor",
+    "name": "errors",
+    "enabled": true,
+    "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+    "inputs": [],
+    "triggers": [{"id": "nBMBN3UBz5Fc7aRgo3Gl", "name": "any", "severity": "1", "condition": {"script": {"source": "return true", "lang": "painless"}}, "actions": []}]
+  }
+}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": nil,
+	})
+
+	hits = []string{"mhMBN3UBz5Fc7aRgo3Gl"}
+	resourceData := schema.TestResourceDataRaw(t, dataSourceElasticsearchOpenDistroMonitor().Schema, map[string]interface{}{
+		"name": "errors",
+	})
+	if err := dataSourceElasticsearchOpenDistroMonitorRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if resourceData.Id() != "mhMBN3UBz5Fc7aRgo3Gl" || resourceData.Get("monitor_id") != "mhMBN3UBz5Fc7aRgo3Gl" {
+		t.Errorf("expected the monitor to be resolved by name, got %q", resourceData.Id())
+	}
+	expected := `{"enabled":true,"inputs":[],"name":"errors","schedule":{"period":{"interval":1,"unit":"MINUTES"}},"triggers":[{"actions":[],"condition":{"script":{"source":"return true"}},"name":"any","severity":"1"}],"type":"monitor"}`
+	if body := resourceData.Get("body").(string); !diffSuppressMonitor("body", body, expected, nil) {
+		t.Errorf("expected the normalized body %s, got %s", expected, body)
+	}
+
+	hits = []string{"mhMBN3UBz5Fc7aRgo3Gl", "nBMBN3UBz5Fc7aRgo3Gl"}
+	resourceData = schema.TestResourceDataRaw(t, dataSourceElasticsearchOpenDistroMonitor().Schema, map[string]interface{}{
+		"name": "errors",
+	})
+	if err := dataSourceElasticsearchOpenDistroMonitorRead(resourceData, meta); err == nil || !strings.Contains(err.Error(), "2 monitors are named") {
+		t.Errorf("expected an error about the ambiguous name, got %v", err)
+	}
+
+	hits = nil
+	if err := dataSourceElasticsearchOpenDistroMonitorRead(resourceData, meta); err == nil || !strings.Contains(err.Error(), "no monitor named") {
+		t.Errorf("expected an error about the missing monitor, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package es
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func dataSourceElasticsearchOpenDistroRole() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_opendistro_role` can be used to check whether a security role exists, e.g. to only create it when it doesn't, and to retrieve its definition.",
+		Read:        dataSourceElasticsearchOpenDistroRoleRead,
+		Schema: map[string]*schema.Schema{
+			"role_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the role.",
+			},
+			"exists": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role exists. The other attributes are empty if it doesn't.",
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"cluster_permissions": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"index_permissions": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index_patterns": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"document_level_security": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"field_level_security": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"masked_fields": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"allowed_actions": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"tenant_permissions": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tenant_patterns": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"allowed_actions": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchOpenDistroRoleRead(d *schema.ResourceData, m interface{}) error {
+	name := d.Get("role_name").(string)
+	d.SetId(name)
+
+	res, err := resourceElasticsearchGetOpenDistroRole(name, m)
+	if elastic7.IsNotFound(err) {
+		return d.Set("exists", false)
+	}
+	if err != nil {
+		return securityPluginError(fmt.Sprintf("role %s", name), err)
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("exists", true)
+	ds.set("description", res.Description)
+	ds.set("cluster_permissions", res.ClusterPermissions)
+	ds.set("index_permissions", flattenIndexPermissions(res.IndexPermissions, d))
+	ds.set("tenant_permissions", flattenTenantPermissions(res.TenantPermissions))
+	return ds.err
+}
+
+// securityPluginError returns a clear error when the security API doesn't
+// exist, i.e. the cluster runs without the security plugin.
+func securityPluginError(object string, err error) error {
+	if elastic7.IsStatusCode(err, 400) || elastic7.IsStatusCode(err, 405) {
+		return fmt.Errorf("error reading %s, the security plugin doesn't seem to be installed on the cluster: %+v", object, err)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroRoleDataSourceExists(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_opendistro/_security/api/roles/logs_reader":
+			fmt.Fprint(w, `{"logs_reader": {
+  "reserved": false,
+  "hidden": false,
+  "description": "Reads logs",
+  "cluster_permissions": ["cluster_composite_ops_ro"],
+  "index_permissions": [{"index_patterns": ["logs-*"], "dls": "", "fls": [], "masked_fields": [], "allowed_actions": ["read"]}],
+  "tenant_permissions": [],
+  "static": false
+}}`)
+		case "/_opendistro/_security/api/roles/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status": "NOT_FOUND", "message": "Resource 'missing' not found."}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	dataSource := dataSourceElasticsearchOpenDistroRole()
+
+	d := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{"role_name": "logs_reader"})
+	if err := dataSourceElasticsearchOpenDistroRoleRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !d.Get("exists").(bool) || d.Get("description") != "Reads logs" {
+		t.Errorf("expected the existing role to be read, got exists %v and description %q", d.Get("exists"), d.Get("description"))
+	}
+	if permissions := d.Get("index_permissions").(*schema.Set).List(); len(permissions) != 1 {
+		t.Errorf("expected the index permissions of the role, got %v", permissions)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{"role_name": "missing"})
+	if err := dataSourceElasticsearchOpenDistroRoleRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Get("exists").(bool) || d.Id() != "missing" {
+		t.Errorf("expected the missing role not to exist, got exists %v and ID %q", d.Get("exists"), d.Id())
+	}
+}
+
+func TestOpenDistroRoleDataSourceWithoutSecurityPlugin(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "no handler found for uri [%s] and method [GET]"}`, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	d := schema.TestResourceDataRaw(t, dataSourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{"role_name": "logs_reader"})
+	err := dataSourceElasticsearchOpenDistroRoleRead(d, meta)
+	if err == nil || !strings.Contains(err.Error(), "the security plugin doesn't seem to be installed") {
+		t.Errorf("expected an error about the security plugin, got %v", err)
+	}
+}
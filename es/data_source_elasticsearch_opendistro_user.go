@@ -0,0 +1,68 @@
+package es
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func dataSourceElasticsearchOpenDistroUser() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_opendistro_user` can be used to check whether an internal user exists, e.g. to only create it when it doesn't, and to retrieve its definition.",
+		Read:        dataSourceElasticsearchOpenDistroUserRead,
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the user.",
+			},
+			"exists": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the user exists. The other attributes are empty if it doesn't.",
+			},
+			"backend_roles": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"opendistro_security_roles": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchOpenDistroUserRead(d *schema.ResourceData, m interface{}) error {
+	name := d.Get("username").(string)
+	d.SetId(name)
+
+	res, err := resourceElasticsearchGetOpenDistroUser(name, m)
+	if elastic7.IsNotFound(err) {
+		return d.Set("exists", false)
+	}
+	if err != nil {
+		return securityPluginError(fmt.Sprintf("user %s", name), err)
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("exists", true)
+	ds.set("backend_roles", res.BackendRoles)
+	ds.set("opendistro_security_roles", res.SecurityRoles)
+	ds.set("attributes", res.Attributes)
+	ds.set("description", res.Description)
+	return ds.err
+}
@@ -0,0 +1,57 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroUserDataSourceExists(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_opendistro/_security/api/internalusers/jane":
+			fmt.Fprint(w, `{"jane": {
+  "hash": "",
+  "reserved": false,
+  "hidden": false,
+  "backend_roles": ["readers"],
+  "attributes": {"team": "logs"},
+  "opendistro_security_roles": [],
+  "description": "Jane",
+  "static": false
+}}`)
+		case "/_opendistro/_security/api/internalusers/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status": "NOT_FOUND", "message": "Resource 'missing' not found."}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	dataSource := dataSourceElasticsearchOpenDistroUser()
+
+	d := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{"username": "jane"})
+	if err := dataSourceElasticsearchOpenDistroUserRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !d.Get("exists").(bool) || d.Get("attributes.team") != "logs" {
+		t.Errorf("expected the existing user to be read, got exists %v and attributes %v", d.Get("exists"), d.Get("attributes"))
+	}
+	if roles := d.Get("backend_roles").(*schema.Set).List(); len(roles) != 1 || roles[0] != "readers" {
+		t.Errorf("expected the backend roles of the user, got %v", roles)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{"username": "missing"})
+	if err := dataSourceElasticsearchOpenDistroUserRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Get("exists").(bool) {
+		t.Error("expected the missing user not to exist")
+	}
+}
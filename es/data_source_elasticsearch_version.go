@@ -0,0 +1,50 @@
+package es
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceElasticsearchVersion() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_version` can be used to retrieve the version of the cluster of the provider, e.g. to choose settings supported by that version. The version is detected once per provider, from the root endpoint also used to check connectivity.",
+		Read:        dataSourceElasticsearchVersionRead,
+
+		Schema: map[string]*schema.Schema{
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version number of the cluster, e.g. `7.10.2`.",
+			},
+			"distribution": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The distribution of the cluster, `elasticsearch` or `opensearch`.",
+			},
+			"build_flavor": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The build flavor of the cluster, e.g. `default` or `oss`. Empty if not reported by the cluster.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchVersionRead(d *schema.ResourceData, m interface{}) error {
+	info, err := getRootInfo(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	// only OpenSearch reports its distribution
+	distribution := info.Version.Distribution
+	if distribution == "" {
+		distribution = "elasticsearch"
+	}
+
+	d.SetId(info.Version.Number)
+	ds := &resourceDataSetter{d: d}
+	ds.set("version", info.Version.Number)
+	ds.set("distribution", distribution)
+	ds.set("build_flavor", info.Version.BuildFlavor)
+	return ds.err
+}
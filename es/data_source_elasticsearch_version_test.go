@@ -0,0 +1,51 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestElasticsearchVersionDataSourceRead(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "name": "node-1",
+  "cluster_name": "opensearch",
+  "version": {"distribution": "opensearch", "number": "1.3.2", "build_type": "tar"},
+  "tagline": "The OpenSearch Project: https://opensearch.org/"
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	for i := 0; i < 2; i++ {
+		resourceData := schema.TestResourceDataRaw(t, dataSourceElasticsearchVersion().Schema, map[string]interface{}{})
+		if err := dataSourceElasticsearchVersionRead(resourceData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if v := resourceData.Get("version"); v != "1.3.2" {
+			t.Errorf("expected version 1.3.2, got %v", v)
+		}
+		if v := resourceData.Get("distribution"); v != "opensearch" {
+			t.Errorf("expected distribution opensearch, got %v", v)
+		}
+		if v := resourceData.Get("build_flavor"); v != "" {
+			t.Errorf("expected no build_flavor, got %v", v)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the root endpoint to be requested once, got %d requests", requests)
+	}
+}
@@ -51,7 +51,26 @@ func diffSuppressComposableIndexTemplate(k, old, new string, d *schema.ResourceD
 	return reflect.DeepEqual(oo, no)
 }
 
+// diffSuppressComponentTemplate compares the bodies of component templates,
+// which have the shape of the ones of composable index templates.
+func diffSuppressComponentTemplate(k, old, new string, d *schema.ResourceData) bool {
+	return diffSuppressComposableIndexTemplate(k, old, new, d)
+}
+
 func diffSuppressDestination(k, old, new string, d *schema.ResourceData) bool {
+	return equivalentDestinations(old, new, d != nil && d.Get("preserve_unknown_fields").(bool))
+}
+
+// diffSuppressDestinations compares the bodies of the destinations of
+// elasticsearch_opendistro_destinations, which has no preserve_unknown_fields.
+func diffSuppressDestinations(k, old, new string, d *schema.ResourceData) bool {
+	return equivalentDestinations(old, new, false)
+}
+
+// equivalentDestinations compares the bodies of destinations, ignoring the
+// fields set by the server and, with preserveUnknownFields, the fields of old
+// missing from new.
+func equivalentDestinations(old, new string, preserveUnknownFields bool) bool {
 	var oo, no interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
 		return false
@@ -68,6 +87,55 @@ func diffSuppressDestination(k, old, new string, d *schema.ResourceData) bool {
 		normalizeDestination(nm)
 	}
 
+	// the server may return scalars in another representation than written
+	oo, no = normalizedScalars(oo), normalizedScalars(no)
+
+	if preserveUnknownFields {
+		dropUnknownFields(oo, no)
+	}
+
+	return reflect.DeepEqual(oo, no)
+}
+
+// dropUnknownFields removes the keys of objects in old which are missing from
+// the corresponding objects in new.
+func dropUnknownFields(old, new interface{}) {
+	om, ok := old.(map[string]interface{})
+	if !ok {
+		return
+	}
+	nm, ok := new.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for k, v := range om {
+		if nv, ok := nm[k]; ok {
+			dropUnknownFields(v, nv)
+		} else {
+			delete(om, k)
+		}
+	}
+}
+
+// diffSuppressKibanaObject compares kibana objects, ignoring the fields which
+// Kibana adds to the objects, e.g. updated_at or migrationVersion.
+func diffSuppressKibanaObject(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no []interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+	if len(oo) != len(no) {
+		return false
+	}
+
+	for i := range oo {
+		dropUnknownFields(oo[i], no[i])
+	}
+
 	return reflect.DeepEqual(oo, no)
 }
 
@@ -91,6 +159,86 @@ func diffSuppressMonitor(k, old, new string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oo, no)
 }
 
+func diffSuppressAnomalyDetector(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+
+	if om, ok := oo.(map[string]interface{}); ok {
+		normalizeAnomalyDetector(om)
+	}
+
+	if nm, ok := no.(map[string]interface{}); ok {
+		normalizeAnomalyDetector(nm)
+	}
+
+	return reflect.DeepEqual(oo, no)
+}
+
+func diffSuppressTransform(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+
+	if om, ok := oo.(map[string]interface{}); ok {
+		normalizeTransform(om)
+	}
+
+	if nm, ok := no.(map[string]interface{}); ok {
+		normalizeTransform(nm)
+	}
+
+	return reflect.DeepEqual(oo, no)
+}
+
+func diffSuppressIndexTimeValue(k, old, new string, d *schema.ResourceData) bool {
+	return canonicalTimeValue(old) == canonicalTimeValue(new)
+}
+
+func diffSuppressIndexByteSize(k, old, new string, d *schema.ResourceData) bool {
+	return canonicalByteSize(old) == canonicalByteSize(new)
+}
+
+func diffSuppressIndexAliases(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+
+	if om, ok := oo.(map[string]interface{}); ok {
+		normalizeIndexAliases(om)
+	}
+
+	if nm, ok := no.(map[string]interface{}); ok {
+		normalizeIndexAliases(nm)
+	}
+
+	return reflect.DeepEqual(oo, no)
+}
+
+func diffSuppressAliasFilter(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedQuery(oo), normalizedQuery(no))
+}
+
 func suppressEquivalentJson(k, old, new string, d *schema.ResourceData) bool {
 	var oldObj, newObj interface{}
 	if err := json.Unmarshal([]byte(old), &oldObj); err != nil {
@@ -102,6 +250,10 @@ func suppressEquivalentJson(k, old, new string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oldObj, newObj)
 }
 
+func diffSuppressDocumentLevelSecurity(k, old, new string, d *schema.ResourceData) bool {
+	return normalizedDocumentLevelSecurity(old) == normalizedDocumentLevelSecurity(new)
+}
+
 func diffSuppressIndexLifecyclePolicy(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
@@ -151,6 +303,15 @@ func diffSuppressIngestPipeline(k, old, new string, d *schema.ResourceData) bool
 		return false
 	}
 
+	// the owner stamped by the ownership guard isn't part of the configuration
+	if d != nil && d.Get("ownership_guard").(bool) {
+		for _, v := range []interface{}{oo, no} {
+			if pipeline, ok := v.(map[string]interface{}); ok {
+				normalizeIngestPipelineOwner(pipeline)
+			}
+		}
+	}
+
 	return reflect.DeepEqual(oo, no)
 }
 
@@ -182,5 +343,9 @@ func diffSuppressLicense(k, old, new string, d *schema.ResourceData) bool {
 	if err := json.Unmarshal([]byte(new), &newObj); err != nil {
 		return false
 	}
+
+	normalizeLicense(oldObj)
+	normalizeLicense(newObj)
+
 	return reflect.DeepEqual(oldObj, newObj)
 }
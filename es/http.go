@@ -1,9 +1,41 @@
 package es
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
+// sensitiveHeaderFragments are matched case insensitively against header
+// names to decide whether a header value may be logged.
+var sensitiveHeaderFragments = []string{
+	"authorization",
+	"cookie",
+	"key",
+	"password",
+	"secret",
+	"token",
+}
+
+// sensitiveBodyFragments are matched case insensitively against the keys of
+// JSON bodies to decide whether a value may be logged.
+var sensitiveBodyFragments = []string{
+	"authorization",
+	"api_key",
+	"access_key",
+	"hash",
+	"password",
+	"secret",
+	"token",
+}
+
 type withHeader struct {
 	http.Header
 	rt http.RoundTripper
@@ -24,3 +56,258 @@ func (h withHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	return h.rt.RoundTrip(req)
 }
+
+// withBearerTokenFile sets a bearer token read from a file as the
+// Authorization header of every request. The file is read again for each
+// request, so a token rotated on disk is used without reconfiguring the
+// provider.
+type withBearerTokenFile struct {
+	path string
+	rt   http.RoundTripper
+}
+
+func WithBearerTokenFile(rt http.RoundTripper, path string) withBearerTokenFile {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return withBearerTokenFile{path: path, rt: rt}
+}
+
+func (t withBearerTokenFile) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := readBearerTokenFile(t.path)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.rt.RoundTrip(req)
+}
+
+// readBearerTokenFile returns the token in the file, without surrounding
+// whitespace such as a trailing newline.
+func readBearerTokenFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading bearer_token_file: %+v", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("bearer_token_file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+// withOAuthToken sets an access token of the OAuth2 client credentials flow
+// as the Authorization header of every request.
+type withOAuthToken struct {
+	source *oauthTokenSource
+	rt     http.RoundTripper
+}
+
+func WithOAuthToken(rt http.RoundTripper, source *oauthTokenSource) withOAuthToken {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return withOAuthToken{source: source, rt: rt}
+}
+
+func (t withOAuthToken) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.rt.RoundTrip(req)
+}
+
+// oauthTokenExpiryDelta is how long before its expiry a token is refreshed,
+// so that it doesn't expire while a request is in flight.
+const oauthTokenExpiryDelta = 10 * time.Second
+
+// oauthTokenSource fetches access tokens from the token endpoint of an
+// identity provider with the OAuth2 client credentials flow, and caches them
+// until they are about to expire. It is shared by the concurrent operations
+// of resources, only one of them fetches a token at a time.
+type oauthTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns the cached access token, or a new one if it is about to
+// expire. Tokens without an expiry are cached for the life of the provider.
+func (s *oauthTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Add(oauthTokenExpiryDelta).Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	req, err := http.NewRequest("POST", s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error building OAuth token request: %+v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// the credentials are form encoded before they are encoded for basic auth,
+	// see https://tools.ietf.org/html/rfc6749#section-2.3.1
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error fetching OAuth token from %s: %+v", s.tokenURL, err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading OAuth token response: %+v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching OAuth token from %s: %s: %s", s.tokenURL, res.Status, body)
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling OAuth token response: %+v", err)
+	}
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("no access_token in the OAuth token response of %s", s.tokenURL)
+	}
+
+	s.token = response.AccessToken
+	s.expiry = time.Time{}
+	if response.ExpiresIn > 0 {
+		s.expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	log.Printf("[DEBUG] Fetched OAuth token from %s, expiring at %s", s.tokenURL, s.expiry)
+
+	return s.token, nil
+}
+
+// redactHeaderValue returns the value of a header suitable for logging,
+// hiding the values of headers that likely carry credentials.
+func redactHeaderValue(name string, value string) string {
+	lower := strings.ToLower(name)
+	for _, fragment := range sensitiveHeaderFragments {
+		if strings.Contains(lower, fragment) {
+			return "<redacted>"
+		}
+	}
+
+	return value
+}
+
+type withDebugLogging struct {
+	rt http.RoundTripper
+}
+
+// WithDebugLogging logs the method, path, headers and body of every request,
+// and the status and body of every response, redacting credentials.
+func WithDebugLogging(rt http.RoundTripper) withDebugLogging {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return withDebugLogging{rt: rt}
+}
+
+func (l withDebugLogging) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	headers := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		headers = append(headers, k+": "+redactHeaderValue(k, req.Header.Get(k)))
+	}
+	log.Printf("[DEBUG] Request %s %s\n%s\n%s", req.Method, req.URL.RequestURI(), strings.Join(headers, "\n"), redactBody(reqBody))
+
+	res, err := l.rt.RoundTrip(req)
+	if err != nil {
+		log.Printf("[DEBUG] Request %s %s failed: %+v", req.Method, req.URL.RequestURI(), err)
+		return res, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+	if err != nil {
+		return res, err
+	}
+	log.Printf("[DEBUG] Response %s %s: %s\n%s", req.Method, req.URL.RequestURI(), res.Status, redactBody(resBody))
+
+	return res, nil
+}
+
+// redactBody returns a body suitable for logging, hiding the values of JSON
+// keys that likely hold credentials. Bodies that aren't JSON are logged as is.
+func redactBody(body []byte) string {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return string(body)
+	}
+
+	return string(redacted)
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, value := range t {
+			if isSensitiveBodyKey(k) {
+				t[k] = "<redacted>"
+			} else {
+				t[k] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range t {
+			t[i] = redactJSON(value)
+		}
+	}
+
+	return v
+}
+
+func isSensitiveBodyKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, fragment := range sensitiveBodyFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,22 +4,32 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
 	awsstscreds "github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	awssession "github.com/aws/aws-sdk-go/aws/session"
 	awssigv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	awssts "github.com/aws/aws-sdk-go/service/sts"
 	"github.com/deoxxa/aws_signing_client"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/pathorcontents"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
@@ -28,10 +38,21 @@ import (
 
 var awsUrlRegexp = regexp.MustCompile(`([a-z0-9-]+).es.amazonaws.com$`)
 
+// ServerFlavor is the distribution of the cluster the provider talks to.
+type ServerFlavor int
+
+const (
+	Unknown ServerFlavor = iota
+	Elasticsearch
+	OpenSearch
+)
+
 type ProviderConf struct {
 	rawUrl             string
+	rawUrls            []string
 	insecure           bool
 	sniffing           bool
+	sniffingConfigured bool
 	healthchecking     bool
 	cacertFile         string
 	username           string
@@ -41,6 +62,7 @@ type ProviderConf struct {
 	parsedUrl          *url.URL
 	signAWSRequests    bool
 	esVersion          string
+	flavor             ServerFlavor
 	awsRegion          string
 	awsAssumeRoleArn   string
 	awsAccessKeyId     string
@@ -49,28 +71,58 @@ type ProviderConf struct {
 	awsProfile         string
 	certPemPath        string
 	keyPemPath         string
+	clientCertificate  *tls.Certificate
+	headers            map[string]string
+	proxyUrl           *url.URL
+	debugLogging       bool
+	compression        bool
+	requestTimeout     time.Duration
+	securityBatcher    *patchBatcher
+
+	// the page size of the API listing destinations, see destinationsPageSize
+	destinationsPageSize int
+
+	// the file of a bearer token, read again for every request
+	bearerTokenFile string
+
+	// the access tokens of the OAuth2 client credentials flow, if configured
+	oauthTokens *oauthTokenSource
+
+	// the tuning of the connections kept alive by the transport
+	maxIdleConns    int
+	idleConnTimeout time.Duration
+
+	// the client is created, and the version detected, once per configuration
+	clientOnce sync.Once
+	client     interface{}
+	clientErr  error
+
+	// the response of the root endpoint, kept from detecting the version
+	rootInfoOnce sync.Once
+	rootInfo     *rootInfo
+	rootInfoErr  error
 }
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"url": {
 				Type:        schema.TypeString,
 				Required:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_URL", nil),
-				Description: "Elasticsearch URL",
+				Description: "Elasticsearch URL, or a comma separated list of the URLs of several nodes of the cluster to fail over between.",
 			},
 			"sniff": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_SNIFF", true),
-				Description: "Set the node sniffing option for the elastic client. Client won't work with sniffing if nodes are not routable.",
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_SNIFF", nil),
+				Description: "Set the node sniffing option for the elastic client. Client won't work with sniffing if nodes are not routable. Defaults to true, unless the url refers to a cloud endpoint behind a load balancer, e.g. of AWS or Elastic Cloud, or requests are signed, authenticated with a token, sent with TLS options or through a proxy.",
 			},
 			"healthcheck": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_HEALTH", true),
-				Description: "Set the client healthcheck option for the elastic client. Healthchecking is designed for direct access to the cluster.",
+				Description: "Set the client healthcheck option for the elastic client, and check connectivity, credentials and version of the cluster when the provider is configured. Healthchecking is designed for direct access to the cluster.",
 			},
 			"username": {
 				Type:        schema.TypeString,
@@ -96,6 +148,49 @@ func Provider() terraform.ResourceProvider {
 				Default:     "ApiKey",
 				Description: "The type of token, usually ApiKey or Bearer",
 			},
+			"bearer_token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_BEARER_TOKEN_FILE", ""),
+				Description: "A file containing a bearer token for an Authorization header. The file is read again for every request, so tokens rotated on disk are picked up during long running applies.",
+			},
+			"oauth_token_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The token endpoint of an identity provider to fetch bearer tokens from with the OAuth2 client credentials flow, together with `oauth_client_id` and `oauth_client_secret`. Tokens are refreshed when they are about to expire.",
+			},
+			"oauth_client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The client ID to fetch OAuth2 tokens with from `oauth_token_url`.",
+			},
+			"oauth_client_secret": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_OAUTH_CLIENT_SECRET", ""),
+				Description: "The client secret to fetch OAuth2 tokens with from `oauth_token_url`.",
+			},
+			"oauth_scopes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The scopes to request OAuth2 tokens for, the default scopes of the client if empty.",
+			},
+			"api_key_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The ID of an API key to authenticate with, together with `api_key_value`. Takes precedence over basic auth.",
+			},
+			"api_key_value": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The value of the API key with the ID `api_key_id`.",
+			},
 			"aws_assume_role_arn": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -134,7 +229,7 @@ func Provider() terraform.ResourceProvider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "",
-				Description: "The AWS region for use in signing of AWS elasticsearch requests. Must be specified in order to use AWS URL signing with AWS ElasticSearch endpoint exposed on a custom DNS domain.",
+				Description: "The AWS region for use in signing of AWS elasticsearch requests. Defaults to the region of the `url` of an AWS domain, then to the `AWS_REGION` environment variable, then to the region of the EC2 instance metadata.",
 			},
 
 			"cacert_file": {
@@ -154,21 +249,21 @@ func Provider() terraform.ResourceProvider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "",
-				Description: "A X509 certificate to connect to elasticsearch",
+				Description: "A X509 certificate to connect to elasticsearch, as a path to a PEM file or the PEM encoded certificate itself",
 				DefaultFunc: schema.EnvDefaultFunc("ES_CLIENT_CERTIFICATE_PATH", ""),
 			},
 			"client_key_path": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "",
-				Description: "A X509 key to connect to elasticsearch",
+				Description: "A X509 key to connect to elasticsearch, as a path to a PEM file or the PEM encoded key itself",
 				DefaultFunc: schema.EnvDefaultFunc("ES_CLIENT_KEY_PATH", ""),
 			},
 			"sign_aws_requests": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     true,
-				Description: "Enable signing of AWS elasticsearch requests. The `url` must refer to AWS ES domain (`*.<region>.es.amazonaws.com`), or `aws_region` must be specified explicitly.",
+				Description: "Enable signing of AWS elasticsearch requests. Requests are signed when the `url` refers to an AWS ES domain (`*.<region>.es.amazonaws.com`) or any of the `aws_*` options are set.",
 			},
 			"elasticsearch_version": {
 				Type:        schema.TypeString,
@@ -176,20 +271,100 @@ func Provider() terraform.ResourceProvider {
 				Default:     "",
 				Description: "ElasticSearch Version",
 			},
+			"flavor": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				ValidateFunc: validation.StringInSlice([]string{"", "elasticsearch", "opensearch"}, false),
+				Description:  "The distribution of the cluster, `elasticsearch` or `opensearch`. Detected together with the version unless `elasticsearch_version` is set, then it defaults to `elasticsearch`.",
+			},
+			"headers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A map of static HTTP headers to send with every request, e.g. an API gateway key or a tenant identifier.",
+			},
+			"debug_logging": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Log the method, path, headers and body of every request and response, with credentials redacted. The logs are shown with `TF_LOG=DEBUG`.",
+			},
+			"enable_compression": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Compress the bodies of requests with gzip, e.g. large monitors and ISM policies sent to remote clusters. Opt-in, as not every cluster or proxy accepts compressed requests.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				Description:  "The maximum duration of any request to the cluster, as a Go duration string, e.g. `90s` or `5m`. Defaults to `0s`, which means no timeout.",
+				ValidateFunc: validateDuration,
+			},
+			"max_idle_conns": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxIdleConns,
+				Description:  "The maximum number of idle connections kept alive to the cluster, in total and per node, to reuse them for later requests. `0` disables keep-alive, closing connections after each request.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"idle_conn_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultIdleConnTimeout,
+				Description:  "How long an idle connection is kept alive before it is closed, as a Go duration string, e.g. `90s`. `0s` keeps idle connections alive indefinitely.",
+				ValidateFunc: validateNonNegativeDuration,
+			},
+			"batch_security_requests": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Send the changes of opendistro roles and role mappings made within 500ms of each other, e.g. by the same apply, as a single PATCH request per API, which is faster for large configurations. When a batch fails, its changes are sent again one at a time, so only the failing changes fail.",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "URL of an http, https or socks5 proxy to route requests through. Defaults to the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables.",
+			},
+			"destinations_page_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultDestinationsPageSize,
+				Description:  "The number of destinations requested per page when destinations are listed, e.g. to read a destination when the alerting config index isn't readable. Larger pages need fewer requests on clusters with many destinations, smaller pages smaller responses. Between 1 and 10000.",
+				ValidateFunc: validation.IntBetween(1, 10000),
+			},
+			"path_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_PATH_PREFIX", ""),
+				Description: "Path prefix of the cluster behind a reverse proxy, e.g. `/es`, prepended to the path of every request. Disables sniffing by default, as the addresses of sniffed nodes don't have the prefix.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
+			"elasticsearch_alias":                           resourceElasticsearchAlias(),
+			"elasticsearch_cluster_settings":                resourceElasticsearchClusterSettings(),
 			"elasticsearch_destination":                     resourceElasticsearchDeprecatedDestination(),
 			"elasticsearch_index":                           resourceElasticsearchIndex(),
 			"elasticsearch_index_lifecycle_policy":          resourceElasticsearchDeprecatedIndexLifecyclePolicy(),
 			"elasticsearch_index_template":                  resourceElasticsearchIndexTemplate(),
+			"elasticsearch_component_template":              resourceElasticsearchComponentTemplate(),
 			"elasticsearch_composable_index_template":       resourceElasticsearchComposableIndexTemplate(),
+			"elasticsearch_data_stream":                     resourceElasticsearchDataStream(),
 			"elasticsearch_ingest_pipeline":                 resourceElasticsearchIngestPipeline(),
 			"elasticsearch_kibana_object":                   resourceElasticsearchKibanaObject(),
 			"elasticsearch_monitor":                         resourceElasticsearchDeprecatedMonitor(),
+			"elasticsearch_reindex":                         resourceElasticsearchReindex(),
+			"elasticsearch_script":                          resourceElasticsearchScript(),
 			"elasticsearch_snapshot_repository":             resourceElasticsearchSnapshotRepository(),
+			"elasticsearch_transform":                       resourceElasticsearchTransform(),
 			"elasticsearch_watch":                           resourceElasticsearchDeprecatedWatch(),
+			"elasticsearch_opendistro_anomaly_detector":     resourceElasticsearchOpenDistroAnomalyDetector(),
 			"elasticsearch_opendistro_destination":          resourceElasticsearchOpenDistroDestination(),
+			"elasticsearch_opendistro_destinations":         resourceElasticsearchOpenDistroDestinations(),
 			"elasticsearch_opendistro_ism_policy":           resourceElasticsearchOpenDistroISMPolicy(),
 			"elasticsearch_opendistro_ism_policy_mapping":   resourceElasticsearchOpenDistroISMPolicyMapping(),
 			"elasticsearch_opendistro_monitor":              resourceElasticsearchOpenDistroMonitor(),
@@ -197,6 +372,9 @@ func Provider() terraform.ResourceProvider {
 			"elasticsearch_opendistro_role":                 resourceElasticsearchOpenDistroRole(),
 			"elasticsearch_opendistro_user":                 resourceElasticsearchOpenDistroUser(),
 			"elasticsearch_opendistro_kibana_tenant":        resourceElasticsearchOpenDistroKibanaTenant(),
+			"elasticsearch_opendistro_tenant":               resourceElasticsearchOpenDistroKibanaTenant(),
+			"elasticsearch_opensearch_channel":              resourceElasticsearchOpenSearchChannel(),
+			"elasticsearch_opensearch_role":                 resourceElasticsearchOpenSearchRole(),
 			"elasticsearch_xpack_index_lifecycle_policy":    resourceElasticsearchXpackIndexLifecyclePolicy(),
 			"elasticsearch_xpack_license":                   resourceElasticsearchXpackLicense(),
 			"elasticsearch_xpack_role":                      resourceElasticsearchXpackRole(),
@@ -209,34 +387,99 @@ func Provider() terraform.ResourceProvider {
 		DataSourcesMap: map[string]*schema.Resource{
 			"elasticsearch_destination":            dataSourceElasticsearchDeprecatedDestination(),
 			"elasticsearch_host":                   dataSourceElasticsearchHost(),
+			"elasticsearch_index":                  dataSourceElasticsearchIndex(),
 			"elasticsearch_opendistro_destination": dataSourceElasticsearchOpenDistroDestination(),
+			"elasticsearch_opendistro_findings":    dataSourceElasticsearchOpenDistroFindings(),
+			"elasticsearch_opendistro_monitor":     dataSourceElasticsearchOpenDistroMonitor(),
+			"elasticsearch_opendistro_role":        dataSourceElasticsearchOpenDistroRole(),
+			"elasticsearch_opendistro_user":        dataSourceElasticsearchOpenDistroUser(),
+			"elasticsearch_version":                dataSourceElasticsearchVersion(),
 		},
 
 		ConfigureFunc: providerConfigure,
 	}
+
+	// Terraform validates configurations, and warns about deprecations, before
+	// configuring the provider, so the warnings are suppressed by the
+	// environment rather than by a setting of the provider
+	if suppress, _ := strconv.ParseBool(os.Getenv("ELASTICSEARCH_SUPPRESS_DEPRECATION_WARNINGS")); suppress {
+		suppressDeprecationWarnings(provider)
+	}
+
+	return provider
+}
+
+// deprecatedDestinationType is the type of the deprecated destination
+// resource and data source.
+const deprecatedDestinationType = "elasticsearch_destination"
+
+// suppressDeprecationWarnings downgrades the deprecation of the
+// elasticsearch_destination resource and data source, which Terraform warns
+// about for every use of them, to a single logged line.
+func suppressDeprecationWarnings(provider *schema.Provider) {
+	suppressed := false
+	for _, r := range []*schema.Resource{provider.ResourcesMap[deprecatedDestinationType], provider.DataSourcesMap[deprecatedDestinationType]} {
+		if r != nil && r.DeprecationMessage != "" {
+			r.DeprecationMessage = ""
+			suppressed = true
+		}
+	}
+
+	if suppressed {
+		log.Printf("[WARN] %s is deprecated, please use elasticsearch_opendistro_destination instead; the deprecation warnings are suppressed by ELASTICSEARCH_SUPPRESS_DEPRECATION_WARNINGS", deprecatedDestinationType)
+	}
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	rawUrl := d.Get("url").(string)
-	parsedUrl, err := url.Parse(rawUrl)
+	rawUrls, parsedUrls, err := parseUrls(d.Get("url").(string))
 	if err != nil {
 		return nil, err
 	}
+	pathPrefix := d.Get("path_prefix").(string)
+	if pathPrefix != "" {
+		rawUrls = applyPathPrefix(parsedUrls, pathPrefix)
+	}
+	rawUrl, parsedUrl := rawUrls[0], parsedUrls[0]
+
+	sniffing := !isCloudEndpoint(parsedUrls) && pathPrefix == ""
+	v, sniffingConfigured := d.GetOkExists("sniff")
+	if sniffingConfigured {
+		sniffing = v.(bool)
+		if sniffing && pathPrefix != "" {
+			log.Printf("[WARN] Sniffing is enabled with path_prefix %q, the sniffed nodes will be requested without the prefix", pathPrefix)
+		}
+	}
+
+	var proxyUrl *url.URL
+	if rawProxyUrl := d.Get("proxy_url").(string); rawProxyUrl != "" {
+		proxyUrl, err = parseProxyUrl(rawProxyUrl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	headers := make(map[string]string)
+	for k, v := range d.Get("headers").(map[string]interface{}) {
+		headers[k] = v.(string)
+		log.Printf("[DEBUG] Using custom header %s: %s", k, redactHeaderValue(k, v.(string)))
+	}
 
-	return &ProviderConf{
-		rawUrl:          rawUrl,
-		insecure:        d.Get("insecure").(bool),
-		sniffing:        d.Get("sniff").(bool),
-		healthchecking:  d.Get("healthcheck").(bool),
-		cacertFile:      d.Get("cacert_file").(string),
-		username:        d.Get("username").(string),
-		password:        d.Get("password").(string),
-		token:           d.Get("token").(string),
-		tokenName:       d.Get("token_name").(string),
-		parsedUrl:       parsedUrl,
-		signAWSRequests: d.Get("sign_aws_requests").(bool),
-		esVersion:       d.Get("elasticsearch_version").(string),
-		awsRegion:       d.Get("aws_region").(string),
+	conf := &ProviderConf{
+		rawUrl:             rawUrl,
+		rawUrls:            rawUrls,
+		insecure:           d.Get("insecure").(bool),
+		sniffing:           sniffing,
+		sniffingConfigured: sniffingConfigured,
+		healthchecking:     d.Get("healthcheck").(bool),
+		cacertFile:         d.Get("cacert_file").(string),
+		username:           d.Get("username").(string),
+		password:           d.Get("password").(string),
+		token:              d.Get("token").(string),
+		tokenName:          d.Get("token_name").(string),
+		parsedUrl:          parsedUrl,
+		signAWSRequests:    d.Get("sign_aws_requests").(bool),
+		esVersion:          d.Get("elasticsearch_version").(string),
+		awsRegion:          d.Get("aws_region").(string),
 
 		awsAssumeRoleArn:   d.Get("aws_assume_role_arn").(string),
 		awsAccessKeyId:     d.Get("aws_access_key").(string),
@@ -245,14 +488,188 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		awsProfile:         d.Get("aws_profile").(string),
 		certPemPath:        d.Get("client_cert_path").(string),
 		keyPemPath:         d.Get("client_key_path").(string),
-	}, nil
+		headers:            headers,
+		proxyUrl:           proxyUrl,
+		debugLogging:       d.Get("debug_logging").(bool),
+		compression:        d.Get("enable_compression").(bool),
+
+		destinationsPageSize: d.Get("destinations_page_size").(int),
+		bearerTokenFile:      d.Get("bearer_token_file").(string),
+		maxIdleConns:         d.Get("max_idle_conns").(int),
+	}
+
+	// the flavor is detected with the version, unless either is configured
+	switch d.Get("flavor").(string) {
+	case "opensearch":
+		conf.flavor = OpenSearch
+	case "elasticsearch":
+		conf.flavor = Elasticsearch
+	default:
+		if conf.esVersion != "" {
+			conf.flavor = Elasticsearch
+		}
+	}
+
+	conf.requestTimeout, err = time.ParseDuration(d.Get("request_timeout").(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request_timeout: %+v", err)
+	}
+	conf.idleConnTimeout, err = time.ParseDuration(d.Get("idle_conn_timeout").(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid idle_conn_timeout: %+v", err)
+	}
+
+	// Load the client certificate once so invalid material fails the plan
+	conf.clientCertificate, err = loadClientCertificate(conf.certPemPath, conf.keyPemPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the token endpoint is requested with the same transport as the cluster
+	if err := configureOAuth(conf, d); err != nil {
+		return nil, err
+	}
+	if err := configureApiKey(conf, d.Get("api_key_id").(string), d.Get("api_key_value").(string)); err != nil {
+		return nil, err
+	}
+
+	if d.Get("batch_security_requests").(bool) {
+		conf.securityBatcher = newPatchBatcher(securityBatchWindow, securityPatchFlush(conf))
+	}
+
+	if conf.signAWSRequests && awsSigningConfigured(conf) {
+		region, err := resolveAwsRegion(conf)
+		if err != nil {
+			return nil, err
+		}
+		conf.awsRegion = region
+	}
+
+	// Surface connectivity and authentication problems during the plan rather
+	// than at the first resource operation.
+	if conf.healthchecking && rawUrl != "" {
+		if err := pingCluster(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	return conf, nil
+}
+
+// pingCluster requests the root endpoint of the cluster and checks that it is
+// reachable, that the credentials are accepted and that its version is
+// supported. The detected version is stored on the configuration.
+func pingCluster(conf *ProviderConf) error {
+	httpClient := esHttpClient(conf)
+
+	// Fail over to the next node if a node can't be reached
+	var res *http.Response
+	var host string
+	for i, rawUrl := range conf.rawUrls {
+		req, err := http.NewRequest("GET", rawUrl, nil)
+		if err != nil {
+			return err
+		}
+		if conf.username != "" && conf.password != "" {
+			req.SetBasicAuth(conf.username, conf.password)
+		}
+
+		host = req.URL.Host
+		log.Printf("[INFO] Pinging %s to check connectivity", host)
+		res, err = httpClient.Do(req)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			err = fmt.Errorf("connection refused by %s, please check the url of the provider: %+v", host, err)
+		} else {
+			err = fmt.Errorf("unable to connect to %s: %+v", host, err)
+		}
+		if i == len(conf.rawUrls)-1 {
+			return err
+		}
+		log.Printf("[WARN] %+v", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("401 unauthorized from %s, please check the credentials of the provider", host)
+	case res.StatusCode >= 300:
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, host)
+	}
+
+	info := new(rootInfo)
+	if err := json.NewDecoder(res.Body).Decode(info); err != nil {
+		return fmt.Errorf("error unmarshalling root endpoint body: %+v", err)
+	}
+	conf.rootInfo = info
+
+	if conf.esVersion == "" {
+		conf.esVersion = info.Version.Number
+	}
+	if conf.flavor == Unknown {
+		conf.flavor = Elasticsearch
+		if info.Version.Distribution == "opensearch" {
+			conf.flavor = OpenSearch
+		}
+	}
+
+	if conf.flavor != OpenSearch && conf.esVersion < "5.0.0" {
+		return fmt.Errorf("unsupported version %q of Elasticsearch, at least 5.0.0 is required", conf.esVersion)
+	}
+
+	return nil
+}
+
+// clientSniffing returns whether the client sniffs the nodes of the cluster.
+// Clients with a custom transport don't sniff by default, as their nodes are
+// often behind a proxy or a load balancer, but an explicit sniff option is
+// kept.
+func clientSniffing(conf *ProviderConf) bool {
+	if customTransport(conf) && !conf.sniffingConfigured {
+		if conf.sniffing {
+			log.Printf("[INFO] Not sniffing the nodes of the cluster with a custom http client, set sniff to true to sniff them")
+		}
+		return false
+	}
+	return conf.sniffing
+}
+
+// customTransport returns whether requests are signed, authenticated with a
+// token, sent with custom TLS options or through a proxy. Headers, debug
+// logging and timeouts don't change how the nodes are reached.
+func customTransport(conf *ProviderConf) bool {
+	return (conf.signAWSRequests && conf.awsRegion != "") ||
+		conf.oauthTokens != nil ||
+		conf.bearerTokenFile != "" ||
+		conf.token != "" ||
+		conf.insecure || conf.cacertFile != "" || conf.clientCertificate != nil ||
+		conf.proxyUrl != nil
 }
+
+// getClient returns the client of the configuration, shared by all resources.
+// It is created on the first call, which detects the version of the cluster
+// unless it is configured.
 func getClient(conf *ProviderConf) (interface{}, error) {
+	conf.clientOnce.Do(func() {
+		conf.client, conf.clientErr = newClient(conf)
+	})
+
+	return conf.client, conf.clientErr
+}
+
+func newClient(conf *ProviderConf) (interface{}, error) {
+	httpClient := esHttpClient(conf)
+	sniffing := clientSniffing(conf)
+
 	opts := []elastic7.ClientOptionFunc{
-		elastic7.SetURL(conf.rawUrl),
+		elastic7.SetURL(conf.rawUrls...),
 		elastic7.SetScheme(conf.parsedUrl.Scheme),
-		elastic7.SetSniff(conf.sniffing),
+		elastic7.SetHttpClient(httpClient),
+		elastic7.SetSniff(sniffing),
 		elastic7.SetHealthcheck(conf.healthchecking),
+		elastic7.SetGzip(conf.compression),
 	}
 
 	if conf.parsedUrl.User.Username() != "" {
@@ -263,18 +680,6 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 		opts = append(opts, elastic7.SetBasicAuth(conf.username, conf.password))
 	}
 
-	if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-		log.Printf("[INFO] Using AWS: %+v", m[1])
-		opts = append(opts, elastic7.SetHttpClient(awsHttpClient(m[1], conf)), elastic7.SetSniff(false))
-	} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-		log.Printf("[INFO] Using AWS: %+v", awsRegion)
-		opts = append(opts, elastic7.SetHttpClient(awsHttpClient(awsRegion, conf)), elastic7.SetSniff(false))
-	} else if conf.insecure || conf.cacertFile != "" {
-		opts = append(opts, elastic7.SetHttpClient(tlsHttpClient(conf)), elastic7.SetSniff(false))
-	} else if conf.token != "" {
-		opts = append(opts, elastic7.SetHttpClient(tokenHttpClient(conf.token, conf.tokenName, conf.insecure)), elastic7.SetSniff(false))
-	}
-
 	var relevantClient interface{}
 	client, err := elastic7.NewClient(opts...)
 	if err != nil {
@@ -285,20 +690,32 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 	// Use the v7 client to ping the cluster to determine the version if one was not provided
 	if conf.esVersion == "" {
 		log.Printf("[INFO] Pinging url to determine version %+v", conf.rawUrl)
-		info, _, err := client.Ping(conf.rawUrl).Do(context.TODO())
+		info, err := elastic7GetRootInfo(client)
 		if err != nil {
 			return nil, err
 		}
+		conf.rootInfo = info
 		conf.esVersion = info.Version.Number
+		if conf.flavor == Unknown {
+			conf.flavor = Elasticsearch
+			if info.Version.Distribution == "opensearch" {
+				conf.flavor = OpenSearch
+			}
+		}
 	}
 
-	if conf.esVersion < "7.0.0" && conf.esVersion >= "6.0.0" {
+	if conf.flavor == OpenSearch {
+		// OpenSearch is API compatible with Elasticsearch 7.10
+		log.Printf("[INFO] Using OpenSearch %s", conf.esVersion)
+	} else if conf.esVersion < "7.0.0" && conf.esVersion >= "6.0.0" {
 		log.Printf("[INFO] Using ES 6")
 		opts := []elastic6.ClientOptionFunc{
-			elastic6.SetURL(conf.rawUrl),
+			elastic6.SetURL(conf.rawUrls...),
 			elastic6.SetScheme(conf.parsedUrl.Scheme),
-			elastic6.SetSniff(conf.sniffing),
+			elastic6.SetHttpClient(httpClient),
+			elastic6.SetSniff(sniffing),
 			elastic6.SetHealthcheck(conf.healthchecking),
+			elastic6.SetGzip(conf.compression),
 		}
 
 		if conf.parsedUrl.User.Username() != "" {
@@ -309,18 +726,6 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 			opts = append(opts, elastic6.SetBasicAuth(conf.username, conf.password))
 		}
 
-		if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", m[1])
-			opts = append(opts, elastic6.SetHttpClient(awsHttpClient(m[1], conf)), elastic6.SetSniff(false))
-		} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", conf.awsRegion)
-			opts = append(opts, elastic6.SetHttpClient(awsHttpClient(awsRegion, conf)), elastic6.SetSniff(false))
-		} else if conf.insecure || conf.cacertFile != "" {
-			opts = append(opts, elastic6.SetHttpClient(tlsHttpClient(conf)), elastic6.SetSniff(false))
-		} else if conf.token != "" {
-			opts = append(opts, elastic6.SetHttpClient(tokenHttpClient(conf.token, conf.tokenName, conf.insecure)), elastic6.SetSniff(false))
-		}
-
 		relevantClient, err = elastic6.NewClient(opts...)
 		if err != nil {
 			return nil, err
@@ -328,10 +733,12 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 	} else if conf.esVersion < "6.0.0" && conf.esVersion >= "5.0.0" {
 		log.Printf("[INFO] Using ES 5")
 		opts := []elastic5.ClientOptionFunc{
-			elastic5.SetURL(conf.rawUrl),
+			elastic5.SetURL(conf.rawUrls...),
 			elastic5.SetScheme(conf.parsedUrl.Scheme),
-			elastic5.SetSniff(conf.sniffing),
+			elastic5.SetHttpClient(httpClient),
+			elastic5.SetSniff(sniffing),
 			elastic5.SetHealthcheck(conf.healthchecking),
+			elastic5.SetGzip(conf.compression),
 		}
 
 		if conf.parsedUrl.User.Username() != "" {
@@ -342,28 +749,320 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 			opts = append(opts, elastic5.SetBasicAuth(conf.username, conf.password))
 		}
 
-		if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil && conf.signAWSRequests {
-			opts = append(opts, elastic5.SetHttpClient(awsHttpClient(m[1], conf)), elastic5.SetSniff(false))
-		} else if awsRegion := conf.awsRegion; conf.awsRegion != "" && conf.signAWSRequests {
-			log.Printf("[INFO] Using AWS: %+v", conf.awsRegion)
-			opts = append(opts, elastic5.SetHttpClient(awsHttpClient(awsRegion, conf)), elastic5.SetSniff(false))
-		} else if conf.insecure || conf.cacertFile != "" {
-			opts = append(opts, elastic5.SetHttpClient(tlsHttpClient(conf)), elastic5.SetSniff(false))
-		} else if conf.token != "" {
-			opts = append(opts, elastic5.SetHttpClient(tokenHttpClient(conf.token, conf.tokenName, conf.insecure)), elastic5.SetSniff(false))
-		}
-
 		relevantClient, err = elastic5.NewClient(opts...)
 		if err != nil {
 			return nil, err
 		}
 	} else if conf.esVersion < "5.0.0" {
-		return nil, errors.New("ElasticSearch is older than 5.0.0!")
+		return nil, errors.New("ElasticSearch is older than 5.0.0! Set flavor to opensearch for OpenSearch clusters")
 	}
 
 	return relevantClient, nil
 }
 
+// configureApiKey sets the token of the configuration to the encoded API key,
+// if any, and checks the bearer token file can be read. Tokens take precedence
+// over basic auth, so the credentials for basic auth are dropped with a warning.
+func configureApiKey(conf *ProviderConf, id, value string) error {
+	if id != "" || value != "" {
+		if id == "" || value == "" {
+			return errors.New("both api_key_id and api_key_value are required for API key authentication")
+		}
+		conf.token = base64.StdEncoding.EncodeToString([]byte(id + ":" + value))
+		conf.tokenName = "ApiKey"
+	}
+
+	if conf.oauthTokens != nil && (conf.token != "" || conf.bearerTokenFile != "") {
+		return errors.New("oauth_token_url can't be used together with token, bearer_token_file or an API key")
+	}
+
+	if conf.bearerTokenFile != "" {
+		if conf.token != "" {
+			return errors.New("bearer_token_file can't be used together with token or an API key")
+		}
+		// fail early on a missing file, rather than on the first request
+		if _, err := readBearerTokenFile(conf.bearerTokenFile); err != nil {
+			return err
+		}
+	}
+
+	if (conf.token != "" || conf.bearerTokenFile != "" || conf.oauthTokens != nil) && (conf.username != "" || conf.parsedUrl.User.Username() != "") {
+		log.Printf("[WARN] Both a token or API key and basic auth credentials are configured, ignoring the basic auth credentials")
+		conf.username, conf.password = "", ""
+		conf.parsedUrl.User = nil
+	}
+
+	return nil
+}
+
+// configureOAuth sets up fetching bearer tokens with the OAuth2 client
+// credentials flow, if a token URL is configured.
+func configureOAuth(conf *ProviderConf, d *schema.ResourceData) error {
+	tokenURL := d.Get("oauth_token_url").(string)
+	clientID := d.Get("oauth_client_id").(string)
+	clientSecret := d.Get("oauth_client_secret").(string)
+	if tokenURL == "" && clientID == "" {
+		return nil
+	}
+	if tokenURL == "" || clientID == "" || clientSecret == "" {
+		return errors.New("oauth_token_url, oauth_client_id and oauth_client_secret are all required for OAuth2 authentication")
+	}
+
+	var scopes []string
+	for _, scope := range d.Get("oauth_scopes").([]interface{}) {
+		scopes = append(scopes, scope.(string))
+	}
+
+	conf.oauthTokens = &oauthTokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		client:       &http.Client{Transport: httpTransport(conf), Timeout: conf.requestTimeout},
+	}
+
+	return nil
+}
+
+// rootInfo is the subset of the response of the root endpoint used to
+// identify the cluster.
+type rootInfo struct {
+	Version struct {
+		Number       string `json:"number"`
+		Distribution string `json:"distribution"`
+		BuildFlavor  string `json:"build_flavor"`
+	} `json:"version"`
+}
+
+// getRootInfo returns the response of the root endpoint of the cluster. It is
+// only requested if it wasn't already while detecting the version.
+func getRootInfo(conf *ProviderConf) (*rootInfo, error) {
+	esClient, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.rootInfoOnce.Do(func() {
+		if conf.rootInfo != nil {
+			return
+		}
+
+		var body json.RawMessage
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			conf.rootInfo, conf.rootInfoErr = elastic7GetRootInfo(client)
+			return
+		case *elastic6.Client:
+			var res *elastic6.Response
+			res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+				Method: "GET",
+				Path:   "/",
+			})
+			if err == nil {
+				body = res.Body
+			}
+		default:
+			var res *elastic5.Response
+			res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", "/", nil, nil)
+			if err == nil {
+				body = res.Body
+			}
+		}
+		if err != nil {
+			conf.rootInfoErr = err
+			return
+		}
+
+		info := new(rootInfo)
+		if err := json.Unmarshal(body, info); err != nil {
+			conf.rootInfoErr = fmt.Errorf("error unmarshalling root endpoint body: %+v: %+v", err, string(body))
+			return
+		}
+		conf.rootInfo = info
+	})
+
+	return conf.rootInfo, conf.rootInfoErr
+}
+
+func elastic7GetRootInfo(client *elastic7.Client) (*rootInfo, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(rootInfo)
+	if err := json.Unmarshal(res.Body, info); err != nil {
+		return nil, fmt.Errorf("error unmarshalling root endpoint body: %+v: %+v", err, string(res.Body))
+	}
+
+	return info, nil
+}
+
+// esHttpClient returns the HTTP client shared by the elastic clients, with
+// the tuned transport of the configuration.
+func esHttpClient(conf *ProviderConf) *http.Client {
+	client := &http.Client{Transport: httpTransport(conf)}
+	if conf.signAWSRequests && conf.awsRegion != "" {
+		log.Printf("[INFO] Using AWS: %+v", conf.awsRegion)
+		client = awsHttpClient(conf.awsRegion, conf)
+	} else if conf.oauthTokens != nil {
+		client = &http.Client{Transport: WithOAuthToken(httpTransport(conf), conf.oauthTokens)}
+	} else if conf.bearerTokenFile != "" {
+		client = &http.Client{Transport: WithBearerTokenFile(httpTransport(conf), conf.bearerTokenFile)}
+	} else if conf.token != "" {
+		client = tokenHttpClient(conf)
+	}
+
+	if conf.debugLogging {
+		client = &http.Client{Transport: WithDebugLogging(client.Transport)}
+	}
+
+	// the headers are set before the request is logged
+	if len(conf.headers) > 0 {
+		rt := WithHeader(client.Transport)
+		for k, v := range conf.headers {
+			rt.Set(k, v)
+		}
+		client = &http.Client{Transport: rt}
+	}
+
+	// a backstop for requests which aren't bound by a context with a deadline
+	if conf.requestTimeout > 0 {
+		client.Timeout = conf.requestTimeout
+	}
+
+	return client
+}
+
+func validateDuration(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	if _, err := time.ParseDuration(v); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration, e.g. 90s or 5m: %s", k, err))
+	}
+	return
+}
+
+func validateNonNegativeDuration(i interface{}, k string) (warnings []string, errors []error) {
+	warnings, errors = validateDuration(i, k)
+	if len(errors) > 0 {
+		return
+	}
+	if d, _ := time.ParseDuration(i.(string)); d < 0 {
+		errors = append(errors, fmt.Errorf("%q must not be negative, got %s", k, i))
+	}
+	return
+}
+
+// awsMetadataRegion looks up the region of the EC2 instance the provider runs
+// on, replaced in tests.
+var awsMetadataRegion = func() (string, error) {
+	sess, err := awssession.NewSession()
+	if err != nil {
+		return "", err
+	}
+
+	return ec2metadata.New(sess).Region()
+}
+
+// parseUrls splits the comma separated url option into the URLs of the nodes
+// of the cluster, which must share a scheme.
+func parseUrls(rawUrl string) ([]string, []*url.URL, error) {
+	var rawUrls []string
+	var parsedUrls []*url.URL
+	for _, u := range strings.Split(rawUrl, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		parsedUrl, err := url.Parse(u)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(parsedUrls) > 0 && parsedUrl.Scheme != parsedUrls[0].Scheme {
+			return nil, nil, fmt.Errorf("all urls must use the same scheme, got %s and %s", parsedUrls[0].Scheme, parsedUrl.Scheme)
+		}
+		rawUrls = append(rawUrls, u)
+		parsedUrls = append(parsedUrls, parsedUrl)
+	}
+
+	// keep the previous behavior for an empty url, e.g. when validating
+	if len(rawUrls) == 0 {
+		parsedUrl, _ := url.Parse(rawUrl)
+		return []string{rawUrl}, []*url.URL{parsedUrl}, nil
+	}
+
+	return rawUrls, parsedUrls, nil
+}
+
+// applyPathPrefix prepends the path prefix to the paths of the urls, so that
+// every request path, e.g. /_opendistro/_alerting/destinations, is sent
+// below the prefix. The urls are updated in place and returned as strings.
+func applyPathPrefix(urls []*url.URL, prefix string) []string {
+	prefix = strings.Trim(prefix, "/")
+	rawUrls := make([]string, len(urls))
+	for i, u := range urls {
+		if prefix != "" {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + prefix
+		}
+		rawUrls[i] = u.String()
+	}
+	return rawUrls
+}
+
+// isCloudEndpoint returns whether any of the urls refers to a cloud endpoint,
+// whose nodes are behind a load balancer and can't be sniffed.
+func isCloudEndpoint(urls []*url.URL) bool {
+	for _, u := range urls {
+		host := u.Hostname()
+		if awsUrlRegexp.MatchString(host) ||
+			strings.HasSuffix(host, ".cloud.es.io") ||
+			strings.HasSuffix(host, ".found.io") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// awsSigningConfigured returns whether the configuration refers to an AWS
+// domain, either through its url or through any of the aws options.
+func awsSigningConfigured(conf *ProviderConf) bool {
+	return awsUrlRegexp.MatchString(conf.parsedUrl.Hostname()) ||
+		conf.awsRegion != "" ||
+		conf.awsAccessKeyId != "" ||
+		conf.awsAssumeRoleArn != "" ||
+		conf.awsProfile != ""
+}
+
+// resolveAwsRegion returns the region to sign requests for, in order of
+// precedence: the aws_region option, the region of an AWS domain url, the
+// AWS_REGION environment variable and the EC2 instance metadata.
+func resolveAwsRegion(conf *ProviderConf) (string, error) {
+	if conf.awsRegion != "" {
+		return conf.awsRegion, nil
+	}
+	if m := awsUrlRegexp.FindStringSubmatch(conf.parsedUrl.Hostname()); m != nil {
+		return m[1], nil
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region, nil
+	}
+	if region, err := awsMetadataRegion(); err == nil && region != "" {
+		return region, nil
+	} else if err != nil {
+		log.Printf("[DEBUG] Unable to get the region from the EC2 instance metadata: %+v", err)
+	}
+
+	return "", errors.New("sign_aws_requests is enabled but no AWS region could be determined, please set aws_region or AWS_REGION, or set sign_aws_requests to false")
+}
+
 func assumeRoleCredentials(region, roleARN, profile string) *awscredentials.Credentials {
 	sess := awssession.Must(awssession.NewSessionWithOptions(awssession.Options{
 		Profile: profile,
@@ -413,7 +1112,7 @@ func awsSession(region string, conf *ProviderConf) *awssession.Session {
 
 func awsHttpClient(region string, conf *ProviderConf) *http.Client {
 	signer := awssigv4.NewSigner(awsSession(region, conf).Config.Credentials)
-	client, err := aws_signing_client.New(signer, nil, "es", region)
+	client, err := aws_signing_client.New(signer, &http.Client{Transport: httpTransport(conf)}, "es", region)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -421,37 +1120,45 @@ func awsHttpClient(region string, conf *ProviderConf) *http.Client {
 	return client
 }
 
-func tokenHttpClient(token string, tokenName string, insecure bool) *http.Client {
-	client := http.DefaultClient
+func tokenHttpClient(conf *ProviderConf) *http.Client {
+	rt := WithHeader(httpTransport(conf))
+	rt.Set("Authorization", fmt.Sprintf("%s %s", conf.tokenName, conf.token))
 
-	rt := WithHeader(client.Transport)
-	rt.Set("Authorization", fmt.Sprintf("%s %s", tokenName, token))
-	client.Transport = rt
+	return &http.Client{Transport: rt}
+}
 
-	if insecure {
-		client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true
+// loadClientCertificate loads the certificate presented to the cluster for
+// mutual TLS, each of the certificate and the key being either a path to a
+// PEM file or the PEM encoded contents. It returns nil if neither is set.
+func loadClientCertificate(certPemPath string, keyPemPath string) (*tls.Certificate, error) {
+	if certPemPath == "" && keyPemPath == "" {
+		return nil, nil
+	}
+	if certPemPath == "" || keyPemPath == "" {
+		return nil, errors.New("both client_cert_path and client_key_path are required for client certificate authentication")
 	}
 
-	return client
+	certPem, _, err := pathorcontents.Read(certPemPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading client_cert_path: %+v", err)
+	}
+	keyPem, _, err := pathorcontents.Read(keyPemPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading client_key_path: %+v", err)
+	}
+	cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+	if err != nil {
+		return nil, fmt.Errorf("error loading the client certificate: %+v", err)
+	}
+
+	return &cert, nil
 }
 
-func tlsHttpClient(conf *ProviderConf) *http.Client {
-	// Configure TLS/SSL
+// tlsConfig returns the TLS configuration of the connections to the cluster.
+func tlsConfig(conf *ProviderConf) *tls.Config {
 	tlsConfig := &tls.Config{}
-	if conf.certPemPath != "" && conf.keyPemPath != "" {
-		certPem, _, err := pathorcontents.Read(conf.certPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		keyPem, _, err := pathorcontents.Read(conf.keyPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
-		if err != nil {
-			log.Fatal(err)
-		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
+	if conf.clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*conf.clientCertificate}
 	}
 
 	// If a cacertFile has been specified, use that for cert validation
@@ -468,9 +1175,51 @@ func tlsHttpClient(conf *ProviderConf) *http.Client {
 		tlsConfig.InsecureSkipVerify = true
 	}
 
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	return tlsConfig
+}
 
-	client := &http.Client{Transport: transport}
+// The defaults of max_idle_conns and idle_conn_timeout. Unlike the default
+// transport of Go, which keeps only two idle connections per host, the idle
+// connections aren't limited per node of the cluster.
+const (
+	defaultMaxIdleConns    = 100
+	defaultIdleConnTimeout = "90s"
+)
 
-	return client
+// httpTransport returns a copy of the default transport of Go, keeping its
+// dial and TLS handshake timeouts and HTTP/2 support, routing requests
+// through the configured proxy, or through the proxy from the environment if
+// there is none, and applying the connection and TLS options of the
+// configuration.
+func httpTransport(conf *ProviderConf) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if conf.proxyUrl != nil {
+		transport.Proxy = http.ProxyURL(conf.proxyUrl)
+	}
+
+	transport.MaxIdleConns = conf.maxIdleConns
+	transport.MaxIdleConnsPerHost = conf.maxIdleConns
+	transport.IdleConnTimeout = conf.idleConnTimeout
+	// no connections are kept idle without a limit of at least one
+	transport.DisableKeepAlives = conf.maxIdleConns == 0
+	if conf.insecure || conf.cacertFile != "" || conf.clientCertificate != nil {
+		transport.TLSClientConfig = tlsConfig(conf)
+	}
+
+	return transport
+}
+
+func parseProxyUrl(rawProxyUrl string) (*url.URL, error) {
+	proxyUrl, err := url.Parse(rawProxyUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy_url: %+v", err)
+	}
+
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5":
+		return proxyUrl, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy_url scheme %q, must be one of http, https or socks5", proxyUrl.Scheme)
+	}
 }
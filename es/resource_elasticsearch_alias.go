@@ -0,0 +1,296 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic5 "gopkg.in/olivere/elastic.v5"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchAlias() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch alias of an index, managed separately from the index. Changes are applied atomically with the aliases API.",
+		Create:      resourceElasticsearchAliasCreate,
+		Read:        resourceElasticsearchAliasRead,
+		Update:      resourceElasticsearchAliasUpdate,
+		Delete:      resourceElasticsearchAliasDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the alias.",
+			},
+			"index": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the index the alias points to. Changing it moves the alias to the new index in a single request, removing it from the old index.",
+			},
+			"filter": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: diffSuppressAliasFilter,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+				Description: "The JSON query limiting the documents the alias can access.",
+			},
+			"routing": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The value used to route both indexing and search operations of the alias to a shard.",
+			},
+			"is_write_index": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Make the index the write index of the alias, taking the flag over from the current write index of the alias.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchAliasCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchAliasPut(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(formatAliasID(d.Get("index").(string), d.Get("name").(string)))
+	return resourceElasticsearchAliasRead(d, meta)
+}
+
+func resourceElasticsearchAliasRead(d *schema.ResourceData, meta interface{}) error {
+	index, name, err := parseAliasID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/{index}/_alias/{name}", map[string]string{
+		"index": index,
+		"name":  name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for alias: %+v", err)
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, nil, nil)
+		if err == nil {
+			body = res.Body
+		}
+	}
+
+	if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) || elastic5.IsNotFound(err) {
+		log.Printf("[WARN] Alias (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var indices map[string]struct {
+		Aliases map[string]aliasResponse `json:"aliases"`
+	}
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return fmt.Errorf("error unmarshalling alias body: %+v: %+v", err, string(body))
+	}
+
+	alias, ok := indices[index].Aliases[name]
+	if !ok {
+		log.Printf("[WARN] Alias (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	filter := ""
+	if alias.Filter != nil {
+		f, err := json.Marshal(alias.Filter)
+		if err != nil {
+			return err
+		}
+		filter = string(f)
+	}
+
+	// routing sets both routings, others are managed elsewhere
+	routing := ""
+	if alias.IndexRouting == alias.SearchRouting {
+		routing = alias.IndexRouting
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", name)
+	ds.set("index", index)
+	ds.set("filter", filter)
+	ds.set("routing", routing)
+	ds.set("is_write_index", alias.IsWriteIndex)
+	return ds.err
+}
+
+func resourceElasticsearchAliasUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchAliasPut(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(formatAliasID(d.Get("index").(string), d.Get("name").(string)))
+	return resourceElasticsearchAliasRead(d, meta)
+}
+
+func resourceElasticsearchAliasDelete(d *schema.ResourceData, meta interface{}) error {
+	index, name, err := parseAliasID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = resourceElasticsearchPostAliasActions(meta, []map[string]interface{}{
+		{"remove": map[string]interface{}{"index": index, "alias": name}},
+	})
+	if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) || elastic5.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// resourceElasticsearchAliasPut adds the alias to its index, removing it from
+// the previous index in the same request when the index changed.
+func resourceElasticsearchAliasPut(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	o, n := d.GetChange("index")
+	oldIndex, newIndex := o.(string), n.(string)
+
+	properties := map[string]interface{}{}
+	if filter, ok := d.GetOk("filter"); ok {
+		var query interface{}
+		if err := json.Unmarshal([]byte(filter.(string)), &query); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		properties["filter"] = query
+	}
+	if routing, ok := d.GetOk("routing"); ok {
+		properties["routing"] = routing
+	}
+	// the flag is only sent when set, or to unset it, an alias of a single
+	// index without it writes to that index
+	if o, n := d.GetChange("is_write_index"); o.(bool) || n.(bool) {
+		properties["is_write_index"] = n
+	}
+
+	writeIndices, err := indexAliasWriteIndices(meta, []string{name})
+	if err != nil {
+		return err
+	}
+
+	var actions []map[string]interface{}
+	if oldIndex != "" && oldIndex != newIndex {
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{"index": oldIndex, "alias": name},
+		})
+
+		// the old index doesn't need to give up the flag, it loses the alias
+		var others []string
+		for _, writeIndex := range writeIndices[name] {
+			if writeIndex != oldIndex {
+				others = append(others, writeIndex)
+			}
+		}
+		writeIndices[name] = others
+	}
+	actions = append(actions, indexAliasActions(newIndex, nil, map[string]interface{}{name: properties}, writeIndices)...)
+
+	if err := resourceElasticsearchPostAliasActions(meta, actions); err != nil {
+		return fmt.Errorf("error updating alias %s of index %s: %+v", name, newIndex, err)
+	}
+
+	return nil
+}
+
+// resourceElasticsearchPostAliasActions applies the actions atomically with
+// the aliases API.
+func resourceElasticsearchPostAliasActions(meta interface{}, actions []map[string]interface{}) error {
+	body := map[string]interface{}{"actions": actions}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	default:
+		_, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "POST", "/_aliases", nil, body)
+	}
+
+	return err
+}
+
+// formatAliasID returns the ID of the resource of the alias of an index.
+func formatAliasID(index, name string) string {
+	return index + "/" + name
+}
+
+// parseAliasID returns the index and name of the alias of the resource.
+func parseAliasID(id string) (string, string, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid alias ID %q, expected <index>/<name>", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+type aliasResponse struct {
+	Filter        interface{} `json:"filter"`
+	IndexRouting  string      `json:"index_routing"`
+	SearchRouting string      `json:"search_routing"`
+	IsWriteIndex  bool        `json:"is_write_index"`
+}
@@ -0,0 +1,138 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestElasticsearchAliasCreateFilteredWriteAlias(t *testing.T) {
+	var actions []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_alias/logs":
+			fmt.Fprint(w, `{"logs-000001": {"aliases": {"logs": {"is_write_index": true}}}}`)
+		case r.Method == "POST" && r.URL.Path == "/_aliases":
+			var body struct {
+				Actions []map[string]interface{} `json:"actions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			actions = body.Actions
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "GET" && r.URL.Path == "/logs-000002/_alias/logs":
+			fmt.Fprint(w, `{"logs-000002": {"aliases": {"logs": {"filter": {"term": {"service": "api"}}, "index_routing": "1", "search_routing": "1", "is_write_index": true}}}}`)
+		case r.Method == "GET" && r.URL.Path == "/logs-000003/_alias/logs":
+			fmt.Fprint(w, `{"logs-000003": {"aliases": {"logs": {"filter": {"term": {"service": "api"}}, "index_routing": "1", "search_routing": "1"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchAlias().Schema, map[string]interface{}{
+		"name":           "logs",
+		"index":          "logs-000002",
+		"filter":         `{"term": {"service": "api"}}`,
+		"routing":        "1",
+		"is_write_index": true,
+	})
+	if err := resourceElasticsearchAliasCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the current write index gives up the flag in the same request
+	expected := []map[string]interface{}{
+		{"add": map[string]interface{}{"index": "logs-000001", "alias": "logs", "is_write_index": false}},
+		{"add": map[string]interface{}{
+			"index":          "logs-000002",
+			"alias":          "logs",
+			"filter":         map[string]interface{}{"term": map[string]interface{}{"service": "api"}},
+			"routing":        "1",
+			"is_write_index": true,
+		}},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected the actions %v, got %v", expected, actions)
+	}
+
+	if resourceData.Id() != "logs-000002/logs" {
+		t.Errorf("expected the ID logs-000002/logs, got %s", resourceData.Id())
+	}
+	if filter := resourceData.Get("filter").(string); filter != `{"term":{"service":"api"}}` {
+		t.Errorf("expected the filter to be read, got %s", filter)
+	}
+	if resourceData.Get("routing") != "1" || resourceData.Get("is_write_index") != true {
+		t.Errorf("expected the routing and write index to be read, got %v and %v", resourceData.Get("routing"), resourceData.Get("is_write_index"))
+	}
+
+	// moving the alias removes it from the old index in the same request
+	state := &terraform.InstanceState{
+		ID: "logs-000002/logs",
+		Attributes: map[string]string{
+			"id":             "logs-000002/logs",
+			"name":           "logs",
+			"index":          "logs-000002",
+			"filter":         `{"term":{"service":"api"}}`,
+			"routing":        "1",
+			"is_write_index": "false",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":    "logs",
+		"index":   "logs-000003",
+		"filter":  `{"term": {"service": "api"}}`,
+		"routing": "1",
+	})
+	diff, err := resourceElasticsearchAlias().Diff(state, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Error("expected the alias to be moved rather than replaced")
+	}
+	state, err = resourceElasticsearchAlias().Apply(state, diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state.ID != "logs-000003/logs" {
+		t.Errorf("expected the ID logs-000003/logs, got %s", state.ID)
+	}
+
+	expected = []map[string]interface{}{
+		{"remove": map[string]interface{}{"index": "logs-000002", "alias": "logs"}},
+		{"add": map[string]interface{}{
+			"index":   "logs-000003",
+			"alias":   "logs",
+			"filter":  map[string]interface{}{"term": map[string]interface{}{"service": "api"}},
+			"routing": "1",
+		}},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected the actions %v, got %v", expected, actions)
+	}
+}
+
+func TestParseAliasID(t *testing.T) {
+	index, name, err := parseAliasID("logs-000001/logs")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if index != "logs-000001" || name != "logs" {
+		t.Errorf("expected logs-000001 and logs, got %s and %s", index, name)
+	}
+
+	if _, _, err := parseAliasID("logs"); err == nil {
+		t.Error("expected an error for an ID without an index")
+	}
+}
@@ -0,0 +1,258 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic5 "gopkg.in/olivere/elastic.v5"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// clusterSettingsID is the ID of the cluster settings, which exist once per
+// cluster.
+const clusterSettingsID = "settings"
+
+func resourceElasticsearchClusterSettings() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Manages dynamic settings of an Elasticsearch cluster. Only the settings set in the configuration are managed, others are left as they are. Removed settings are reset to their defaults.",
+		Create:        resourceElasticsearchClusterSettingsCreate,
+		Read:          resourceElasticsearchClusterSettingsRead,
+		Update:        resourceElasticsearchClusterSettingsUpdate,
+		Delete:        resourceElasticsearchClusterSettingsDelete,
+		CustomizeDiff: resourceElasticsearchClusterSettingsCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"persistent": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The persistent settings, which survive a full cluster restart, keyed by the flat name of the setting, e.g. `cluster.routing.allocation.enable`. Settings with several values are comma separated.",
+			},
+			"transient": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The transient settings, which are lost on a full cluster restart, keyed by the flat name of the setting. They take precedence over persistent settings of the same name.",
+			},
+			"overlapping_settings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The settings set both persistently and transiently, whose transient value takes precedence until the next full cluster restart. Changes are shown in the plan as a warning.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: resourceElasticsearchClusterSettingsImport,
+		},
+	}
+}
+
+func resourceElasticsearchClusterSettingsCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchPutClusterSettings(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(clusterSettingsID)
+	return resourceElasticsearchClusterSettingsRead(d, meta)
+}
+
+func resourceElasticsearchClusterSettingsRead(d *schema.ResourceData, meta interface{}) error {
+	return resourceElasticsearchClusterSettingsReadSettings(d, meta, false)
+}
+
+// resourceElasticsearchClusterSettingsImport imports all the persistent and
+// transient settings of the cluster.
+func resourceElasticsearchClusterSettingsImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourceElasticsearchClusterSettingsReadSettings(d, meta, true); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceElasticsearchClusterSettingsReadSettings reads the settings of the
+// cluster, only the managed ones unless all is set.
+func resourceElasticsearchClusterSettingsReadSettings(d *schema.ResourceData, meta interface{}, all bool) error {
+	response, err := resourceElasticsearchGetClusterSettings(meta)
+	if err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	for _, level := range []string{"persistent", "transient"} {
+		managed := d.Get(level).(map[string]interface{})
+		settings := make(map[string]interface{})
+		for key, value := range response[level] {
+			if _, ok := managed[key]; ok || all {
+				settings[key] = clusterSettingValue(value)
+			}
+		}
+		ds.set(level, settings)
+	}
+	ds.set("overlapping_settings", overlappingClusterSettings(d.Get("persistent").(map[string]interface{}), d.Get("transient").(map[string]interface{})))
+	return ds.err
+}
+
+func resourceElasticsearchClusterSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchPutClusterSettings(d, meta); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchClusterSettingsRead(d, meta)
+}
+
+func resourceElasticsearchClusterSettingsDelete(d *schema.ResourceData, meta interface{}) error {
+	body := make(map[string]interface{})
+	for _, level := range []string{"persistent", "transient"} {
+		settings := make(map[string]interface{})
+		for key := range d.Get(level).(map[string]interface{}) {
+			settings[key] = nil
+		}
+		body[level] = settings
+	}
+
+	return resourceElasticsearchRequestClusterSettings("PUT", body, meta, nil)
+}
+
+// resourceElasticsearchClusterSettingsCustomizeDiff warns about settings set
+// both persistently and transiently, as the transient value wins until the
+// next full cluster restart, which makes the effective value confusing. The
+// settings are planned as overlapping_settings, to show them in the plan.
+func resourceElasticsearchClusterSettingsCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("persistent") || !d.NewValueKnown("transient") {
+		return d.SetNewComputed("overlapping_settings")
+	}
+
+	overlapping := overlappingClusterSettings(d.Get("persistent").(map[string]interface{}), d.Get("transient").(map[string]interface{}))
+	if len(overlapping) > 0 {
+		log.Printf("[WARN] The cluster settings %s are set both persistently and transiently, the transient values take precedence until the next full cluster restart", strings.Join(overlapping, ", "))
+	}
+
+	if current := d.Get("overlapping_settings").([]interface{}); len(current) == len(overlapping) {
+		changed := false
+		for i := range overlapping {
+			if current[i] != overlapping[i] {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+	return d.SetNew("overlapping_settings", overlapping)
+}
+
+// overlappingClusterSettings returns the sorted keys set both persistently
+// and transiently.
+func overlappingClusterSettings(persistent, transient map[string]interface{}) []string {
+	overlapping := []string{}
+	for key := range persistent {
+		if _, ok := transient[key]; ok {
+			overlapping = append(overlapping, key)
+		}
+	}
+	sort.Strings(overlapping)
+	return overlapping
+}
+
+// resourceElasticsearchPutClusterSettings puts the configured settings,
+// resetting the removed ones with null.
+func resourceElasticsearchPutClusterSettings(d *schema.ResourceData, meta interface{}) error {
+	body := make(map[string]interface{})
+	for _, level := range []string{"persistent", "transient"} {
+		o, n := d.GetChange(level)
+		settings := make(map[string]interface{})
+		for key := range o.(map[string]interface{}) {
+			settings[key] = nil
+		}
+		for key, value := range n.(map[string]interface{}) {
+			settings[key] = value
+		}
+		body[level] = settings
+	}
+
+	if err := resourceElasticsearchRequestClusterSettings("PUT", body, meta, nil); err != nil {
+		return fmt.Errorf("error updating cluster settings: %+v", err)
+	}
+	return nil
+}
+
+// resourceElasticsearchGetClusterSettings returns the flat persistent and
+// transient settings of the cluster.
+func resourceElasticsearchGetClusterSettings(meta interface{}) (map[string]map[string]interface{}, error) {
+	var body json.RawMessage
+	if err := resourceElasticsearchRequestClusterSettings("GET", nil, meta, &body); err != nil {
+		return nil, err
+	}
+
+	var response map[string]map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling cluster settings body: %+v: %+v", err, string(body))
+	}
+	return response, nil
+}
+
+func resourceElasticsearchRequestClusterSettings(method string, body interface{}, meta interface{}, response *json.RawMessage) error {
+	params := url.Values{}
+	params.Set("flat_settings", "true")
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: method,
+			Path:   "/_cluster/settings",
+			Params: params,
+			Body:   body,
+		})
+		if err == nil && response != nil {
+			*response = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: method,
+			Path:   "/_cluster/settings",
+			Params: params,
+			Body:   body,
+		})
+		if err == nil && response != nil {
+			*response = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), method, "/_cluster/settings", params, body)
+		if err == nil && response != nil {
+			*response = res.Body
+		}
+	}
+
+	return err
+}
+
+// clusterSettingValue returns the value of a flat setting as a string, with
+// the values of list settings comma separated.
+func clusterSettingValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprint(item)
+		}
+		return strings.Join(values, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,150 @@
+package es
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestElasticsearchClusterSettings(t *testing.T) {
+	var put map[string]map[string]interface{}
+	settings := map[string]map[string]interface{}{
+		"persistent": {"action.auto_create_index": "false"},
+		"transient":  {},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/_cluster/settings" || r.URL.Query().Get("flat_settings") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		switch r.Method {
+		case "PUT":
+			put = nil
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			for level, values := range put {
+				for key, value := range values {
+					if value == nil {
+						delete(settings[level], key)
+					} else {
+						settings[level][key] = value
+					}
+				}
+			}
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case "GET":
+			body, _ := json.Marshal(settings)
+			fmt.Fprint(w, string(body))
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchClusterSettings().Schema, map[string]interface{}{
+		"persistent": map[string]interface{}{
+			"cluster.routing.allocation.enable":               "primaries",
+			"cluster.routing.allocation.awareness.attributes": "zone,rack",
+		},
+	})
+	if err := resourceElasticsearchClusterSettingsCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// settings which aren't managed are left as they are
+	expected := map[string]interface{}{
+		"cluster.routing.allocation.enable":               "primaries",
+		"cluster.routing.allocation.awareness.attributes": "zone,rack",
+	}
+	if persistent := resourceData.Get("persistent").(map[string]interface{}); !reflect.DeepEqual(persistent, expected) {
+		t.Errorf("expected the persistent settings %v, got %v", expected, persistent)
+	}
+	if settings["persistent"]["action.auto_create_index"] != "false" {
+		t.Errorf("expected the unmanaged setting to be kept, got %v", settings)
+	}
+
+	// list settings are read comma separated
+	settings["persistent"]["cluster.routing.allocation.awareness.attributes"] = []interface{}{"zone", "rack"}
+
+	state := resourceData.State()
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"persistent": map[string]interface{}{
+			"cluster.routing.allocation.awareness.attributes": "zone,rack",
+		},
+	})
+	diff, err := resourceElasticsearchClusterSettings().Diff(state, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = resourceElasticsearchClusterSettings().Apply(state, diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// removed settings are reset
+	if value, ok := put["persistent"]["cluster.routing.allocation.enable"]; !ok || value != nil {
+		t.Errorf("expected the removed setting to be reset with null, got %v", put)
+	}
+	if state.Attributes["persistent.cluster.routing.allocation.awareness.attributes"] != "zone,rack" {
+		t.Errorf("expected the list setting to be read comma separated, got %v", state.Attributes)
+	}
+
+	// import reads all the settings
+	resourceData = resourceElasticsearchClusterSettings().Data(&terraform.InstanceState{ID: clusterSettingsID})
+	if _, err := resourceElasticsearchClusterSettingsImport(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Get("persistent").(map[string]interface{})["action.auto_create_index"] != "false" {
+		t.Errorf("expected all the settings to be imported, got %v", resourceData.Get("persistent"))
+	}
+}
+
+func TestElasticsearchClusterSettingsOverlappingKey(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"persistent": map[string]interface{}{
+			"cluster.routing.allocation.enable":  "all",
+			"indices.recovery.max_bytes_per_sec": "100mb",
+		},
+		"transient": map[string]interface{}{
+			"cluster.routing.allocation.enable": "primaries",
+		},
+	})
+	diff, err := resourceElasticsearchClusterSettings().Diff(nil, config, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Empty() {
+		t.Fatal("expected the settings to be planned")
+	}
+	// the overlapping settings are shown in the plan
+	if attr := diff.Attributes["overlapping_settings.0"]; attr == nil || attr.New != "cluster.routing.allocation.enable" || diff.Attributes["overlapping_settings.#"].New != "1" {
+		t.Errorf("expected the overlapping setting to be planned, got %v", diff.Attributes)
+	}
+
+	if !strings.Contains(buf.String(), "[WARN] The cluster settings cluster.routing.allocation.enable are set both persistently and transiently") {
+		t.Errorf("expected a warning about the overlapping setting, got %s", buf.String())
+	}
+
+	overlapping := overlappingClusterSettings(
+		map[string]interface{}{"a": "1", "b": "1", "c": "1"},
+		map[string]interface{}{"c": "2", "a": "2", "d": "2"},
+	)
+	if !reflect.DeepEqual(overlapping, []string{"a", "c"}) {
+		t.Errorf("expected the overlapping settings a and c, got %v", overlapping)
+	}
+}
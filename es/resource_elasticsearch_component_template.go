@@ -0,0 +1,342 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func resourceElasticsearchComponentTemplate() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Provides an Elasticsearch component template, the building block of composable index templates. This resource uses the `/_component_template` endpoint of Elasticsearch API that is available since version 7.8.",
+		Create:        resourceElasticsearchComponentTemplateCreate,
+		Read:          resourceElasticsearchComponentTemplateRead,
+		Update:        resourceElasticsearchComponentTemplateUpdate,
+		Delete:        resourceElasticsearchComponentTemplateDelete,
+		CustomizeDiff: resourceElasticsearchComponentTemplateCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "The name of the component template.",
+			},
+			"body": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: diffSuppressComponentTemplate,
+				ValidateFunc:     validation.StringIsJSON,
+				Description:      "The JSON body of the component template.",
+			},
+			"auto_increment_version": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Increment the `version` of the component template whenever its body changes, instead of using the `version` of the body.",
+			},
+			"refresh_dependent_templates": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Put the composable index templates composed of the component template again once it changed, so that they resolve its new content.",
+			},
+			"version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version of the component template.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchComponentTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchPutComponentTemplate(d, meta, true)
+	if err != nil {
+		return err
+	}
+	d.SetId(d.Get("name").(string))
+	return resourceElasticsearchComponentTemplateRead(d, meta)
+}
+
+func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	client, err := elastic7ComponentTemplateClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_component_template/{name}", map[string]string{
+		"name": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for component template: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Component template (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+
+		return err
+	}
+
+	response := new(componentTemplatesResponse)
+	if err := json.Unmarshal(res.Body, response); err != nil {
+		return fmt.Errorf("error unmarshalling component template body: %+v: %+v", err, string(res.Body))
+	}
+	// No more than 1 element is expected, if the component template is not
+	// found, previous call should return a 404 error
+	if len(response.ComponentTemplates) == 0 {
+		log.Printf("[WARN] Component template (%s) not found, removing from state", id)
+		d.SetId("")
+		return nil
+	}
+	template := response.ComponentTemplates[0].ComponentTemplate
+
+	templateVersion, err := componentTemplateVersion(string(template))
+	if err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", d.Id())
+	ds.set("body", string(template))
+	ds.set("version", templateVersion)
+	return ds.err
+}
+
+func resourceElasticsearchComponentTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchPutComponentTemplate(d, meta, false)
+	if err != nil {
+		return err
+	}
+
+	if d.Get("refresh_dependent_templates").(bool) {
+		if err := resourceElasticsearchRefreshDependentTemplates(d.Id(), meta); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchComponentTemplateRead(d, meta)
+}
+
+func resourceElasticsearchComponentTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := elastic7ComponentTemplateClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_component_template/{name}", map[string]string{
+		"name": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for component template: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	if err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+// resourceElasticsearchComponentTemplateCustomizeDiff plans the next version
+// of the component template: the incremented one when its body changed and
+// auto_increment_version is set, the one of the body otherwise.
+func resourceElasticsearchComponentTemplateCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.NewValueKnown("body") {
+		return nil
+	}
+
+	currentVersion := d.Get("version").(int)
+	if d.Get("auto_increment_version").(bool) {
+		// the change of the body isn't suppressed in the resource diff
+		o, n := d.GetChange("body")
+		if !diffSuppressComponentTemplate("body", o.(string), n.(string), nil) {
+			return d.SetNew("version", currentVersion+1)
+		}
+		return nil
+	}
+
+	bodyVersion, err := componentTemplateVersion(d.Get("body").(string))
+	if err != nil {
+		return err
+	}
+	if bodyVersion != currentVersion {
+		return d.SetNew("version", bodyVersion)
+	}
+	return nil
+}
+
+func resourceElasticsearchPutComponentTemplate(d *schema.ResourceData, meta interface{}, create bool) error {
+	name := d.Get("name").(string)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("body").(string)), &body); err != nil {
+		return fmt.Errorf("error unmarshalling component template body: %+v", err)
+	}
+	// the version is left out of the comparison of the bodies, the planned
+	// one is put on update
+	if !create {
+		if templateVersion := d.Get("version").(int); templateVersion != 0 {
+			body["version"] = templateVersion
+		} else {
+			delete(body, "version")
+		}
+	} else if _, ok := body["version"]; !ok && d.Get("auto_increment_version").(bool) {
+		body["version"] = 1
+	}
+
+	client, err := elastic7ComponentTemplateClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_component_template/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for component template: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   body,
+	})
+	return err
+}
+
+// resourceElasticsearchRefreshDependentTemplates puts the composable index
+// templates composed of the component template again, as they are, so that
+// they resolve its new content.
+func resourceElasticsearchRefreshDependentTemplates(name string, meta interface{}) error {
+	client, err := elastic7ComponentTemplateClient(meta)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_index_template",
+	})
+	if err != nil {
+		return err
+	}
+
+	response := new(indexTemplatesResponse)
+	if err := json.Unmarshal(res.Body, response); err != nil {
+		return fmt.Errorf("error unmarshalling index templates body: %+v: %+v", err, string(res.Body))
+	}
+
+	for _, template := range response.IndexTemplates {
+		var composition struct {
+			ComposedOf []string `json:"composed_of"`
+		}
+		if err := json.Unmarshal(template.IndexTemplate, &composition); err != nil {
+			return fmt.Errorf("error unmarshalling index template %s: %+v", template.Name, err)
+		}
+		composed := false
+		for _, component := range composition.ComposedOf {
+			if component == name {
+				composed = true
+			}
+		}
+		if !composed {
+			continue
+		}
+
+		log.Printf("[INFO] Refreshing index template (%s) composed of component template (%s)", template.Name, name)
+		path, err := uritemplates.Expand("/_index_template/{name}", map[string]string{
+			"name": template.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("error building URL path for index template: %+v", err)
+		}
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "PUT",
+			Path:   path,
+			Body:   template.IndexTemplate,
+		})
+		if err != nil {
+			return fmt.Errorf("error refreshing index template %s: %+v", template.Name, err)
+		}
+	}
+	return nil
+}
+
+// elastic7ComponentTemplateClient returns the client of clusters supporting
+// component templates, i.e. Elasticsearch 7.8 or later and OpenSearch.
+func elastic7ComponentTemplateClient(meta interface{}) (*elastic7.Client, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		return nil, fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version < 7.0.0")
+	}
+
+	// the flavor of the cluster is known once the client is created
+	if meta.(*ProviderConf).flavor == OpenSearch {
+		return client, nil
+	}
+
+	var elasticVersion *version.Version
+	elasticVersion, err = elastic7GetVersion(client)
+	if err != nil {
+		return nil, err
+	}
+	if elasticVersion.LessThan(minimalVersion) {
+		return nil, fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
+	}
+	return client, nil
+}
+
+// componentTemplateVersion returns the version of the JSON body of a
+// component template, 0 when it has none.
+func componentTemplateVersion(body string) (int, error) {
+	var template struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		return 0, fmt.Errorf("error unmarshalling component template body: %+v", err)
+	}
+	return template.Version, nil
+}
+
+type componentTemplatesResponse struct {
+	ComponentTemplates []struct {
+		Name              string          `json:"name"`
+		ComponentTemplate json.RawMessage `json:"component_template"`
+	} `json:"component_templates"`
+}
+
+type indexTemplatesResponse struct {
+	IndexTemplates []struct {
+		Name          string          `json:"name"`
+		IndexTemplate json.RawMessage `json:"index_template"`
+	} `json:"index_templates"`
+}
@@ -0,0 +1,104 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestElasticsearchComponentTemplateAutoIncrementVersion(t *testing.T) {
+	var template map[string]interface{}
+	var refreshed []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.0"}}`)
+		case r.Method == "PUT" && r.URL.Path == "/_component_template/test":
+			template = nil
+			if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "GET" && r.URL.Path == "/_component_template/test":
+			body, _ := json.Marshal(map[string]interface{}{
+				"component_templates": []interface{}{
+					map[string]interface{}{"name": "test", "component_template": template},
+				},
+			})
+			fmt.Fprint(w, string(body))
+		case r.Method == "GET" && r.URL.Path == "/_index_template":
+			fmt.Fprint(w, `{"index_templates": [
+				{"name": "logs", "index_template": {"index_patterns": ["logs-*"], "composed_of": ["base", "test"], "priority": 10}},
+				{"name": "metrics", "index_template": {"index_patterns": ["metrics-*"], "composed_of": ["base"]}}
+			]}`)
+		case r.Method == "PUT" && r.URL.Path == "/_index_template/logs":
+			body, _ := ioutil.ReadAll(r.Body)
+			if !suppressEquivalentJson("", string(body), `{"index_patterns": ["logs-*"], "composed_of": ["base", "test"], "priority": 10}`, nil) {
+				t.Errorf("expected the index template to be put as it is, got %s", body)
+			}
+			refreshed = append(refreshed, "logs")
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	config := map[string]interface{}{
+		"name":                        "test",
+		"body":                        `{"template": {"settings": {"index": {"number_of_shards": "1"}}}}`,
+		"auto_increment_version":      true,
+		"refresh_dependent_templates": true,
+	}
+	diff, err := resourceElasticsearchComponentTemplate().Diff(nil, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := resourceElasticsearchComponentTemplate().Apply(nil, diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state.Attributes["version"] != "1" || template["version"] != 1.0 {
+		t.Errorf("expected the first version to be 1, got %v and %v", state.Attributes["version"], template)
+	}
+
+	// an unchanged body keeps the version
+	diff, err = resourceElasticsearchComponentTemplate().Diff(state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for an unchanged body, got %v", diff)
+	}
+
+	// a changed body increments the version and refreshes the dependent index
+	// templates
+	config["body"] = `{"template": {"settings": {"index": {"number_of_shards": "2"}}}}`
+	diff, err = resourceElasticsearchComponentTemplate().Diff(state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["version"] == nil || diff.Attributes["version"].New != "2" {
+		t.Fatalf("expected the version 2 to be planned, got %v", diff)
+	}
+	state, err = resourceElasticsearchComponentTemplate().Apply(state, diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state.Attributes["version"] != "2" || template["version"] != 2.0 {
+		t.Errorf("expected the version to be incremented to 2, got %v and %v", state.Attributes["version"], template)
+	}
+	if !reflect.DeepEqual(refreshed, []string{"logs"}) {
+		t.Errorf("expected only the dependent index template logs to be refreshed, got %v", refreshed)
+	}
+}
@@ -0,0 +1,224 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var dataStreamMinimalVersion, _ = version.NewVersion("7.9.0")
+
+func resourceElasticsearchDataStream() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch data stream resource. A data stream requires a matching composable index template with a `data_stream` object, see the `elasticsearch_composable_index_template` resource.",
+		Create:      resourceElasticsearchDataStreamCreate,
+		Read:        resourceElasticsearchDataStreamRead,
+		Delete:      resourceElasticsearchDataStreamDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the data stream to create, must have a matching composable index template.",
+				ForceNew:    true,
+				Required:    true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchDataStreamCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	client, err := getDataStreamClient(meta)
+	if err != nil {
+		return err
+	}
+
+	// without a matching template the API only reports that no index
+	// template matches, without hinting at the data_stream object
+	if err := dataStreamCheckTemplate(client, name); err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(name)
+	return resourceElasticsearchDataStreamRead(d, meta)
+}
+
+func resourceElasticsearchDataStreamRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	client, err := getDataStreamClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Data stream (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	// the generation, indices and status of the data stream change as it is
+	// rolled over, only its name is managed
+	var response struct {
+		DataStreams []struct {
+			Name string `json:"name"`
+		} `json:"data_streams"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling data stream body: %+v: %s", err, res.Body)
+	}
+	if len(response.DataStreams) == 0 {
+		log.Printf("[WARN] Data stream (%s) not found, removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", response.DataStreams[0].Name)
+	return ds.err
+}
+
+func resourceElasticsearchDataStreamDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getDataStreamClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
+
+func getDataStreamClient(meta interface{}) (*elastic7.Client, error) {
+	conf := meta.(*ProviderConf)
+	esClient, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		return nil, errors.New("data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+	}
+	if conf.flavor == OpenSearch {
+		return client, nil
+	}
+
+	elasticVersion, err := elastic7GetVersion(client)
+	if err != nil {
+		return nil, err
+	}
+	if elasticVersion.LessThan(dataStreamMinimalVersion) {
+		return nil, fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
+	}
+
+	return client, nil
+}
+
+// dataStreamCheckTemplate returns an error unless the composable index
+// template with the highest priority matching the name enables data streams.
+func dataStreamCheckTemplate(client *elastic7.Client, name string) error {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_index_template",
+	})
+	if err != nil {
+		return fmt.Errorf("error getting the index templates matching data stream %s: %+v", name, err)
+	}
+
+	var response struct {
+		IndexTemplates []struct {
+			Name          string `json:"name"`
+			IndexTemplate struct {
+				IndexPatterns []string    `json:"index_patterns"`
+				Priority      int         `json:"priority"`
+				DataStream    interface{} `json:"data_stream"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling index templates body: %+v: %s", err, res.Body)
+	}
+
+	matched := -1
+	for i, template := range response.IndexTemplates {
+		for _, pattern := range template.IndexTemplate.IndexPatterns {
+			if !indexPatternMatches(pattern, name) {
+				continue
+			}
+			if matched < 0 || template.IndexTemplate.Priority > response.IndexTemplates[matched].IndexTemplate.Priority {
+				matched = i
+			}
+		}
+	}
+
+	if matched < 0 {
+		return fmt.Errorf("no composable index template matches data stream %s, create one with index_patterns matching the name and a data_stream object first", name)
+	}
+	if template := response.IndexTemplates[matched]; template.IndexTemplate.DataStream == nil {
+		return fmt.Errorf("composable index template %s matching data stream %s doesn't enable data streams, add a data_stream object to its body", template.Name, name)
+	}
+
+	return nil
+}
+
+// indexPatternMatches returns whether the name matches the index pattern,
+// where `*` matches any characters.
+func indexPatternMatches(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	matched, _ := regexp.MatchString("^"+strings.Join(parts, ".*")+"$", name)
+	return matched
+}
@@ -0,0 +1,213 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccElasticsearchDataStream(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		elasticVersion, err := elastic7GetVersion(client)
+		allowed = err == nil && !elasticVersion.LessThan(dataStreamMinimalVersion)
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("/_data_stream endpoint only supported on ES >= 7.9")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchDataStreamDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataStream,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchDataStreamExists("elasticsearch_data_stream.test"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_data_stream.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestElasticsearchDataStreamCreate(t *testing.T) {
+	var created bool
+	templates := `{"index_templates": [
+  {"name": "logs", "index_template": {"index_patterns": ["logs-*"], "priority": 100, "data_stream": {}}},
+  {"name": "metrics", "index_template": {"index_patterns": ["metrics-*"], "priority": 100}}
+]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.0"}}`)
+		case r.Method == "GET" && r.URL.Path == "/_index_template":
+			fmt.Fprint(w, templates)
+		case r.Method == "PUT" && r.URL.Path == "/_data_stream/logs-app":
+			created = true
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "GET" && r.URL.Path == "/_data_stream/logs-app":
+			fmt.Fprint(w, `{"data_streams": [{
+  "name": "logs-app",
+  "timestamp_field": {"name": "@timestamp"},
+  "indices": [{"index_name": ".ds-logs-app-000001", "index_uuid": "abc"}],
+  "generation": 1,
+  "status": "GREEN",
+  "template": "logs"
+}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	dataStreamSchema := resourceElasticsearchDataStream().Schema
+
+	resourceData := schema.TestResourceDataRaw(t, dataStreamSchema, map[string]interface{}{
+		"name": "logs-app",
+	})
+	if err := resourceElasticsearchDataStreamCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !created || resourceData.Id() != "logs-app" {
+		t.Errorf("expected the data stream to be created, got ID %q", resourceData.Id())
+	}
+
+	cases := map[string]string{
+		"metrics-app": "composable index template metrics matching data stream metrics-app doesn't enable data streams",
+		"traces-app":  "no composable index template matches data stream traces-app",
+	}
+	for name, expected := range cases {
+		created = false
+		resourceData = schema.TestResourceDataRaw(t, dataStreamSchema, map[string]interface{}{
+			"name": name,
+		})
+		err := resourceElasticsearchDataStreamCreate(resourceData, meta)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error containing %q, got %v", expected, err)
+		}
+		if created {
+			t.Errorf("expected data stream %s not to be created", name)
+		}
+	}
+}
+
+func TestIndexPatternMatches(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"logs-*", "logs-app", true},
+		{"logs-*", "logs", false},
+		{"*-app", "logs-app", true},
+		{"logs.app", "logs-app", false},
+		{"logs-app", "logs-app", true},
+	}
+
+	for _, c := range cases {
+		if actual := indexPatternMatches(c.pattern, c.name); actual != c.expected {
+			t.Errorf("indexPatternMatches(%q, %q) = %t, expected %t", c.pattern, c.name, actual, c.expected)
+		}
+	}
+}
+
+func testCheckElasticsearchDataStreamExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No data stream ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+		client, err := getDataStreamClient(meta)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_data_stream/" + rs.Primary.ID,
+		})
+		return err
+	}
+}
+
+func testCheckElasticsearchDataStreamDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_data_stream" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+		client, err := getDataStreamClient(meta)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_data_stream/" + rs.Primary.ID,
+		})
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Data stream %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testAccElasticsearchDataStream = `
+resource "elasticsearch_composable_index_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-*"],
+  "data_stream": {},
+  "priority": 200
+}
+EOF
+}
+
+resource "elasticsearch_data_stream" "test" {
+  name       = "terraform-test-stream"
+  depends_on = [elasticsearch_composable_index_template.test]
+}
+`
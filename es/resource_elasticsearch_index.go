@@ -3,11 +3,20 @@ package es
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
@@ -20,11 +29,31 @@ var (
 		"codec",
 		"routing_partition_size",
 		"load_fixed_bitset_filters_eagerly",
+		"mapping.coerce",
+		"mapping.ignore_malformed",
 	}
 	dynamicsSettingsKeys = []string{
 		"number_of_replicas",
 		"auto_expand_replicas",
 		"refresh_interval",
+		"routing.allocation.total_shards_per_node",
+		"lifecycle.origination_date",
+		"lifecycle.parse_origination_date",
+		"merge.policy.deletes_pct_allowed",
+		"merge.policy.expunge_deletes_allowed",
+		"merge.policy.floor_segment",
+		"merge.policy.max_merge_at_once",
+		"merge.policy.max_merged_segment",
+		"merge.policy.segments_per_tier",
+		"blocks.read_only",
+		"blocks.read_only_allow_delete",
+		"blocks.read",
+		"blocks.write",
+		"blocks.metadata",
+		"mapping.depth.limit",
+		"mapping.nested_fields.limit",
+		"analyze.max_token_count",
+		"highlight.max_analyzed_offset",
 		//"max_result_window"
 		//"max_inner_result_window"
 		//"max_rescore_window"
@@ -50,11 +79,23 @@ var (
 		// Static settings that can only be set on creation
 		"number_of_shards": {
 			Type:        schema.TypeString,
-			Description: "Number of shards for the index. This can be set only on creation.",
-			ForceNew:    true,
+			Description: "Number of shards for the index. This can be set only on creation, unless `allow_split_on_shard_increase` is set.",
 			Default:     "1",
 			Optional:    true,
 		},
+		"allow_split_on_shard_increase": {
+			Type:        schema.TypeBool,
+			Description: "Split the index into a new index when `number_of_shards` is increased to a multiple of the current number, instead of recreating it. The new index replaces the old one behind an alias with the name of the index.",
+			Default:     false,
+			Optional:    true,
+		},
+		"shard_limit_check": {
+			Type:         schema.TypeString,
+			Description:  "Check before creating the index that its shards, `number_of_shards * (1 + number_of_replicas)`, fit in the remaining shard budget of the cluster, following from `cluster.max_shards_per_node`. One of `off`, `warn` to log a warning or `error` to fail. Only supported on Elasticsearch >= 7 and OpenSearch.",
+			Default:      "off",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"off", "warn", "error"}, false),
+		},
 		"routing_partition_size": {
 			Type:        schema.TypeInt,
 			Description: "The number of shards a custom routing value can go to. This can be set only on creation.",
@@ -73,6 +114,18 @@ var (
 			ForceNew:    true,
 			Optional:    true,
 		},
+		"mapping_coerce": {
+			Type:        schema.TypeBool,
+			Description: "Try to convert values of fields to the type of their mapping, e.g. strings to numbers. Enabled by default, set to `false` to reject documents with values of another type. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+		},
+		"mapping_ignore_malformed": {
+			Type:        schema.TypeBool,
+			Description: "Index documents with values which don't match the mapping of their field, without indexing these fields, instead of rejecting them. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+		},
 		// Dynamic settings that can be changed at runtime
 		"number_of_replicas": {
 			Type:        schema.TypeString,
@@ -85,26 +138,144 @@ var (
 			Optional:    true,
 		},
 		"refresh_interval": {
+			Type:             schema.TypeString,
+			Description:      "How often to perform a refresh operation, which makes recent changes to the index visible to search. Can be set to `-1` to disable refresh.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexTimeValue,
+		},
+		"routing_allocation_total_shards_per_node": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of shards (replicas and primaries) that will be allocated to a single node. Defaults to unbounded.",
+			Optional:    true,
+		},
+		"lifecycle_origination_date": {
+			Type:        schema.TypeString,
+			Description: "The timestamp, in milliseconds since the epoch, used to calculate the index age for its phase transitions with ILM. Useful for indices with pre-existing data.",
+			Optional:    true,
+		},
+		"lifecycle_parse_origination_date": {
+			Type:        schema.TypeBool,
+			Description: "Set `lifecycle_origination_date` by parsing the date from the index name, which must match the pattern `^.*-{date_format}-\\d+`.",
+			Optional:    true,
+		},
+		"analyze_max_token_count": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of tokens that can be produced using the `_analyze` API. Defaults to 10000.",
+			Optional:    true,
+		},
+		"highlight_max_analyzed_offset": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of characters analyzed for a highlight request, raise it to highlight large documents. Defaults to 1000000.",
+			Optional:    true,
+		},
+		"blocks_read_only": {
+			Type:        schema.TypeBool,
+			Description: "Set to `true` to make the index and its metadata read only, `false` to allow writes and metadata changes.",
+			Optional:    true,
+		},
+		"blocks_read_only_allow_delete": {
+			Type:        schema.TypeBool,
+			Description: "Identical to `blocks_read_only` but allows deleting the index to free up resources.",
+			Optional:    true,
+		},
+		"blocks_read": {
+			Type:        schema.TypeBool,
+			Description: "Set to `true` to disable read operations against the index.",
+			Optional:    true,
+		},
+		"blocks_write": {
+			Type:        schema.TypeBool,
+			Description: "Set to `true` to disable data write operations against the index. This setting does not affect metadata.",
+			Optional:    true,
+		},
+		"blocks_metadata": {
+			Type:        schema.TypeBool,
+			Description: "Set to `true` to disable index metadata reads and writes.",
+			Optional:    true,
+		},
+		"mapping_depth_limit": {
+			Type:        schema.TypeInt,
+			Description: "The maximum depth of a field, measured in the number of inner objects, e.g. 1 for fields of the root object. Defaults to 20. The `mappings` are checked against it when planning.",
+			Optional:    true,
+		},
+		"mapping_nested_fields_limit": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of distinct `nested` mappings in the index. Defaults to 50. The `mappings` are checked against it when planning.",
+			Optional:    true,
+		},
+		"merge_policy_deletes_pct_allowed": {
 			Type:        schema.TypeString,
-			Description: "How often to perform a refresh operation, which makes recent changes to the index visible to search. Can be set to `-1` to disable refresh.",
+			Description: "The maximum percentage of deleted documents in the index that the merge policy tolerates before merging segments.",
+			Optional:    true,
+		},
+		"merge_policy_expunge_deletes_allowed": {
+			Type:        schema.TypeString,
+			Description: "The percentage of deleted documents a segment must exceed to be merged by a force merge with `only_expunge_deletes`.",
+			Optional:    true,
+		},
+		"merge_policy_floor_segment": {
+			Type:             schema.TypeString,
+			Description:      "The size below which segments are rounded up by the merge policy, to avoid many tiny segments, e.g. `2mb`.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexByteSize,
+		},
+		"merge_policy_max_merge_at_once": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of segments merged at once during normal merging.",
+			Optional:    true,
+		},
+		"merge_policy_max_merged_segment": {
+			Type:             schema.TypeString,
+			Description:      "The maximum size of a segment produced by normal merging, e.g. `5gb`.",
+			Optional:         true,
+			DiffSuppressFunc: diffSuppressIndexByteSize,
+		},
+		"merge_policy_segments_per_tier": {
+			Type:        schema.TypeString,
+			Description: "The number of segments allowed per tier. Smaller values mean more merging but fewer segments.",
 			Optional:    true,
 		},
 		// Other attributes
 		"mappings": {
 			Type:         schema.TypeString,
-			Description:  "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. To avoid the complexities of field mapping updates, updates of this field are not allowed via this provider. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/6.8/indices-put-mapping.html#updating-field-mappings) for more details.",
+			Description:  "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. To avoid the complexities of field mapping updates, updates of this field are not allowed via this provider. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/6.8/indices-put-mapping.html#updating-field-mappings) for more details. The `_size` and `_doc_count` meta-fields are read back from the index; `_size` requires the [mapper-size plugin](https://www.elastic.co/guide/en/elasticsearch/plugins/current/mapper-size.html).",
 			Optional:     true,
 			ForceNew:     true,
-			ValidateFunc: validation.StringIsJSON,
+			ValidateFunc: validateIndexMappings,
 		},
 		"aliases": {
 			Type:        schema.TypeString,
 			Description: "A JSON string describing a set of aliases. The index aliases API allows aliasing an index with a name, with all APIs automatically converting the alias name to the actual index name. An alias can also be mapped to more than one index, and when specifying it, the alias will automatically expand to the aliased indices.",
 			Optional:    true,
-			// In order to not handle the separate endpoint of alias updates, updates
-			// are not allowed via this provider currently.
-			ForceNew:     true,
-			ValidateFunc: validation.StringIsJSON,
+			// Updates are applied in a single request to the aliases API, which
+			// moves is_write_index between indices atomically.
+			DiffSuppressFunc: diffSuppressIndexAliases,
+			ValidateFunc:     validation.StringIsJSON,
+		},
+		"timeout": {
+			Type:         schema.TypeString,
+			Description:  "How long the server waits for the index to be created, e.g. `60s`, passed as the `timeout` parameter of the create request. Independent of the timeouts of the resource, which bound the retries of the provider.",
+			Optional:     true,
+			ValidateFunc: validation.StringMatch(timeValueRegexp, "must be a time value with a unit, e.g. 60s"),
+		},
+		"master_timeout": {
+			Type:         schema.TypeString,
+			Description:  "How long the server waits for the master node to create the index, e.g. `60s`, passed as the `master_timeout` parameter of the create request.",
+			Optional:     true,
+			ValidateFunc: validation.StringMatch(timeValueRegexp, "must be a time value with a unit, e.g. 60s"),
+		},
+		"wait_for_status": {
+			Type:         schema.TypeString,
+			Description:  "Wait after creating the index until its health is at least this status, one of `green`, `yellow` or `red`, e.g. `green` to wait for its replicas to be allocated. The cluster health API is polled until `wait_for_status_timeout` has elapsed.",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"green", "yellow", "red"}, false),
+		},
+		"wait_for_status_timeout": {
+			Type:         schema.TypeString,
+			Description:  "How long to wait for the index to reach `wait_for_status` after creating it, e.g. `5m`.",
+			Optional:     true,
+			Default:      "30s",
+			ValidateFunc: validation.StringMatch(timeValueRegexp, "must be a time value with a unit, e.g. 60s"),
 		},
 		// Computed attributes
 		"rollover_alias": {
@@ -117,18 +288,247 @@ var (
 
 func resourceElasticsearchIndex() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch index resource.",
-		Create:      resourceElasticsearchIndexCreate,
-		Read:        resourceElasticsearchIndexRead,
-		Update:      resourceElasticsearchIndexUpdate,
-		Delete:      resourceElasticsearchIndexDelete,
-		Schema:      configSchema,
+		Description:   "Provides an Elasticsearch index resource.",
+		Create:        resourceElasticsearchIndexCreate,
+		Read:          resourceElasticsearchIndexRead,
+		Update:        resourceElasticsearchIndexUpdate,
+		Delete:        resourceElasticsearchIndexDelete,
+		Schema:        configSchema,
+		CustomizeDiff: resourceElasticsearchIndexCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+		},
 	}
 }
 
+// resourceElasticsearchIndexCustomizeDiff recreates the index when the
+// number of shards changes, unless the index can be split instead. It also
+// logs warnings about mappings exceeding configured mapping limits, the
+// default limits are checked by validateIndexMappings.
+func resourceElasticsearchIndexCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	depthLimit, depthConfigured := d.GetOk("mapping_depth_limit")
+	nestedFieldsLimit, nestedFieldsConfigured := d.GetOk("mapping_nested_fields_limit")
+	if (depthConfigured || nestedFieldsConfigured) && (d.HasChange("mappings") || d.HasChange("mapping_depth_limit") || d.HasChange("mapping_nested_fields_limit")) {
+		if !depthConfigured {
+			depthLimit = defaultMappingDepthLimit
+		}
+		if !nestedFieldsConfigured {
+			nestedFieldsLimit = defaultMappingNestedFieldsLimit
+		}
+		for _, warning := range indexMappingLimitWarnings(d.Get("mappings").(string), depthLimit.(int), nestedFieldsLimit.(int)) {
+			log.Printf("[WARN] Index %s: %s", d.Get("name").(string), warning)
+		}
+	}
+
+	if d.Id() == "" || !d.HasChange("number_of_shards") {
+		return nil
+	}
+
+	// indices behind a rollover alias are replaced by rollovers instead
+	o, n := d.GetChange("number_of_shards")
+	_, rollover := d.GetOk("rollover_alias")
+	if d.Get("allow_split_on_shard_increase").(bool) && !rollover && indexShardsSplittable(o.(string), n.(string)) {
+		return nil
+	}
+
+	return d.ForceNew("number_of_shards")
+}
+
+// The defaults of mapping.depth.limit and mapping.nested_fields.limit.
+const (
+	defaultMappingDepthLimit        = 20
+	defaultMappingNestedFieldsLimit = 50
+)
+
+// validateIndexMappings validates the JSON of the mappings, and warns about
+// mappings exceeding the default mapping limits. The warnings are shown when
+// planning, unlike the ones about configured limits, which the validation
+// can't read.
+func validateIndexMappings(i interface{}, k string) (warnings []string, errors []error) {
+	warnings, errors = validation.StringIsJSON(i, k)
+	if len(errors) > 0 {
+		return
+	}
+
+	for _, warning := range indexMappingLimitWarnings(i.(string), defaultMappingDepthLimit, defaultMappingNestedFieldsLimit) {
+		warnings = append(warnings, fmt.Sprintf("%s: %s by default, documents are rejected when indexed unless mapping_depth_limit or mapping_nested_fields_limit raise the limit", k, warning))
+	}
+	return
+}
+
+// indexMappingLimitWarnings returns a warning for each mapping limit the
+// mappings exceed, which would only fail when documents are indexed. Mappings
+// which aren't known yet, or aren't valid JSON, aren't checked.
+func indexMappingLimitWarnings(mappingsJSON string, depthLimit, nestedFieldsLimit int) []string {
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON), &mappings); err != nil {
+		return nil
+	}
+
+	var warnings []string
+	depth, nestedFields := indexMappingDepthAndNestedFields(mappings)
+	if depth > depthLimit {
+		warnings = append(warnings, fmt.Sprintf("the mappings have a depth of %d, exceeding mapping.depth.limit of %d", depth, depthLimit))
+	}
+	if nestedFields > nestedFieldsLimit {
+		warnings = append(warnings, fmt.Sprintf("the mappings have %d nested fields, exceeding mapping.nested_fields.limit of %d", nestedFields, nestedFieldsLimit))
+	}
+
+	return warnings
+}
+
+// indexMappingDepthAndNestedFields returns the depth of the deepest field of
+// the mappings, fields of the root object having a depth of 1, and the
+// number of fields of type nested. Mappings of Elasticsearch 6 are wrapped in
+// an object named after their type.
+func indexMappingDepthAndNestedFields(mappings map[string]interface{}) (int, int) {
+	if _, ok := mappings["properties"]; !ok {
+		for _, v := range mappings {
+			if wrapped, ok := v.(map[string]interface{}); ok {
+				if _, ok := wrapped["properties"]; ok {
+					mappings = wrapped
+				}
+			}
+		}
+	}
+
+	var walk func(properties map[string]interface{}, level int) (int, int)
+	walk = func(properties map[string]interface{}, level int) (int, int) {
+		depth, nestedFields := 0, 0
+		for _, f := range properties {
+			field, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if level > depth {
+				depth = level
+			}
+			if field["type"] == "nested" {
+				nestedFields++
+			}
+			if inner, ok := field["properties"].(map[string]interface{}); ok {
+				innerDepth, innerNestedFields := walk(inner, level+1)
+				if innerDepth > depth {
+					depth = innerDepth
+				}
+				nestedFields += innerNestedFields
+			}
+		}
+		return depth, nestedFields
+	}
+
+	properties, _ := mappings["properties"].(map[string]interface{})
+	return walk(properties, 1)
+}
+
+// indexMappingMetaFields are the meta-fields of the mappings which are read
+// back from the index: _size is added by the mapper-size plugin, and
+// _doc_count is used by documents holding pre-aggregated data.
+var indexMappingMetaFields = []string{"_size", "_doc_count"}
+
+// indexMappingsWithMetaFields returns the declared mappings with the
+// meta-fields of the mappings of the index, so that they round-trip. It
+// returns the declared mappings unchanged if the meta-fields are equal.
+// Typed mappings, of Elasticsearch 6 and before, are merged per type.
+func indexMappingsWithMetaFields(mappingsJSON string, indexMappings map[string]interface{}, typed bool) (string, error) {
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON), &mappings); err != nil {
+		return "", fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	changed := false
+	merge := func(declared, index map[string]interface{}) {
+		for _, field := range indexMappingMetaFields {
+			value, ok := index[field]
+			declaredValue, declaredOk := declared[field]
+			switch {
+			case ok && !reflect.DeepEqual(value, declaredValue):
+				declared[field] = value
+				changed = true
+			// disabled meta-fields may be omitted by the server
+			case !ok && declaredOk && !indexMappingMetaFieldEnabled(declaredValue):
+			case !ok && declaredOk:
+				delete(declared, field)
+				changed = true
+			}
+		}
+	}
+
+	if typed {
+		for typeName, v := range mappings {
+			declared, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			index, _ := indexMappings[typeName].(map[string]interface{})
+			merge(declared, index)
+		}
+	} else {
+		merge(mappings, indexMappings)
+	}
+
+	if !changed {
+		return mappingsJSON, nil
+	}
+	out, err := json.Marshal(mappings)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// indexMappingMetaFieldEnabled returns whether a meta-field is enabled, i.e.
+// declared with anything but `"enabled": false`.
+func indexMappingMetaFieldEnabled(v interface{}) bool {
+	field, ok := v.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	enabled, ok := field["enabled"].(bool)
+	return !ok || enabled
+}
+
+// isUnsupportedSizeFieldError returns whether the index was rejected because
+// its mappings use the _size meta-field without the mapper-size plugin.
+func isUnsupportedSizeFieldError(err error) bool {
+	var errorType, reason string
+	switch e := err.(type) {
+	case *elastic7.Error:
+		if e.Details != nil {
+			errorType, reason = e.Details.Type, e.Details.Reason
+		}
+	case *elastic6.Error:
+		if e.Details != nil {
+			errorType, reason = e.Details.Type, e.Details.Reason
+		}
+	case *elastic5.Error:
+		if e.Details != nil {
+			errorType, reason = e.Details.Type, e.Details.Reason
+		}
+	}
+
+	return errorType == "mapper_parsing_exception" && strings.Contains(reason, "unsupported parameters") && strings.Contains(reason, "[_size")
+}
+
+// indexShardsSplittable returns whether an index with old shards can be split
+// into new shards, which must be a multiple of the old number.
+func indexShardsSplittable(old, new string) bool {
+	o, err := strconv.Atoi(old)
+	if err != nil || o < 1 {
+		return false
+	}
+	n, err := strconv.Atoi(new)
+	if err != nil {
+		return false
+	}
+
+	return n > o && n%o == 0
+}
+
 func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{}) error {
 	var (
 		name     = d.Get("name").(string)
@@ -161,68 +561,365 @@ func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{})
 		body["mappings"] = mappings
 	}
 
+	if check := d.Get("shard_limit_check").(string); check != "off" {
+		if err := resourceElasticsearchIndexCheckShardLimit(d, meta, check == "error"); err != nil {
+			return err
+		}
+	}
+
 	// if date math is used, we need to pass the resolved name along to the read
 	// so we can pull the right result from the response
 	var resolvedName string
 
+	// empty values aren't sent
+	timeout := d.Get("timeout").(string)
+	masterTimeout := d.Get("master_timeout").(string)
+
 	// Note: the CreateIndex call handles URL encoding under the hood to handle
 	// non-URL friendly characters and functionality like date math
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
-		err = requestErr
-		if err == nil {
+	err = retryWhileClusterBlocked(d.Timeout(schema.TimeoutCreate), func() error {
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			resp, err := client.CreateIndex(name).BodyJson(body).Timeout(timeout).MasterTimeout(masterTimeout).Do(ctx)
+			if err != nil {
+				return err
+			}
 			resolvedName = resp.Index
-		}
 
-	case *elastic6.Client:
-		resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
-		err = requestErr
-		if err == nil {
+		case *elastic6.Client:
+			resp, err := client.CreateIndex(name).BodyJson(body).Timeout(timeout).MasterTimeout(masterTimeout).Do(ctx)
+			if err != nil {
+				return err
+			}
 			resolvedName = resp.Index
-		}
 
-	default:
-		elastic5Client := client.(*elastic5.Client)
-		resp, requestErr := elastic5Client.CreateIndex(name).BodyJson(body).Do(ctx)
-		err = requestErr
-		if err == nil {
+		default:
+			elastic5Client := client.(*elastic5.Client)
+			resp, err := elastic5Client.CreateIndex(name).BodyJson(body).Timeout(timeout).MasterTimeout(masterTimeout).Do(ctx)
+			if err != nil {
+				return err
+			}
 			resolvedName = resp.Index
 		}
+		return nil
+	})
 
+	if isUnsupportedSizeFieldError(err) {
+		return fmt.Errorf("the mappings of index %s use the `_size` meta-field, which requires the mapper-size plugin to be installed on all nodes of the cluster: %+v", name, err)
 	}
 
-	if err == nil {
-		// Let terraform know the resource was created
-		d.SetId(resolvedName)
-		return resourceElasticsearchIndexRead(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// Let terraform know the resource was created
+	d.SetId(resolvedName)
+
+	if status := d.Get("wait_for_status").(string); status != "" {
+		timeout, err := time.ParseDuration(canonicalTimeValue(d.Get("wait_for_status_timeout").(string)))
+		if err != nil {
+			return fmt.Errorf("invalid wait_for_status_timeout: %+v", err)
+		}
+		if err := resourceElasticsearchIndexWaitForStatus(resolvedName, status, timeout, meta); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchIndexRead(d, meta)
+}
+
+// indexHealthPollTimeout bounds how long a single request to the cluster
+// health API waits for the status of the index.
+const indexHealthPollTimeout = 10 * time.Second
+
+type indexHealthResponse struct {
+	Status   string `json:"status"`
+	TimedOut bool   `json:"timed_out"`
+}
+
+// resourceElasticsearchIndexWaitForStatus polls the health of the index until
+// it has at least the status, or the timeout has elapsed.
+func resourceElasticsearchIndexWaitForStatus(name, status string, timeout time.Duration, meta interface{}) error {
+	path, err := uritemplates.Expand("/_cluster/health/{index}", map[string]string{
+		"index": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for index health: %+v", err)
+	}
+
+	pollTimeout := indexHealthPollTimeout
+	if timeout < pollTimeout {
+		pollTimeout = timeout
+	}
+	params := url.Values{}
+	params.Set("wait_for_status", status)
+	params.Set("timeout", fmt.Sprintf("%dms", pollTimeout.Milliseconds()))
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var current string
+	timedOut := false
+	err = resource.Retry(timeout, func() *resource.RetryError {
+		timedOut = false
+		// the cluster health API responds with 408 when the status isn't
+		// reached within the timeout of the request
+		var body json.RawMessage
+		var err error
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			var res *elastic7.Response
+			res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+				Method:       "GET",
+				Path:         path,
+				Params:       params,
+				IgnoreErrors: []int{http.StatusRequestTimeout},
+			})
+			if err == nil {
+				body = res.Body
+			}
+		case *elastic6.Client:
+			var res *elastic6.Response
+			res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+				Method:       "GET",
+				Path:         path,
+				Params:       params,
+				IgnoreErrors: []int{http.StatusRequestTimeout},
+			})
+			if err == nil {
+				body = res.Body
+			}
+		default:
+			var res *elastic5.Response
+			res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, params, nil, http.StatusRequestTimeout)
+			if err == nil {
+				body = res.Body
+			}
+		}
+		if err != nil {
+			return resource.NonRetryableError(fmt.Errorf("error getting the health of index %s: %+v", name, err))
+		}
+
+		var health indexHealthResponse
+		if err := json.Unmarshal(body, &health); err != nil {
+			return resource.NonRetryableError(fmt.Errorf("error unmarshalling index health body: %+v: %+v", err, string(body)))
+		}
+		current, timedOut = health.Status, health.TimedOut
+		if health.TimedOut {
+			log.Printf("[INFO] Index %s is %s, waiting for %s", name, health.Status, status)
+			return resource.RetryableError(fmt.Errorf("index %s is %s", name, health.Status))
+		}
+		return nil
+	})
+
+	if err != nil && timedOut {
+		return fmt.Errorf("index %s did not reach status %s within %s, its status is %s", name, status, timeout, current)
+	}
+	return err
+}
+
+// retryWhileClusterBlocked retries f while the cluster blocks the request,
+// e.g. while it is recovering without an elected master, until the timeout
+// has elapsed.
+func retryWhileClusterBlocked(timeout time.Duration, f func() error) error {
+	blocked := false
+	err := resource.Retry(timeout, func() *resource.RetryError {
+		err := f()
+		blocked = isClusterBlockError(err)
+		if blocked {
+			log.Printf("[INFO] Request blocked by the cluster, retrying: %+v", err)
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+
+	if err != nil && blocked {
+		return fmt.Errorf("the cluster block was not lifted within %s: %+v", timeout, err)
 	}
+
 	return err
 }
 
+// isClusterBlockError returns whether the error is a cluster_block_exception.
+func isClusterBlockError(err error) bool {
+	switch e := err.(type) {
+	case *elastic7.Error:
+		return e.Details != nil && e.Details.Type == "cluster_block_exception"
+	case *elastic6.Error:
+		return e.Details != nil && e.Details.Type == "cluster_block_exception"
+	case *elastic5.Error:
+		return e.Details != nil && e.Details.Type == "cluster_block_exception"
+	}
+
+	return false
+}
+
+// defaultMaxShardsPerNode is the default of cluster.max_shards_per_node.
+const defaultMaxShardsPerNode = 1000
+
+type clusterSettingsResponse struct {
+	Persistent map[string]interface{} `json:"persistent"`
+	Transient  map[string]interface{} `json:"transient"`
+	Defaults   map[string]interface{} `json:"defaults"`
+}
+
+// resourceElasticsearchIndexCheckShardLimit estimates the shards of the index
+// and compares them to the shards left in the cluster before the limit of
+// cluster.max_shards_per_node is reached, to not fail in the middle of an
+// apply. Exceeding the limit is an error if fail is set, otherwise a warning.
+func resourceElasticsearchIndexCheckShardLimit(d *schema.ResourceData, meta interface{}, fail bool) error {
+	name := d.Get("name").(string)
+	shards, err := strconv.Atoi(d.Get("number_of_shards").(string))
+	if err != nil {
+		return fmt.Errorf("invalid number_of_shards: %+v", err)
+	}
+	// replicas default to 1, also when they are expanded automatically
+	replicas := 1
+	if v, ok := d.GetOk("number_of_replicas"); ok {
+		if replicas, err = strconv.Atoi(v.(string)); err != nil {
+			return fmt.Errorf("invalid number_of_replicas: %+v", err)
+		}
+	}
+	required := shards * (1 + replicas)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		log.Printf("[WARN] Skipping the shard limit check of index %s, cluster.max_shards_per_node is only enforced from Elasticsearch 7", name)
+		return nil
+	}
+
+	maxShardsPerNode, err := elastic7MaxShardsPerNode(client)
+	if err != nil {
+		return err
+	}
+	health, err := client.ClusterHealth().Do(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error getting the cluster health: %+v", err)
+	}
+
+	limit := maxShardsPerNode * health.NumberOfDataNodes
+	used := health.ActiveShards + health.InitializingShards + health.UnassignedShards
+	if used+required <= limit {
+		return nil
+	}
+
+	message := fmt.Sprintf(
+		"index %s requires %d shards, but only %d of the %d shards of the cluster are left (cluster.max_shards_per_node is %d on %d data nodes)",
+		name, required, limit-used, limit, maxShardsPerNode, health.NumberOfDataNodes,
+	)
+	if fail {
+		return errors.New(message)
+	}
+	log.Printf("[WARN] %s", message)
+	return nil
+}
+
+// elastic7MaxShardsPerNode returns the effective value of
+// cluster.max_shards_per_node, transient settings taking precedence over
+// persistent settings.
+func elastic7MaxShardsPerNode(client *elastic7.Client) (int, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cluster/settings",
+		Params: url.Values{
+			"include_defaults": []string{"true"},
+			"flat_settings":    []string{"true"},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting the cluster settings: %+v", err)
+	}
+
+	settings := new(clusterSettingsResponse)
+	if err := json.Unmarshal(res.Body, settings); err != nil {
+		return 0, fmt.Errorf("error unmarshalling cluster settings body: %+v: %+v", err, res.Body)
+	}
+
+	for _, s := range []map[string]interface{}{settings.Transient, settings.Persistent, settings.Defaults} {
+		if v, ok := s["cluster.max_shards_per_node"]; ok {
+			return strconv.Atoi(fmt.Sprint(v))
+		}
+	}
+
+	return defaultMaxShardsPerNode, nil
+}
+
+// indexSettingSchemaName returns the name of the attribute for an index
+// setting, e.g. routing_allocation_total_shards_per_node for
+// routing.allocation.total_shards_per_node.
+func indexSettingSchemaName(key string) string {
+	return strings.Replace(key, ".", "_", -1)
+}
+
+// indexSettingOk returns the value of the attribute of an index setting, and
+// whether it is set. Booleans set to false are set, e.g. to disable
+// mapping.coerce which is enabled by default.
+func indexSettingOk(d *schema.ResourceData, schemaName string) (interface{}, bool) {
+	if configSchema[schemaName].Type == schema.TypeBool {
+		return d.GetOkExists(schemaName)
+	}
+	return d.GetOk(schemaName)
+}
+
 func settingsFromIndexResourceData(d *schema.ResourceData) map[string]interface{} {
 	settings := make(map[string]interface{})
 	for _, key := range settingsKeys {
-		if raw, ok := d.GetOk(key); ok {
+		if raw, ok := indexSettingOk(d, indexSettingSchemaName(key)); ok {
 			settings[key] = raw
 		}
 	}
 	return settings
 }
 
-func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.ResourceData) {
+func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.ResourceData, all bool) {
+	flattened := canonicalIndexSettings(settings)
 	for _, key := range settingsKeys {
-		err := d.Set(key, settings[key])
+		schemaName := indexSettingSchemaName(key)
+		if _, ok := indexSettingOk(d, schemaName); !ok && !all {
+			continue
+		}
+		err := d.Set(schemaName, indexSettingValue(schemaName, flattened[key]))
 		if err != nil {
 			log.Printf("[INFO] indexResourceDataFromSettings: %+v", err)
 		}
 	}
 }
 
+// indexSettingValue converts the string values returned by the settings API
+// to the type of the attribute.
+func indexSettingValue(schemaName string, value interface{}) interface{} {
+	raw, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	switch configSchema[schemaName].Type {
+	case schema.TypeInt:
+		if i, err := strconv.Atoi(raw); err == nil {
+			return i
+		}
+	case schema.TypeBool:
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
+
 func resourceElasticsearchIndexDelete(d *schema.ResourceData, meta interface{}) error {
 	var (
 		name = d.Id()
@@ -295,18 +992,99 @@ func allowIndexDestroy(indexName string, d *schema.ResourceData, meta interface{
 }
 
 func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{}) error {
+	// any other change of the number of shards recreates the index
+	if d.HasChange("number_of_shards") {
+		if err := resourceElasticsearchIndexSplit(d, meta); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("aliases") {
+		if err := resourceElasticsearchIndexUpdateAliases(d, meta); err != nil {
+			return err
+		}
+	}
+
+	if err := resourceElasticsearchIndexUpdateSettings(d, meta); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchIndexRead(d, meta)
+}
+
+// resourceElasticsearchIndexUpdateSettings puts the changed dynamic settings.
+// Blocks preventing metadata writes are applied after the other settings, and
+// cleared before them.
+func resourceElasticsearchIndexUpdateSettings(d *schema.ResourceData, meta interface{}) error {
+	blocks := make(map[string]interface{})
 	settings := make(map[string]interface{})
 	for _, key := range settingsKeys {
-		if d.HasChange(key) {
-			settings[key] = d.Get(key)
+		schemaName := indexSettingSchemaName(key)
+		if key == "number_of_shards" {
+			continue
+		}
+		if !d.HasChange(schemaName) {
+			continue
+		}
+
+		// a removed setting is reset to its default
+		var value interface{}
+		if v, ok := indexSettingOk(d, schemaName); ok {
+			value = v
+		}
+		if strings.HasPrefix(key, "blocks.") {
+			// blocks are off by default, a removed or disabled block is reset
+			if value == false {
+				value = nil
+			}
+			blocks[key] = value
+		} else {
+			settings[key] = value
 		}
 	}
 
-	// if we're not changing any settings, no-op this function
-	if len(settings) == 0 {
-		return resourceElasticsearchIndexRead(d, meta)
+	wasBlocked, blocked := indexMetadataBlocked(d)
+	if wasBlocked != "" && blocked != "" && len(settings) > 0 {
+		return fmt.Errorf("index %s has a %s block, which prevents changing its settings; clear the block by removing or disabling `%s` first, then change the other settings", d.Id(), blocked, indexSettingSchemaName(blocked))
 	}
 
+	batches := []map[string]interface{}{blocks, settings}
+	if blocked != "" {
+		batches = []map[string]interface{}{settings, blocks}
+	}
+	for _, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		if err := resourceElasticsearchIndexPutSettings(d, meta, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexMetadataBlocks are the blocks which prevent changing the settings of
+// an index.
+var indexMetadataBlocks = []string{"blocks.read_only", "blocks.read_only_allow_delete", "blocks.metadata"}
+
+// indexMetadataBlocked returns the blocks preventing changes of the settings
+// before and after the update, if any.
+func indexMetadataBlocked(d *schema.ResourceData) (string, string) {
+	var wasBlocked, blocked string
+	for _, key := range indexMetadataBlocks {
+		o, n := d.GetChange(indexSettingSchemaName(key))
+		if o.(bool) && wasBlocked == "" {
+			wasBlocked = key
+		}
+		if n.(bool) && blocked == "" {
+			blocked = key
+		}
+	}
+	return wasBlocked, blocked
+}
+
+func resourceElasticsearchIndexPutSettings(d *schema.ResourceData, meta interface{}, settings map[string]interface{}) error {
 	body := map[string]interface{}{
 		"settings": settings,
 	}
@@ -321,26 +1099,315 @@ func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{})
 		name = getWriteIndexByAlias(alias.(string), d, meta)
 	}
 
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	return retryWhileClusterBlocked(d.Timeout(schema.TimeoutUpdate), func() error {
+		var err error
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+
+		case *elastic6.Client:
+			_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+
+		default:
+			elastic5Client := client.(*elastic5.Client)
+			_, err = elastic5Client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		}
+		return err
+	})
+}
+
+// resourceElasticsearchIndexUpdateAliases applies the changes of the aliases
+// of the index in a single request to the aliases API. An index becoming the
+// write index of an alias takes the flag over from the current write index in
+// the same request, so the alias never has zero or two write indices.
+func resourceElasticsearchIndexUpdateAliases(d *schema.ResourceData, meta interface{}) error {
+	o, n := d.GetChange("aliases")
+	var oldAliases, newAliases map[string]interface{}
+	if o.(string) != "" {
+		if err := json.Unmarshal([]byte(o.(string)), &oldAliases); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+	}
+	if n.(string) != "" {
+		if err := json.Unmarshal([]byte(n.(string)), &newAliases); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+	}
+
+	var names []string
+	for alias := range newAliases {
+		names = append(names, alias)
+	}
+	writeIndices, err := indexAliasWriteIndices(meta, names)
+	if err != nil {
+		return err
+	}
+
+	actions := indexAliasActions(d.Id(), oldAliases, newAliases, writeIndices)
+	if len(actions) == 0 {
+		return nil
+	}
+	body := map[string]interface{}{"actions": actions}
+
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   body,
+		})
+	default:
+		_, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "POST", "/_aliases", nil, body)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating aliases of index %s: %+v", d.Id(), err)
+	}
+
+	return nil
+}
+
+// indexAliasActions returns the actions of the aliases API changing the
+// aliases of index from old to new, given the current write indices of the
+// aliases.
+func indexAliasActions(index string, old, new map[string]interface{}, writeIndices map[string][]string) []map[string]interface{} {
+	var actions []map[string]interface{}
+
+	for alias := range old {
+		if _, ok := new[alias]; !ok {
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]interface{}{"index": index, "alias": alias},
+			})
+		}
+	}
+
+	for alias, a := range new {
+		properties, _ := a.(map[string]interface{})
+		if oldProperties, ok := old[alias]; ok && reflect.DeepEqual(oldProperties, a) {
+			continue
+		}
+
+		add := map[string]interface{}{"index": index, "alias": alias}
+		for k, v := range properties {
+			add[k] = v
+		}
 
+		var others []string
+		isWriteIndex := false
+		for _, writeIndex := range writeIndices[alias] {
+			if writeIndex == index {
+				isWriteIndex = true
+			} else {
+				others = append(others, writeIndex)
+			}
+		}
+
+		switch add["is_write_index"] {
+		case true:
+			// take the flag over from the current write index
+			for _, other := range others {
+				actions = append(actions, map[string]interface{}{
+					"add": map[string]interface{}{"index": other, "alias": alias, "is_write_index": false},
+				})
+			}
+		case false:
+			// the index stays the write index until another index takes over
+			if len(others) == 0 && isWriteIndex {
+				log.Printf("[WARN] Index %s stays the write index of alias %s until another index becomes its write index", index, alias)
+				add["is_write_index"] = true
+			}
+		}
+
+		actions = append(actions, map[string]interface{}{"add": add})
+	}
+
+	return actions
+}
+
+// indexAliasWriteIndices returns the indices which are the write index of
+// each of the aliases.
+func indexAliasWriteIndices(meta interface{}, aliases []string) (map[string][]string, error) {
+	writeIndices := make(map[string][]string)
+	if len(aliases) == 0 {
+		return writeIndices, nil
+	}
+
+	path, err := uritemplates.Expand("/_alias/{alias}", map[string]string{
+		"alias": strings.Join(aliases, ","),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building URL path for aliases: %+v", err)
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
-		_, err = client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, nil, nil, http.StatusNotFound)
+		if err == nil {
+			body = res.Body
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading aliases %s: %+v", strings.Join(aliases, ","), err)
+	}
+
+	// missing aliases are reported next to the indices of the others
+	var indices map[string]interface{}
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return nil, fmt.Errorf("error unmarshalling aliases body: %+v: %+v", err, string(body))
+	}
+	for index, i := range indices {
+		indexAliases, _ := i.(map[string]interface{})
+		aliasesOfIndex, _ := indexAliases["aliases"].(map[string]interface{})
+		for alias, a := range aliasesOfIndex {
+			if properties, ok := a.(map[string]interface{}); ok && properties["is_write_index"] == true {
+				writeIndices[alias] = append(writeIndices[alias], index)
+			}
+		}
+	}
+
+	return writeIndices, nil
+}
+
+// indexSplitTargetName returns the name of the index an index is split into.
+func indexSplitTargetName(name string, shards string) string {
+	return fmt.Sprintf("%s-split-%s", name, shards)
+}
 
+// resourceElasticsearchIndexSplit splits the index into a new index with the
+// new number of shards, then atomically deletes the old index and points an
+// alias with the name of the resource to the new index. Writes are blocked
+// on the old index during the split.
+func resourceElasticsearchIndexSplit(d *schema.ResourceData, meta interface{}) error {
+	var (
+		source    = d.Id()
+		name      = d.Get("name").(string)
+		shards    = d.Get("number_of_shards").(string)
+		target    = indexSplitTargetName(name, shards)
+		ctx       = context.Background()
+		blockBody = map[string]interface{}{"settings": map[string]interface{}{"index.blocks.write": true}}
+		resetBody = map[string]interface{}{"settings": map[string]interface{}{"index.blocks.write": nil}}
+	)
+
+	splitPath, err := uritemplates.Expand("/{source}/_split/{target}", map[string]string{
+		"source": source,
+		"target": target,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for split: %+v", err)
+	}
+	splitBody := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index.number_of_shards": shards,
+		},
+	}
+	aliasesBody := map[string]interface{}{
+		"actions": []map[string]interface{}{
+			{"remove_index": map[string]interface{}{"index": source}},
+			{"add": map[string]interface{}{"index": target, "alias": name}},
+		},
+	}
+
+	log.Printf("[INFO] Splitting index %s into %s with %s shards", source, target, shards)
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		if _, err = client.IndexPutSettings(source).BodyJson(blockBody).Do(ctx); err != nil {
+			break
+		}
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   splitPath,
+			Body:   splitBody,
+		})
+		if err != nil {
+			if _, resetErr := client.IndexPutSettings(source).BodyJson(resetBody).Do(ctx); resetErr != nil {
+				log.Printf("[WARN] Failed to unblock writes on index %s: %+v", source, resetErr)
+			}
+			break
+		}
+		if _, err = client.IndexPutSettings(target).BodyJson(resetBody).Do(ctx); err != nil {
+			break
+		}
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   aliasesBody,
+		})
+	case *elastic6.Client:
+		if _, err = client.IndexPutSettings(source).BodyJson(blockBody).Do(ctx); err != nil {
+			break
+		}
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   splitPath,
+			Body:   splitBody,
+		})
+		if err != nil {
+			if _, resetErr := client.IndexPutSettings(source).BodyJson(resetBody).Do(ctx); resetErr != nil {
+				log.Printf("[WARN] Failed to unblock writes on index %s: %+v", source, resetErr)
+			}
+			break
+		}
+		if _, err = client.IndexPutSettings(target).BodyJson(resetBody).Do(ctx); err != nil {
+			break
+		}
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_aliases",
+			Body:   aliasesBody,
+		})
 	default:
-		elastic5Client := client.(*elastic5.Client)
-		_, err = elastic5Client.IndexPutSettings(name).BodyJson(body).Do(ctx)
+		err = errors.New("splitting indices not implemented prior to Elastic v6")
 	}
 
-	if err == nil {
-		return resourceElasticsearchIndexRead(d, meta.(*ProviderConf))
+	if err != nil {
+		return fmt.Errorf("error splitting index %s into %s: %+v", source, target, err)
 	}
-	return err
+
+	d.SetId(target)
+	return nil
 }
 
 func getWriteIndexByAlias(alias string, d *schema.ResourceData, meta interface{}) string {
@@ -402,6 +1469,8 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 		index    = d.Id()
 		ctx      = context.Background()
 		settings map[string]interface{}
+		mappings map[string]interface{}
+		typed    bool
 	)
 
 	if alias, ok := d.GetOk("rollover_alias"); ok {
@@ -428,6 +1497,7 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 
 		if resp, ok := r[index]; ok {
 			settings = resp.Settings["index"].(map[string]interface{})
+			mappings = resp.Mappings
 		}
 	case *elastic6.Client:
 		r, err := client.IndexGet(index).Do(ctx)
@@ -442,6 +1512,7 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 
 		if resp, ok := r[index]; ok {
 			settings = resp.Settings["index"].(map[string]interface{})
+			mappings, typed = resp.Mappings, true
 		}
 	default:
 		elastic5Client := client.(*elastic5.Client)
@@ -457,11 +1528,13 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 
 		if resp, ok := r[index]; ok {
 			settings = resp.Settings["index"].(map[string]interface{})
+			mappings, typed = resp.Mappings, true
 		}
 	}
 
 	// Don't override name otherwise it will force a replacement
-	if _, ok := d.GetOk("name"); !ok {
+	_, declared := d.GetOk("name")
+	if !declared {
 		name := index
 		if providedName, ok := settings["provided_name"].(string); ok {
 			name = providedName
@@ -491,7 +1564,21 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 		}
 	}
 
-	indexResourceDataFromSettings(settings, d)
+	// Only read back the settings the user declared, to not diff on server
+	// defaults, unless the index is being imported
+	indexResourceDataFromSettings(settings, d, !declared)
+
+	// Read back the meta-fields of the declared mappings, which depend on the
+	// plugins of the cluster, the mappings themselves can't be updated
+	if mappingsJSON, ok := d.GetOk("mappings"); ok && mappings != nil {
+		mappingsWithMetaFields, err := indexMappingsWithMetaFields(mappingsJSON.(string), mappings, typed)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("mappings", mappingsWithMetaFields); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -178,6 +178,9 @@ func resourceElasticsearchPutIndexTemplate(d *schema.ResourceData, meta interfac
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
+		if v, versionErr := elastic7GetVersion(client); versionErr == nil && !v.LessThan(minimalVersion) {
+			log.Printf("[WARN] Legacy index templates are deprecated from Elasticsearch 7.8, please use elasticsearch_composable_index_template for %s instead", name)
+		}
 		err = elastic7IndexPutTemplate(client, name, body, create)
 	case *elastic6.Client:
 		err = elastic6IndexPutTemplate(client, name, body, create)
@@ -2,7 +2,10 @@ package es
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -51,6 +54,57 @@ func TestAccElasticsearchIndexTemplate(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchIndexTemplate_order(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("index_patterns only supported on ES >= 6")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexTemplateOrder(1, `["te*", "bar*"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexTemplateOrder("elasticsearch_index_template.test", 1, []string{"te*", "bar*"}),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexTemplateOrder(2, `["te*", "baz*"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexTemplateOrder("elasticsearch_index_template.test", 2, []string{"te*", "baz*"}),
+				),
+			},
+			{
+				// the default order is omitted by the server
+				Config: testAccElasticsearchIndexTemplateOrder(0, `["te*", "baz*"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexTemplateOrder("elasticsearch_index_template.test", 0, []string{"te*", "baz*"}),
+				),
+			},
+		},
+	})
+}
+
 func TestAccElasticsearchIndexTemplate_importBasic(t *testing.T) {
 	provider := Provider().(*schema.Provider)
 	err := provider.Configure(&terraform.ResourceConfig{})
@@ -126,6 +180,50 @@ func testCheckElasticsearchIndexTemplateExists(name string) resource.TestCheckFu
 	}
 }
 
+func testCheckElasticsearchIndexTemplateOrder(name string, order int, indexPatterns []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+
+		var body string
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			body, err = elastic7IndexGetTemplate(client, rs.Primary.ID)
+		case *elastic6.Client:
+			body, err = elastic6IndexGetTemplate(client, rs.Primary.ID)
+		default:
+			return errors.New("index_patterns only supported on ES >= 6")
+		}
+		if err != nil {
+			return err
+		}
+
+		var template struct {
+			Order         int      `json:"order"`
+			IndexPatterns []string `json:"index_patterns"`
+		}
+		if err := json.Unmarshal([]byte(body), &template); err != nil {
+			return err
+		}
+		if template.Order != order {
+			return fmt.Errorf("expected order %d, got %d", order, template.Order)
+		}
+		if !reflect.DeepEqual(template.IndexPatterns, indexPatterns) {
+			return fmt.Errorf("expected index_patterns %v, got %v", indexPatterns, template.IndexPatterns)
+		}
+
+		return nil
+	}
+}
+
 func testCheckElasticsearchIndexTemplateDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_index_template" {
@@ -252,3 +350,22 @@ resource "elasticsearch_index_template" "test" {
 EOF
 }
 `
+
+func testAccElasticsearchIndexTemplateOrder(order int, indexPatterns string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "order": %d,
+  "index_patterns": %s,
+  "settings": {
+    "index": {
+      "number_of_shards": 1
+    }
+  }
+}
+EOF
+}
+`, order, indexPatterns)
+}
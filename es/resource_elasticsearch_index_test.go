@@ -2,11 +2,20 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -59,6 +68,197 @@ resource "elasticsearch_index" "test" {
   number_of_replicas = 2
   force_destroy = true
 }
+`
+	testAccElasticsearchIndexTotalShardsPerNode = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  routing_allocation_total_shards_per_node = 1
+}
+`
+	testAccElasticsearchIndexTotalShardsPerNodeUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  routing_allocation_total_shards_per_node = 2
+}
+`
+	testAccElasticsearchIndexAliasFilter = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = <<EOF
+{
+  "terraform-test-alias": {
+    "routing": "1",
+    "filter": {
+      "bool": {
+        "filter": [
+          {"term": {"user": "kimchy"}},
+          {"range": {"age": {"gte": 18}}}
+        ]
+      }
+    }
+  }
+}
+EOF
+}
+`
+	testAccElasticsearchIndexAliasFilterReordered = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = <<EOF
+{
+  "terraform-test-alias": {
+    "filter": {
+      "bool": {
+        "filter": [
+          {"range": {"age": {"gte": 18}}},
+          {"term": {"user": "kimchy"}}
+        ]
+      }
+    },
+    "index_routing": "1",
+    "search_routing": "1"
+  }
+}
+EOF
+}
+`
+	testAccElasticsearchIndexTwoShards = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 2
+  number_of_replicas = 1
+}
+`
+	testAccElasticsearchIndexSplit = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  allow_split_on_shard_increase = true
+}
+`
+	testAccElasticsearchIndexSplitTwoShards = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 2
+  number_of_replicas = 1
+  allow_split_on_shard_increase = true
+}
+`
+	testAccElasticsearchIndexRefreshDisabled = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  refresh_interval = -1
+}
+`
+	testAccElasticsearchIndexRefreshEnabled = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  refresh_interval = "1s"
+}
+`
+	testAccElasticsearchIndexRefreshEnabledMillis = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  refresh_interval = "1000ms"
+}
+`
+	testAccElasticsearchIndexDefaultReplicas = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+}
+`
+	testAccElasticsearchIndexOriginationDate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  lifecycle_origination_date = "1577836800000"
+}
+`
+	testAccElasticsearchIndexOriginationDateUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  lifecycle_origination_date = "1609459200000"
+}
+`
+	testAccElasticsearchIndexIgnoreMalformed = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mapping_ignore_malformed = true
+  mapping_coerce = false
+}
+`
+	testAccElasticsearchIndexMergePolicy = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  merge_policy_max_merged_segment = "2gb"
+  merge_policy_segments_per_tier = "20.0"
+}
+`
+	testAccElasticsearchIndexMergePolicyUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  merge_policy_max_merged_segment = "1gb"
+  merge_policy_segments_per_tier = "20.0"
+}
+`
+	testAccElasticsearchIndexHighlight = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  analyze_max_token_count = 20000
+  highlight_max_analyzed_offset = 5000000
+}
+`
+	testAccElasticsearchIndexHighlightUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  analyze_max_token_count = 20000
+  highlight_max_analyzed_offset = 6000000
+}
+`
+	testAccElasticsearchIndexBlocks = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 0
+  blocks_read_only = true
+  blocks_metadata = true
+}
+`
+	testAccElasticsearchIndexBlocksCleared = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+}
 `
 	testAccElasticsearchIndexDateMath = `
 resource "elasticsearch_index" "test_date_math" {
@@ -177,31 +377,878 @@ resource "elasticsearch_index" "test" {
 
   depends_on = [elasticsearch_index_template.test]
 }
-`
-)
+`
+)
+
+func TestAccElasticsearchIndex(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndex,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexUpdate1,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexUpdateForceDestroy,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_totalShardsPerNode(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexTotalShardsPerNode,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "routing_allocation_total_shards_per_node", "1"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "routing.allocation.total_shards_per_node", "1"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexTotalShardsPerNodeUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "routing_allocation_total_shards_per_node", "2"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "routing.allocation.total_shards_per_node", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_aliasFilter(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexAliasFilter,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				// reordering the clauses of the filter must not replace the index
+				Config:             testAccElasticsearchIndexAliasFilterReordered,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func TestIndexResourceDataFromMixedSettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":               "terraform-test",
+		"number_of_replicas": "1",
+		"refresh_interval":   "1s",
+		"routing_allocation_total_shards_per_node": 1,
+	})
+
+	indexResourceDataFromSettings(map[string]interface{}{
+		"index.number_of_replicas": "2",
+		"refresh_interval":         "5s",
+		"index": map[string]interface{}{
+			"routing": map[string]interface{}{
+				"allocation.total_shards_per_node": "3",
+			},
+		},
+	}, d, false)
+
+	if v := d.Get("number_of_replicas"); v != "2" {
+		t.Errorf("expected number_of_replicas to be 2, got %v", v)
+	}
+	if v := d.Get("refresh_interval"); v != "5s" {
+		t.Errorf("expected refresh_interval to be 5s, got %v", v)
+	}
+	if v := d.Get("routing_allocation_total_shards_per_node"); v != 3 {
+		t.Errorf("expected routing_allocation_total_shards_per_node to be 3, got %v", v)
+	}
+}
+
+func TestIndexResourceDataFromMergePolicySettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":                            "terraform-test",
+		"merge_policy_max_merged_segment": "2gb",
+		"merge_policy_max_merge_at_once":  5,
+	})
+
+	settings := settingsFromIndexResourceData(d)
+	if v := settings["merge.policy.max_merged_segment"]; v != "2gb" {
+		t.Errorf("expected merge.policy.max_merged_segment to be 2gb, got %v", v)
+	}
+	if v := settings["merge.policy.max_merge_at_once"]; v != 5 {
+		t.Errorf("expected merge.policy.max_merge_at_once to be 5, got %v", v)
+	}
+
+	indexResourceDataFromSettings(map[string]interface{}{
+		"index": map[string]interface{}{
+			"merge": map[string]interface{}{
+				"policy": map[string]interface{}{
+					"max_merged_segment": "1gb",
+					"max_merge_at_once":  "5",
+				},
+			},
+		},
+	}, d, false)
+
+	if v := d.Get("merge_policy_max_merged_segment"); v != "1gb" {
+		t.Errorf("expected the changed merge_policy_max_merged_segment to be read back as 1gb, got %v", v)
+	}
+	if v := d.Get("merge_policy_max_merge_at_once"); v != 5 {
+		t.Errorf("expected merge_policy_max_merge_at_once to be 5, got %v", v)
+	}
+}
+
+func TestIndexResourceDataFromHighlightSettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":                          "terraform-test",
+		"highlight_max_analyzed_offset": 5000000,
+		"analyze_max_token_count":       20000,
+	})
+
+	settings := settingsFromIndexResourceData(d)
+	if v := settings["highlight.max_analyzed_offset"]; v != 5000000 {
+		t.Errorf("expected highlight.max_analyzed_offset to be 5000000, got %v", v)
+	}
+	if v := settings["analyze.max_token_count"]; v != 20000 {
+		t.Errorf("expected analyze.max_token_count to be 20000, got %v", v)
+	}
+
+	// the settings API returns the values as strings
+	indexResourceDataFromSettings(map[string]interface{}{
+		"index": map[string]interface{}{
+			"highlight": map[string]interface{}{
+				"max_analyzed_offset": "6000000",
+			},
+			"analyze": map[string]interface{}{
+				"max_token_count": "20000",
+			},
+		},
+	}, d, false)
+
+	if v := d.Get("highlight_max_analyzed_offset"); v != 6000000 {
+		t.Errorf("expected the changed highlight_max_analyzed_offset to be read back as 6000000, got %v", v)
+	}
+	if v := d.Get("analyze_max_token_count"); v != 20000 {
+		t.Errorf("expected analyze_max_token_count to be 20000, got %v", v)
+	}
+}
+
+func TestElasticsearchIndexUpdateSettingsBlocks(t *testing.T) {
+	var puts []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "PUT" || r.URL.Path != "/logs/_settings" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		var body map[string]map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("err: %s", err)
+		}
+		puts = append(puts, body["settings"])
+		fmt.Fprint(w, `{"acknowledged": true}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	update := func(attributes map[string]string, changes map[string]*terraform.ResourceAttrDiff) error {
+		attributes["name"] = "logs"
+		state := &terraform.InstanceState{ID: "logs", Attributes: attributes}
+		resourceData, err := schema.InternalMap(configSchema).Data(state, &terraform.InstanceDiff{Attributes: changes})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return resourceElasticsearchIndexUpdateSettings(resourceData, meta)
+	}
+
+	// changing other settings while the block stays set is refused upfront
+	err := update(map[string]string{
+		"blocks_read_only":   "true",
+		"number_of_replicas": "0",
+	}, map[string]*terraform.ResourceAttrDiff{
+		"number_of_replicas": {Old: "0", New: "1"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "clear the block by removing or disabling `blocks_read_only` first") {
+		t.Errorf("expected an error suggesting to clear the block, got %v", err)
+	}
+	if len(puts) != 0 {
+		t.Errorf("expected no settings to be put, got %v", puts)
+	}
+
+	// clearing the block in the same update puts it first
+	err = update(map[string]string{
+		"blocks_read_only":   "true",
+		"number_of_replicas": "0",
+	}, map[string]*terraform.ResourceAttrDiff{
+		"blocks_read_only":   {Old: "true", New: "", NewRemoved: true},
+		"number_of_replicas": {Old: "0", New: "1"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []map[string]interface{}{
+		{"blocks.read_only": nil},
+		{"number_of_replicas": "1"},
+	}
+	if !reflect.DeepEqual(puts, expected) {
+		t.Errorf("expected the block to be cleared first, got %v", puts)
+	}
+
+	// a disabled block is reset like a removed one
+	puts = nil
+	err = update(map[string]string{
+		"blocks_metadata": "true",
+	}, map[string]*terraform.ResourceAttrDiff{
+		"blocks_metadata": {Old: "true", New: "false"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected = []map[string]interface{}{
+		{"blocks.metadata": nil},
+	}
+	if !reflect.DeepEqual(puts, expected) {
+		t.Errorf("expected the block to be reset, got %v", puts)
+	}
+
+	// setting the block in the same update puts it last
+	puts = nil
+	err = update(map[string]string{
+		"number_of_replicas": "1",
+	}, map[string]*terraform.ResourceAttrDiff{
+		"blocks_metadata":    {Old: "", New: "true"},
+		"number_of_replicas": {Old: "1", New: "0"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected = []map[string]interface{}{
+		{"number_of_replicas": "0"},
+		{"blocks.metadata": true},
+	}
+	if !reflect.DeepEqual(puts, expected) {
+		t.Errorf("expected the block to be set last, got %v", puts)
+	}
+}
+
+func TestIndexResourceDataFromMappingSettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":                     "terraform-test",
+		"mapping_ignore_malformed": true,
+		"mapping_coerce":           false,
+	})
+
+	settings := settingsFromIndexResourceData(d)
+	if v := settings["mapping.ignore_malformed"]; v != true {
+		t.Errorf("expected mapping.ignore_malformed to be true, got %v", v)
+	}
+	if v, ok := settings["mapping.coerce"]; !ok || v != false {
+		t.Errorf("expected mapping.coerce to be false, got %v", v)
+	}
+
+	indexResourceDataFromSettings(map[string]interface{}{
+		"mapping": map[string]interface{}{
+			"ignore_malformed": "true",
+			"coerce":           "true",
+		},
+	}, d, false)
+
+	if v := d.Get("mapping_ignore_malformed"); v != true {
+		t.Errorf("expected mapping_ignore_malformed to be true, got %v", v)
+	}
+	if v := d.Get("mapping_coerce"); v != true {
+		t.Errorf("expected the changed mapping_coerce to be read back as true, got %v", v)
+	}
+
+	// the server doesn't report the defaults of settings which weren't set
+	undeclared := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name": "terraform-test",
+	})
+	if _, ok := settingsFromIndexResourceData(undeclared)["mapping.coerce"]; ok {
+		t.Error("expected an unset mapping.coerce not to be sent")
+	}
+}
+
+func TestDiffSuppressIndexTemplateMixedSettings(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			`{"settings": {"index": {"number_of_shards": "1", "number_of_replicas": "1"}}}`,
+			`{"settings": {"index.number_of_shards": 1, "number_of_replicas": 1}}`,
+			true,
+		},
+		{
+			`{"settings": {"index.refresh_interval": "5s"}}`,
+			`{"settings": {"refresh_interval": "5s"}}`,
+			true,
+		},
+		{
+			`{"settings": {"index.number_of_replicas": "1"}}`,
+			`{"settings": {"number_of_replicas": 2}}`,
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		if actual := diffSuppressIndexTemplate("body", tc.old, tc.new, nil); actual != tc.suppress {
+			t.Errorf("case %d: expected suppress to be %t, got %t", i, tc.suppress, actual)
+		}
+	}
+}
+
+func TestDiffSuppressIndexAliases(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			`{"a": {"filter": {"bool": {"must": [{"term": {"x": 1}}, {"term": {"y": 2}}]}}}}`,
+			`{"a": {"filter": {"bool": {"must": [{"term": {"y": 2}}, {"term": {"x": 1}}]}}}}`,
+			true,
+		},
+		{
+			`{"a": {"filter": {"bool": {"filter": {"term": {"x": 1}}}}}}`,
+			`{"a": {"filter": {"bool": {"filter": [{"term": {"x": 1}}]}}}}`,
+			true,
+		},
+		{
+			`{"a": {"routing": "1"}}`,
+			`{"a": {"index_routing": "1", "search_routing": "1"}}`,
+			true,
+		},
+		{
+			`{"a": {"filter": {"bool": {"must": [{"term": {"x": 1}}]}}}}`,
+			`{"a": {"filter": {"bool": {"should": [{"term": {"x": 1}}]}}}}`,
+			false,
+		},
+		{
+			`{"a": {"filter": {"term": {"x": 1}}}}`,
+			`{"a": {"filter": {"term": {"x": 2}}}}`,
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		if actual := diffSuppressIndexAliases("aliases", tc.old, tc.new, nil); actual != tc.suppress {
+			t.Errorf("case %d: expected suppress to be %t, got %t", i, tc.suppress, actual)
+		}
+	}
+}
+
+func TestAccElasticsearchIndex_shardsReplacement(t *testing.T) {
+	var uuid string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndex,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
+				),
+			},
+			{
+				// replicas are updated in place
+				Config: testAccElasticsearchIndexUpdate1,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
+				),
+			},
+			{
+				// shards can only be changed by replacing the index
+				Config: testAccElasticsearchIndexTwoShards,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "number_of_shards", "2"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_splitOnShardIncrease(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+
+	var uuid string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Splitting indices only supported on ES >= 6")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexSplit,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
+				),
+			},
+			{
+				// the index is split and replaced by an alias instead of
+				// being recreated
+				Config: testAccElasticsearchIndexSplitTwoShards,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "id", "terraform-test-split-2"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "number_of_shards", "2"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+				),
+			},
+		},
+	})
+}
+
+func TestIndexShardsSplittable(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		expected bool
+	}{
+		{"1", "2", true},
+		{"2", "6", true},
+		{"2", "3", false},
+		{"4", "2", false},
+		{"2", "2", false},
+		{"", "2", false},
+	}
+
+	for _, c := range cases {
+		if actual := indexShardsSplittable(c.old, c.new); actual != c.expected {
+			t.Errorf("indexShardsSplittable(%q, %q) = %t, expected %t", c.old, c.new, actual, c.expected)
+		}
+	}
+}
+
+func TestIndexMappingLimitWarnings(t *testing.T) {
+	nested := func(n int) string {
+		fields := make([]string, n)
+		for i := range fields {
+			fields[i] = fmt.Sprintf(`"field%d": {"type": "nested", "properties": {"name": {"type": "keyword"}}}`, i)
+		}
+		return `{"properties": {` + strings.Join(fields, ", ") + `}}`
+	}
+
+	if warnings := indexMappingLimitWarnings(nested(3), defaultMappingDepthLimit, 3); len(warnings) != 0 {
+		t.Errorf("expected 3 nested fields to be within the limit, got %v", warnings)
+	}
+	warnings := indexMappingLimitWarnings(nested(4), defaultMappingDepthLimit, 3)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "4 nested fields, exceeding mapping.nested_fields.limit of 3") {
+		t.Errorf("expected a warning about the nested fields, got %v", warnings)
+	}
+	// mappings of Elasticsearch 6 are wrapped by their type
+	if warnings := indexMappingLimitWarnings(`{"_doc": `+nested(51)+`}`, defaultMappingDepthLimit, defaultMappingNestedFieldsLimit); len(warnings) != 1 {
+		t.Errorf("expected a warning about the nested fields of the typed mapping, got %v", warnings)
+	}
+
+	deep := `{"properties": {"a": {"properties": {"b": {"properties": {"c": {"type": "keyword"}}}}}, "d": {"type": "keyword"}}}`
+	if depth, nestedFields := indexMappingDepthAndNestedFields(mustUnmarshal(t, deep)); depth != 3 || nestedFields != 0 {
+		t.Errorf("expected a depth of 3 without nested fields, got %d and %d", depth, nestedFields)
+	}
+	warnings = indexMappingLimitWarnings(deep, 2, defaultMappingNestedFieldsLimit)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "depth of 3, exceeding mapping.depth.limit of 2") {
+		t.Errorf("expected a warning about the depth, got %v", warnings)
+	}
+
+	if warnings := indexMappingLimitWarnings("", 1, 1); len(warnings) != 0 {
+		t.Errorf("expected unknown mappings not to be checked, got %v", warnings)
+	}
+
+	// the default limits are checked by the validation, whose warnings are
+	// shown when planning
+	warnings, errs := validateIndexMappings(nested(51), "mappings")
+	if len(errs) != 0 || len(warnings) != 1 || !strings.Contains(warnings[0], "mappings: the mappings have 51 nested fields, exceeding mapping.nested_fields.limit of 50 by default") {
+		t.Errorf("expected a validation warning about the nested fields, got %v and %v", warnings, errs)
+	}
+	if warnings, errs := validateIndexMappings(nested(50), "mappings"); len(errs) != 0 || len(warnings) != 0 {
+		t.Errorf("expected 50 nested fields to be valid without warnings, got %v and %v", warnings, errs)
+	}
+	if _, errs := validateIndexMappings("{", "mappings"); len(errs) == 0 {
+		t.Error("expected invalid JSON to be rejected")
+	}
+}
+
+func mustUnmarshal(t *testing.T, s string) map[string]interface{} {
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return v
+}
+
+func TestElasticsearchIndexSplit(t *testing.T) {
+	var requests []string
+	var aliases map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/_aliases" {
+			if err := json.NewDecoder(r.Body).Decode(&aliases); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"acknowledged": true}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":                          "terraform-test",
+		"number_of_shards":              "2",
+		"allow_split_on_shard_increase": true,
+	})
+	resourceData.SetId("terraform-test")
+	if err := resourceElasticsearchIndexSplit(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"PUT /terraform-test/_settings",
+		"POST /terraform-test/_split/terraform-test-split-2",
+		"PUT /terraform-test-split-2/_settings",
+		"POST /_aliases",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+	expectedActions := `[map[remove_index:map[index:terraform-test]] map[add:map[alias:terraform-test index:terraform-test-split-2]]]`
+	if actions := fmt.Sprint(aliases["actions"]); actions != expectedActions {
+		t.Errorf("expected alias actions %s, got %s", expectedActions, actions)
+	}
+	if resourceData.Id() != "terraform-test-split-2" {
+		t.Errorf("expected the ID to be the new index, got %s", resourceData.Id())
+	}
+}
+
+func TestAccElasticsearchIndex_refreshInterval(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				// disable refreshes, e.g. for a bulk load
+				Config: testAccElasticsearchIndexRefreshDisabled,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "refresh_interval", "-1"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexRefreshEnabled,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "refresh_interval", "1s"),
+				),
+			},
+			{
+				Config:   testAccElasticsearchIndexRefreshEnabledMillis,
+				PlanOnly: true,
+			},
+			{
+				Config: testAccElasticsearchIndexRefreshDisabled,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "refresh_interval", "-1"),
+				),
+			},
+		},
+	})
+}
+
+func TestCanonicalRefreshInterval(t *testing.T) {
+	cases := map[string]string{
+		"-1":     "-1",
+		"-1ms":   "-1",
+		"0":      "0s",
+		"1s":     "1s",
+		"1000ms": "1s",
+		"30s":    "30s",
+		"1m":     "1m0s",
+		"60s":    "1m0s",
+		"1d":     "24h0m0s",
+		"500ms":  "500ms",
+		"soon":   "soon",
+		"10":     "10",
+	}
+
+	for interval, expected := range cases {
+		if actual := canonicalTimeValue(interval); actual != expected {
+			t.Errorf("canonicalTimeValue(%q) = %q, expected %q", interval, actual, expected)
+		}
+	}
+
+	if !diffSuppressIndexTemplate("body", `{"settings": {"index.refresh_interval": "-1"}}`, `{"settings": {"index": {"refresh_interval": -1}}}`, nil) {
+		t.Error("expected -1 and \"-1\" to be the same refresh interval of templates")
+	}
+}
+
+func TestCanonicalByteSize(t *testing.T) {
+	cases := map[string]string{
+		"-1":     "-1",
+		"0":      "0b",
+		"512b":   "512b",
+		"2mb":    "2097152b",
+		"2048kb": "2097152b",
+		"2MB":    "2097152b",
+		"0.5gb":  "536870912b",
+		"5gb":    "5368709120b",
+		"big":    "big",
+		"10":     "10",
+	}
+
+	for size, expected := range cases {
+		if actual := canonicalByteSize(size); actual != expected {
+			t.Errorf("canonicalByteSize(%q) = %q, expected %q", size, actual, expected)
+		}
+	}
+
+	if !diffSuppressIndexTemplate("body", `{"settings": {"index.merge.policy.floor_segment": "2048kb", "index.translog.sync_interval": "5000ms"}}`, `{"settings": {"index": {"merge": {"policy": {"floor_segment": "2mb"}}, "translog": {"sync_interval": "5s"}}}}`, nil) {
+		t.Error("expected equivalent byte size and time settings of templates to be the same")
+	}
+}
+
+func TestElasticsearchIndexSettingUnitsDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "terraform-test",
+		Attributes: map[string]string{
+			"id":                         "terraform-test",
+			"name":                       "terraform-test",
+			"number_of_shards":           "1",
+			"refresh_interval":           "1000ms",
+			"merge_policy_floor_segment": "2048kb",
+		},
+	}
+
+	cases := []struct {
+		refreshInterval string
+		floorSegment    string
+		diff            bool
+	}{
+		{"1s", "2mb", false},
+		{"1000ms", "2MB", false},
+		{"2s", "2mb", true},
+		{"1s", "4mb", true},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":                       "terraform-test",
+			"refresh_interval":           c.refreshInterval,
+			"merge_policy_floor_segment": c.floorSegment,
+		})
+		diff, err := resourceElasticsearchIndex().Diff(state, config, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		hasDiff := diff != nil && (diff.Attributes["refresh_interval"] != nil || diff.Attributes["merge_policy_floor_segment"] != nil)
+		if hasDiff != c.diff {
+			t.Errorf("%s, %s: expected a diff to be %t, got %+v", c.refreshInterval, c.floorSegment, c.diff, diff)
+		}
+	}
+}
+
+func TestAccElasticsearchIndex_undeclaredSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the default number_of_replicas set by the server must not
+				// cause a diff
+				Config: testAccElasticsearchIndexDefaultReplicas,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_ignoreMalformed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexIgnoreMalformed,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mapping_ignore_malformed", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mapping_coerce", "false"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "mapping.ignore_malformed", "true"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "mapping.coerce", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_mergePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMergePolicy,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "merge_policy_max_merged_segment", "2gb"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "merge.policy.max_merged_segment", "2gb"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "merge.policy.segments_per_tier", "20.0"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexMergePolicyUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "merge_policy_max_merged_segment", "1gb"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "merge.policy.max_merged_segment", "1gb"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_highlight(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexHighlight,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "analyze.max_token_count", "20000"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "highlight.max_analyzed_offset", "5000000"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexHighlightUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "highlight_max_analyzed_offset", "6000000"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "highlight.max_analyzed_offset", "6000000"),
+				),
+			},
+		},
+	})
+}
 
-func TestAccElasticsearchIndex(t *testing.T) {
+func TestAccElasticsearchIndex_blocks(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
 		CheckDestroy: checkElasticsearchIndexDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchIndex,
+				Config: testAccElasticsearchIndexBlocks,
 				Check: resource.ComposeTestCheckFunc(
-					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read_only", "true"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "blocks.read_only", "true"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "blocks.metadata", "true"),
 				),
 			},
 			{
-				Config: testAccElasticsearchIndexUpdate1,
+				// the blocks are cleared before the replicas are changed
+				Config: testAccElasticsearchIndexBlocksCleared,
 				Check: resource.ComposeTestCheckFunc(
-					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", "1"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "number_of_replicas", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_originationDate(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		v, err := elastic7GetVersion(client)
+		allowed = err == nil && !v.LessThan(version.Must(version.NewVersion("7.8.0")))
+	default:
+		allowed = false
+	}
+
+	var uuid string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("lifecycle.origination_date only supported on ES >= 7.8")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexOriginationDate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "lifecycle_origination_date", "1577836800000"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "lifecycle.origination_date", "1577836800000"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
 				),
 			},
 			{
-				Config: testAccElasticsearchIndexUpdateForceDestroy,
+				Config: testAccElasticsearchIndexOriginationDateUpdate,
 				Check: resource.ComposeTestCheckFunc(
-					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "lifecycle_origination_date", "1609459200000"),
+					checkElasticsearchIndexSetting("elasticsearch_index.test", "lifecycle.origination_date", "1609459200000"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
 				),
 			},
 		},
@@ -471,6 +1518,110 @@ func checkElasticsearchIndexUpdated(name string) resource.TestCheckFunc {
 	}
 }
 
+func checkElasticsearchIndexSetting(name, key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+		var settings map[string]interface{}
+
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			settings = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})
+
+		case *elastic6.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			settings = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})
+
+		default:
+			elastic5Client := client.(*elastic5.Client)
+			resp, err := elastic5Client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			settings = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})
+		}
+
+		v, ok := flattenMap(settings)[key]
+		if !ok {
+			return fmt.Errorf("setting %s not found", key)
+		}
+		if fmt.Sprint(v) != expected {
+			return fmt.Errorf("expected %s to be %s, got %v", key, expected, v)
+		}
+		return nil
+	}
+}
+
+// checkElasticsearchIndexUUID records the uuid of the index, checking whether
+// it changed since it was last recorded.
+func checkElasticsearchIndexUUID(name string, uuid *string, changed bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		var current string
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			current, _ = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})["uuid"].(string)
+
+		case *elastic6.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			current, _ = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})["uuid"].(string)
+
+		default:
+			elastic5Client := client.(*elastic5.Client)
+			resp, err := elastic5Client.IndexGetSettings(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			current, _ = resp[rs.Primary.ID].Settings["index"].(map[string]interface{})["uuid"].(string)
+		}
+
+		previous := *uuid
+		*uuid = current
+		if previous == "" {
+			return nil
+		}
+		if changed && previous == current {
+			return fmt.Errorf("expected index %s to be replaced", rs.Primary.ID)
+		}
+		if !changed && previous != current {
+			return fmt.Errorf("expected index %s to be updated in place, but it was replaced", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
 func checkElasticsearchIndexDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_index" {
@@ -595,3 +1746,430 @@ func checkElasticsearchIndexRolloverAliasDestroy(provider *schema.Provider, alia
 		return nil
 	}
 }
+
+func TestElasticsearchIndexCheckShardLimit(t *testing.T) {
+	// a cluster of 2 data nodes with 20 shards per node, 36 of 40 shards used
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_cluster/settings":
+			if r.URL.Query().Get("include_defaults") != "true" {
+				t.Errorf("expected the defaults to be included")
+			}
+			fmt.Fprint(w, `{"persistent": {"cluster.max_shards_per_node": "20"}, "transient": {}, "defaults": {"cluster.max_shards_per_node": "1000"}}`)
+		case "/_cluster/health":
+			fmt.Fprint(w, `{"cluster_name": "test", "status": "yellow", "number_of_nodes": 3, "number_of_data_nodes": 2, "active_shards": 30, "initializing_shards": 2, "unassigned_shards": 4}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	cases := []struct {
+		shards      string
+		replicas    string
+		fail        bool
+		expectedErr string
+	}{
+		{"2", "1", true, ""},
+		{"4", "0", true, ""},
+		{"3", "1", true, "index terraform-test requires 6 shards, but only 4 of the 40 shards of the cluster are left"},
+		{"5", "", true, "requires 10 shards"},
+		{"3", "1", false, ""},
+	}
+	for _, c := range cases {
+		config := map[string]interface{}{
+			"name":              "terraform-test",
+			"number_of_shards":  c.shards,
+			"shard_limit_check": "error",
+		}
+		if c.replicas != "" {
+			config["number_of_replicas"] = c.replicas
+		}
+		resourceData := schema.TestResourceDataRaw(t, configSchema, config)
+
+		err := resourceElasticsearchIndexCheckShardLimit(resourceData, meta, c.fail)
+		if c.expectedErr == "" && err != nil {
+			t.Errorf("%s*(1+%s): expected no error, got %s", c.shards, c.replicas, err)
+		}
+		if c.expectedErr != "" && (err == nil || !regexp.MustCompile(regexp.QuoteMeta(c.expectedErr)).MatchString(err.Error())) {
+			t.Errorf("%s*(1+%s): expected error containing %q, got %v", c.shards, c.replicas, c.expectedErr, err)
+		}
+	}
+}
+
+func TestElasticsearchIndexCreateClusterBlocked(t *testing.T) {
+	creates := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/terraform-test":
+			creates++
+			if creates == 1 {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"error": {"type": "cluster_block_exception", "reason": "blocked by: [FORBIDDEN/6/cluster read-only (api)];"}, "status": 403}`)
+				return
+			}
+			fmt.Fprint(w, `{"acknowledged": true, "shards_acknowledged": true, "index": "terraform-test"}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test":
+			fmt.Fprint(w, `{"terraform-test": {"aliases": {}, "mappings": {}, "settings": {"index": {"number_of_shards": "1", "provided_name": "terraform-test"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":             "terraform-test",
+		"number_of_shards": "1",
+	})
+	if err := resourceElasticsearchIndexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if creates != 2 {
+		t.Errorf("expected the create to be retried once, got %d attempts", creates)
+	}
+	if resourceData.Id() != "terraform-test" {
+		t.Errorf("expected the ID to be terraform-test, got %s", resourceData.Id())
+	}
+}
+
+func TestElasticsearchIndexCreateTimeouts(t *testing.T) {
+	var query map[string][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/terraform-test":
+			query = r.URL.Query()
+			fmt.Fprint(w, `{"acknowledged": true, "shards_acknowledged": true, "index": "terraform-test"}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test":
+			fmt.Fprint(w, `{"terraform-test": {"aliases": {}, "mappings": {}, "settings": {"index": {"number_of_shards": "1", "provided_name": "terraform-test"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":           "terraform-test",
+		"timeout":        "90s",
+		"master_timeout": "2m",
+	})
+	if err := resourceElasticsearchIndexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string][]string{"timeout": {"90s"}, "master_timeout": {"2m"}}
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("expected the query %v, got %v", expected, query)
+	}
+
+	// the parameters are only sent when set
+	resourceData = schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name": "terraform-test",
+	})
+	if err := resourceElasticsearchIndexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(query) != 0 {
+		t.Errorf("expected no query parameters, got %v", query)
+	}
+}
+
+func TestRetryWhileClusterBlocked(t *testing.T) {
+	blocked := &elastic7.Error{Status: 403, Details: &elastic7.ErrorDetails{Type: "cluster_block_exception"}}
+
+	attempts := 0
+	err := retryWhileClusterBlocked(10*time.Second, func() error {
+		attempts++
+		return errors.New("bad request")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("expected other errors to fail without retrying, got %d attempts and error %v", attempts, err)
+	}
+
+	err = retryWhileClusterBlocked(time.Second, func() error {
+		return blocked
+	})
+	if err == nil || !strings.Contains(err.Error(), "cluster block was not lifted") {
+		t.Errorf("expected the cluster block to not be lifted, got %v", err)
+	}
+}
+
+func TestIndexAliasActions(t *testing.T) {
+	write := map[string]interface{}{"logs": map[string]interface{}{"is_write_index": true}}
+	read := map[string]interface{}{"logs": map[string]interface{}{"is_write_index": false}}
+
+	cases := []struct {
+		name         string
+		old          map[string]interface{}
+		new          map[string]interface{}
+		writeIndices map[string][]string
+		expected     []map[string]interface{}
+	}{
+		{
+			"take over the write index",
+			read, write,
+			map[string][]string{"logs": {"logs-000001"}},
+			[]map[string]interface{}{
+				{"add": map[string]interface{}{"index": "logs-000001", "alias": "logs", "is_write_index": false}},
+				{"add": map[string]interface{}{"index": "logs-000002", "alias": "logs", "is_write_index": true}},
+			},
+		},
+		{
+			"hand over to an index which already took over",
+			write, read,
+			map[string][]string{"logs": {"logs-000001"}},
+			[]map[string]interface{}{
+				{"add": map[string]interface{}{"index": "logs-000002", "alias": "logs", "is_write_index": false}},
+			},
+		},
+		{
+			"keep the only write index",
+			write, read,
+			map[string][]string{"logs": {"logs-000002"}},
+			[]map[string]interface{}{
+				{"add": map[string]interface{}{"index": "logs-000002", "alias": "logs", "is_write_index": true}},
+			},
+		},
+		{
+			"remove an alias",
+			read, map[string]interface{}{},
+			map[string][]string{},
+			[]map[string]interface{}{
+				{"remove": map[string]interface{}{"index": "logs-000002", "alias": "logs"}},
+			},
+		},
+		{
+			"unchanged",
+			write, write,
+			map[string][]string{"logs": {"logs-000002"}},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		actual := indexAliasActions("logs-000002", c.old, c.new, c.writeIndices)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected actions %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestElasticsearchIndexUpdateAliasesWriteIndex(t *testing.T) {
+	var requests []string
+	var actions interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_alias/logs":
+			fmt.Fprint(w, `{"logs-000001": {"aliases": {"logs": {"is_write_index": true}}}}`)
+		case r.Method == "POST" && r.URL.Path == "/_aliases":
+			body, _ := ioutil.ReadAll(r.Body)
+			var aliases map[string]interface{}
+			if err := json.Unmarshal(body, &aliases); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			actions = aliases["actions"]
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":    "logs-000002",
+		"aliases": `{"logs": {"is_write_index": true}}`,
+	})
+	resourceData.SetId("logs-000002")
+	if err := resourceElasticsearchIndexUpdateAliases(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// both indices change in a single request
+	if !reflect.DeepEqual(requests, []string{"GET /_alias/logs", "POST /_aliases"}) {
+		t.Errorf("expected the aliases to be updated in a single request, got %v", requests)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"add": map[string]interface{}{"index": "logs-000001", "alias": "logs", "is_write_index": false}},
+		map[string]interface{}{"add": map[string]interface{}{"index": "logs-000002", "alias": "logs", "is_write_index": true}},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected actions %v, got %v", expected, actions)
+	}
+}
+
+func TestElasticsearchIndexCreateSizeMetaField(t *testing.T) {
+	var created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/terraform-test":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"acknowledged": true, "shards_acknowledged": true, "index": "terraform-test"}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test":
+			fmt.Fprint(w, `{"terraform-test": {"aliases": {}, "mappings": {"_size": {"enabled": true}, "properties": {"message": {"type": "text"}}}, "settings": {"index": {"number_of_shards": "1", "provided_name": "terraform-test"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	mappings := `{"_size": {"enabled": true}, "properties": {"message": {"type": "text"}}}`
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":             "terraform-test",
+		"number_of_shards": "1",
+		"mappings":         mappings,
+	})
+	if err := resourceElasticsearchIndexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{"enabled": true}
+	if size := created["mappings"].(map[string]interface{})["_size"]; !reflect.DeepEqual(size, expected) {
+		t.Errorf("expected _size to be created with %v, got %v", expected, size)
+	}
+	if m := resourceData.Get("mappings").(string); m != mappings {
+		t.Errorf("expected the mappings to round-trip, got %s", m)
+	}
+}
+
+func TestElasticsearchIndexCreateWaitForStatus(t *testing.T) {
+	var healthRequests []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/terraform-test":
+			fmt.Fprint(w, `{"acknowledged": true, "shards_acknowledged": true, "index": "terraform-test"}`)
+		case r.Method == "GET" && r.URL.Path == "/_cluster/health/terraform-test":
+			healthRequests = append(healthRequests, r.URL.Query())
+			// the replicas are allocated while waiting for the second time
+			if len(healthRequests) == 1 {
+				w.WriteHeader(http.StatusRequestTimeout)
+				fmt.Fprint(w, `{"cluster_name": "test", "status": "yellow", "timed_out": true}`)
+				return
+			}
+			fmt.Fprint(w, `{"cluster_name": "test", "status": "green", "timed_out": false}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test":
+			fmt.Fprint(w, `{"terraform-test": {"aliases": {}, "mappings": {}, "settings": {"index": {"number_of_shards": "1", "number_of_replicas": "1", "provided_name": "terraform-test"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":                    "terraform-test",
+		"number_of_shards":        "1",
+		"number_of_replicas":      "1",
+		"wait_for_status":         "green",
+		"wait_for_status_timeout": "1m",
+	})
+	if err := resourceElasticsearchIndexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(healthRequests) != 2 {
+		t.Fatalf("expected the health of the index to be polled until it is green, got %d requests", len(healthRequests))
+	}
+	for _, params := range healthRequests {
+		if params.Get("wait_for_status") != "green" || params.Get("timeout") != "10000ms" {
+			t.Errorf("expected to wait for green for at most 10s per request, got %v", params)
+		}
+	}
+	if resourceData.Id() != "terraform-test" {
+		t.Errorf("expected the index to be created, got ID %q", resourceData.Id())
+	}
+}
+
+func TestElasticsearchIndexCreateSizeMetaFieldWithoutPlugin(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" && r.URL.Path == "/terraform-test" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": {"type": "mapper_parsing_exception", "reason": "Failed to parse mapping [_doc]: Root mapping definition has unsupported parameters:  [_size : {enabled=true}]"}, "status": 400}`)
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, configSchema, map[string]interface{}{
+		"name":             "terraform-test",
+		"number_of_shards": "1",
+		"mappings":         `{"_size": {"enabled": true}}`,
+	})
+	err := resourceElasticsearchIndexCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "requires the mapper-size plugin") {
+		t.Errorf("expected an error about the mapper-size plugin, got %v", err)
+	}
+}
+
+func TestIndexMappingsWithMetaFields(t *testing.T) {
+	cases := []struct {
+		name     string
+		declared string
+		index    string
+		typed    bool
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			declared: `{"_size": {"enabled": true}, "properties": {}}`,
+			index:    `{"_size": {"enabled": true}, "properties": {}}`,
+			expected: `{"_size": {"enabled": true}, "properties": {}}`,
+		},
+		{
+			name:     "disabled and omitted by the server",
+			declared: `{"_size": {"enabled": false}}`,
+			index:    `{}`,
+			expected: `{"_size": {"enabled": false}}`,
+		},
+		{
+			name:     "missing from the index",
+			declared: `{"_size": {"enabled": true}, "properties": {}}`,
+			index:    `{"properties": {}}`,
+			expected: `{"properties":{}}`,
+		},
+		{
+			name:     "added to the index",
+			declared: `{"properties": {}}`,
+			index:    `{"_doc_count": {"enabled": true}, "properties": {}}`,
+			expected: `{"_doc_count":{"enabled":true},"properties":{}}`,
+		},
+		{
+			name:     "typed",
+			declared: `{"_doc": {"properties": {}}}`,
+			index:    `{"_doc": {"_size": {"enabled": true}, "properties": {}}}`,
+			typed:    true,
+			expected: `{"_doc":{"_size":{"enabled":true},"properties":{}}}`,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := indexMappingsWithMetaFields(c.declared, mustUnmarshal(t, c.index), c.typed)
+		if err != nil {
+			t.Fatalf("%s: err: %s", c.name, err)
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+}
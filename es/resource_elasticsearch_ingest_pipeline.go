@@ -3,9 +3,12 @@ package es
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -29,6 +32,18 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 				Required:         true,
 				ValidateFunc:     validation.StringIsJSON,
 			},
+			"ownership_guard": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Stamp `_meta.managed_by` with `terraform` on the pipeline, and refuse to create, update or delete a pipeline whose `_meta.managed_by` names another owner, e.g. a pipeline managed by Fleet, unless `force` is set.",
+			},
+			"force": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Overwrite or delete the pipeline even if `_meta.managed_by` names another owner than terraform.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -36,7 +51,13 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 	}
 }
 
+// ingestPipelineManagedBy is the owner stamped on pipelines in _meta.managed_by.
+const ingestPipelineManagedBy = "terraform"
+
 func resourceElasticsearchIngestPipelineCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchIngestPipelineCheckOwner(d.Get("name").(string), d, meta); err != nil {
+		return err
+	}
 
 	err := resourceElasticsearchPutIngestPipeline(d, meta)
 	if err != nil {
@@ -121,12 +142,20 @@ func elastic5IngestGetPipeline(client *elastic5.Client, id string) (string, erro
 }
 
 func resourceElasticsearchIngestPipelineUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchIngestPipelineCheckOwner(d.Id(), d, meta); err != nil {
+		return err
+	}
+
 	return resourceElasticsearchPutIngestPipeline(d, meta)
 }
 
 func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
+	if err := resourceElasticsearchIngestPipelineCheckOwner(id, d, meta); err != nil {
+		return err
+	}
+
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
@@ -154,6 +183,12 @@ func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interfa
 	body := d.Get("body").(string)
 
 	var err error
+	if d.Get("ownership_guard").(bool) {
+		if body, err = stampIngestPipelineOwner(body); err != nil {
+			return err
+		}
+	}
+
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
@@ -170,3 +205,101 @@ func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interfa
 
 	return err
 }
+
+// resourceElasticsearchIngestPipelineCheckOwner fails if the ownership guard
+// is enabled and the pipeline in the cluster is managed by another owner.
+// Pipelines without an owner are taken over.
+func resourceElasticsearchIngestPipelineCheckOwner(name string, d *schema.ResourceData, meta interface{}) error {
+	if !d.Get("ownership_guard").(bool) || d.Get("force").(bool) {
+		return nil
+	}
+
+	owner, err := ingestPipelineOwner(name, meta)
+	if err != nil {
+		return err
+	}
+	if owner != "" && owner != ingestPipelineManagedBy {
+		return fmt.Errorf("ingest pipeline %s is managed by %s, set force to overwrite or delete it anyway", name, owner)
+	}
+
+	return nil
+}
+
+// ingestPipelineOwner returns the _meta.managed_by of the pipeline, empty if
+// it isn't set or the pipeline doesn't exist. The typed responses of the
+// elastic clients don't include _meta.
+func ingestPipelineOwner(name string, meta interface{}) (string, error) {
+	path, err := uritemplates.Expand("/_ingest/pipeline/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error building URL path for ingest pipeline: %+v", err)
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return "", err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, nil, nil, http.StatusNotFound)
+		if err == nil {
+			body = res.Body
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading ingest pipeline %s: %+v", name, err)
+	}
+
+	var pipelines map[string]struct {
+		Meta map[string]interface{} `json:"_meta"`
+	}
+	if err := json.Unmarshal(body, &pipelines); err != nil {
+		// the body of a missing pipeline is empty
+		return "", nil
+	}
+
+	owner, _ := pipelines[name].Meta["managed_by"].(string)
+	return owner, nil
+}
+
+// stampIngestPipelineOwner returns the body of the pipeline with
+// _meta.managed_by set to terraform.
+func stampIngestPipelineOwner(body string) (string, error) {
+	var pipeline map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &pipeline); err != nil {
+		return "", err
+	}
+
+	pipelineMeta, ok := pipeline["_meta"].(map[string]interface{})
+	if !ok {
+		pipelineMeta = make(map[string]interface{})
+	}
+	pipelineMeta["managed_by"] = ingestPipelineManagedBy
+	pipeline["_meta"] = pipelineMeta
+
+	stamped, err := json.Marshal(pipeline)
+	return string(stamped), err
+}
@@ -2,7 +2,12 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -218,3 +223,71 @@ resource "elasticsearch_ingest_pipeline" "test" {
 EOF
 }
 `
+
+func TestIngestPipelineOwnershipGuard(t *testing.T) {
+	var put map[string]interface{}
+	var deleted bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_ingest/pipeline/logs-system.syslog" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"logs-system.syslog": {"description": "Pipeline for syslog", "processors": [], "_meta": {"managed_by": "fleet", "managed": true}}}`)
+		case "PUT":
+			body, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &put); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case "DELETE":
+			deleted = true
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	pipelineSchema := resourceElasticsearchIngestPipeline().Schema
+	body := `{"description": "Pipeline for syslog", "processors": []}`
+	resourceData := schema.TestResourceDataRaw(t, pipelineSchema, map[string]interface{}{
+		"name":            "logs-system.syslog",
+		"body":            body,
+		"ownership_guard": true,
+	})
+	resourceData.SetId("logs-system.syslog")
+
+	err := resourceElasticsearchIngestPipelineUpdate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "managed by fleet") {
+		t.Errorf("expected an error about the pipeline managed by fleet, got %v", err)
+	}
+	err = resourceElasticsearchIngestPipelineDelete(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "managed by fleet") {
+		t.Errorf("expected an error about the pipeline managed by fleet, got %v", err)
+	}
+	if put != nil || deleted {
+		t.Error("expected the pipeline managed by fleet to be left alone")
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, pipelineSchema, map[string]interface{}{
+		"name":            "logs-system.syslog",
+		"body":            body,
+		"ownership_guard": true,
+		"force":           true,
+	})
+	resourceData.SetId("logs-system.syslog")
+	if err := resourceElasticsearchIngestPipelineUpdate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if owner := put["_meta"].(map[string]interface{})["managed_by"]; owner != "terraform" {
+		t.Errorf("expected the pipeline to be stamped as managed by terraform, got %v", owner)
+	}
+
+	// the stamped owner isn't a diff
+	stamped := `{"description": "Pipeline for syslog", "processors": [], "_meta": {"managed_by": "terraform"}}`
+	if !diffSuppressIngestPipeline("body", stamped, body, resourceData) {
+		t.Error("expected the stamped owner not to be a diff")
+	}
+}
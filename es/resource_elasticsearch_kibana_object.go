@@ -22,8 +22,9 @@ func resourceElasticsearchKibanaObject() *schema.Resource {
 		Delete: resourceElasticsearchKibanaObjectDelete,
 		Schema: map[string]*schema.Schema{
 			"body": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: diffSuppressKibanaObject,
 				ValidateFunc: func(i interface{}, k string) (warnings []string, errors []error) {
 					v, ok := i.(string)
 					if !ok {
@@ -215,7 +216,7 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 	}
 
 	if err != nil {
-		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) || elastic5.IsNotFound(err) {
+		if err == errObjNotFound || elastic7.IsNotFound(err) || elastic6.IsNotFound(err) || elastic5.IsNotFound(err) {
 			log.Printf("[WARN] Kibana Object (%s) not found, removing from state", id)
 			d.SetId("")
 			return nil
@@ -226,7 +227,23 @@ func resourceElasticsearchKibanaObjectRead(d *schema.ResourceData, meta interfac
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("index", index)
-	d.Set("body", result)
+
+	// Read back the object in the format of the body to detect changes made
+	// e.g. in Kibana, fields added by Kibana are ignored when comparing
+	if len(body) == 1 {
+		object := map[string]interface{}{
+			"_id":     id,
+			"_source": result,
+		}
+		if body[0]["_type"] != nil {
+			object["_type"] = objectType
+		}
+		objectJSON, err := json.Marshal([]interface{}{object})
+		if err != nil {
+			return err
+		}
+		ds.set("body", string(objectJSON))
+	}
 
 	return ds.err
 }
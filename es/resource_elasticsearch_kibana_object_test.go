@@ -3,7 +3,10 @@ package es
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
@@ -63,6 +66,74 @@ func TestAccElasticsearchKibanaObject(t *testing.T) {
 	})
 }
 
+func TestKibanaObjectIndexPattern(t *testing.T) {
+	stored := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		// .kibana is an alias of the index managed by Kibana
+		case r.Method == "HEAD" && r.URL.Path == "/.kibana":
+		case r.Method == "PUT" && r.URL.Path == "/.kibana/_doc/index-pattern:cloudwatch":
+			body, _ := ioutil.ReadAll(r.Body)
+			stored = string(body)
+			fmt.Fprint(w, `{"_index": ".kibana_1", "_id": "index-pattern:cloudwatch", "result": "created"}`)
+		case r.Method == "GET" && r.URL.Path == "/.kibana/_doc/index-pattern:cloudwatch":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"_index": ".kibana_1", "_id": "index-pattern:cloudwatch", "found": false}`)
+				return
+			}
+			fmt.Fprint(w, `{"_index": ".kibana_1", "_id": "index-pattern:cloudwatch", "found": true, "_source": {
+  "type": "index-pattern",
+  "index-pattern": {"title": "cloudwatch-*", "timeFieldName": "timestamp"},
+  "updated_at": "2021-01-01T00:00:00.000Z",
+  "migrationVersion": {"index-pattern": "7.6.0"}
+}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	configured := `[{"_id": "index-pattern:cloudwatch", "_source": {"type": "index-pattern", "index-pattern": {"title": "cloudwatch-*", "timeFieldName": "timestamp"}}}]`
+	meta := testProviderMeta(t, ts.URL)
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchKibanaObject().Schema, map[string]interface{}{
+		"body": configured,
+	})
+
+	if err := resourceElasticsearchKibanaObjectRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "" {
+		t.Fatalf("expected a missing object to clear the ID, got %s", resourceData.Id())
+	}
+
+	if err := resourceElasticsearchKibanaObjectCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "index-pattern:cloudwatch" {
+		t.Errorf("expected the ID to be the object ID, got %s", resourceData.Id())
+	}
+	if stored == "" {
+		t.Fatalf("expected the object to be stored")
+	}
+
+	if err := resourceElasticsearchKibanaObjectRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	read := resourceData.Get("body").(string)
+	if read == configured {
+		t.Errorf("expected the body to be read back from the cluster")
+	}
+	if !diffSuppressKibanaObject("body", read, configured, resourceData) {
+		t.Errorf("expected %s to be equivalent to %s", read, configured)
+	}
+	changed := `[{"_id": "index-pattern:cloudwatch", "_source": {"type": "index-pattern", "index-pattern": {"title": "logs-*", "timeFieldName": "timestamp"}}}]`
+	if diffSuppressKibanaObject("body", read, changed, resourceData) {
+		t.Errorf("expected %s to differ from %s", read, changed)
+	}
+}
+
 func TestAccElasticsearchKibanaObject_ProviderFormatInvalid(t *testing.T) {
 	provider := Provider().(*schema.Provider)
 	err := provider.Configure(&terraform.ResourceConfig{})
@@ -0,0 +1,245 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var openDistroAnomalyDetectorSchema = map[string]*schema.Schema{
+	"body": {
+		Type:             schema.TypeString,
+		Required:         true,
+		DiffSuppressFunc: diffSuppressAnomalyDetector,
+		StateFunc: func(v interface{}) string {
+			json, _ := structure.NormalizeJsonString(v)
+			return json
+		},
+		ValidateFunc: validation.StringIsJSON,
+	},
+	"enabled": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether the detector job is started, to detect anomalies in real time.",
+	},
+	"primary_term": {
+		Type:     schema.TypeInt,
+		Optional: true,
+		Computed: true,
+	},
+	"seq_no": {
+		Type:     schema.TypeInt,
+		Optional: true,
+		Computed: true,
+	},
+}
+
+func resourceElasticsearchOpenDistroAnomalyDetector() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchOpenDistroAnomalyDetectorCreate,
+		Read:   resourceElasticsearchOpenDistroAnomalyDetectorRead,
+		Update: resourceElasticsearchOpenDistroAnomalyDetectorUpdate,
+		Delete: resourceElasticsearchOpenDistroAnomalyDetectorDelete,
+		Schema: openDistroAnomalyDetectorSchema,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchOpenDistroAnomalyDetectorCreate(d *schema.ResourceData, m interface{}) error {
+	res, err := resourceElasticsearchOpenDistroAnomalyDetectorRequest("POST", "/", "", nil, d.Get("body").(string), m)
+	if err != nil {
+		log.Printf("[INFO] Failed to post anomaly detector: %+v", err)
+		return err
+	}
+
+	d.SetId(res.ID)
+	log.Printf("[INFO] Object ID: %s", d.Id())
+
+	if d.Get("enabled").(bool) {
+		if err := resourceElasticsearchOpenDistroAnomalyDetectorJob(d.Id(), "_start", m); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchOpenDistroAnomalyDetectorRead(d, m)
+}
+
+func resourceElasticsearchOpenDistroAnomalyDetectorRead(d *schema.ResourceData, m interface{}) error {
+	params := url.Values{}
+	params.Set("job", "true")
+	res, err := resourceElasticsearchOpenDistroAnomalyDetectorRequest("GET", "/{id}", d.Id(), params, nil, m)
+
+	if elastic7.IsNotFound(err) {
+		log.Printf("[WARN] Anomaly detector (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	detectorJSON, err := json.Marshal(res.AnomalyDetector)
+	if err != nil {
+		return err
+	}
+	detectorJSONNormalized, err := structure.NormalizeJsonString(string(detectorJSON))
+	if err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("body", detectorJSONNormalized)
+	ds.set("enabled", res.AnomalyDetectorJob != nil && res.AnomalyDetectorJob.Enabled)
+	ds.set("primary_term", res.PrimaryTerm)
+	ds.set("seq_no", res.SeqNo)
+	return ds.err
+}
+
+func resourceElasticsearchOpenDistroAnomalyDetectorUpdate(d *schema.ResourceData, m interface{}) error {
+	o, _ := d.GetChange("enabled")
+	running := o.(bool)
+
+	if d.HasChange("body") {
+		// the detector can't be updated while its job is running
+		if running {
+			if err := resourceElasticsearchOpenDistroAnomalyDetectorJob(d.Id(), "_stop", m); err != nil {
+				return err
+			}
+			running = false
+		}
+
+		params := url.Values{}
+		seq := d.Get("seq_no").(int)
+		primTerm := d.Get("primary_term").(int)
+		// only update the detector if it hasn't changed since it was last read
+		if seq >= 0 && primTerm > 0 {
+			params.Set("if_seq_no", strconv.Itoa(seq))
+			params.Set("if_primary_term", strconv.Itoa(primTerm))
+		}
+
+		_, err := resourceElasticsearchOpenDistroAnomalyDetectorRequest("PUT", "/{id}", d.Id(), params, d.Get("body").(string), m)
+		if elastic7.IsConflict(err) {
+			return fmt.Errorf("anomaly detector (%s) was modified outside of terraform, refresh the state and try again: %+v", d.Id(), err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if enabled := d.Get("enabled").(bool); enabled != running {
+		action := "_stop"
+		if enabled {
+			action = "_start"
+		}
+		if err := resourceElasticsearchOpenDistroAnomalyDetectorJob(d.Id(), action, m); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchOpenDistroAnomalyDetectorRead(d, m)
+}
+
+func resourceElasticsearchOpenDistroAnomalyDetectorDelete(d *schema.ResourceData, m interface{}) error {
+	// a detector can't be deleted while its job is running
+	if d.Get("enabled").(bool) {
+		if err := resourceElasticsearchOpenDistroAnomalyDetectorJob(d.Id(), "_stop", m); err != nil {
+			return err
+		}
+	}
+
+	_, err := resourceElasticsearchOpenDistroAnomalyDetectorRequest("DELETE", "/{id}", d.Id(), nil, nil, m)
+	if elastic7.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// resourceElasticsearchOpenDistroAnomalyDetectorJob starts or stops the job
+// of a detector, with the _start or _stop action.
+func resourceElasticsearchOpenDistroAnomalyDetectorJob(id, action string, m interface{}) error {
+	_, err := resourceElasticsearchOpenDistroAnomalyDetectorRequest("POST", "/{id}/"+action, id, nil, nil, m)
+	if err != nil {
+		return fmt.Errorf("error calling %s of anomaly detector (%s): %+v", action, id, err)
+	}
+	return nil
+}
+
+// resourceElasticsearchOpenDistroAnomalyDetectorRequest sends a request to
+// the detectors API, which is prefixed with _plugins on OpenSearch. The
+// template is expanded with the ID of the detector.
+func resourceElasticsearchOpenDistroAnomalyDetectorRequest(method, template, id string, params url.Values, body interface{}, m interface{}) (*anomalyDetectorResponse, error) {
+	conf := m.(*ProviderConf)
+	response := new(anomalyDetectorResponse)
+
+	esClient, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// the flavor of the cluster is known once the client is created
+	prefix := "/_opendistro/_anomaly_detection/detectors"
+	if conf.flavor == OpenSearch {
+		prefix = "/_plugins/_anomaly_detection/detectors"
+	}
+	path, err := uritemplates.Expand(prefix+template, map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for anomaly detector: %+v", err)
+	}
+
+	var resBody json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: method,
+			Path:   path,
+			Params: params,
+			Body:   body,
+		})
+		if err == nil {
+			resBody = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "anomaly detector", MinimumVersion: "v7"}
+	}
+
+	if err != nil {
+		return response, err
+	}
+
+	if len(resBody) == 0 {
+		return response, nil
+	}
+	if err := json.Unmarshal(resBody, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling anomaly detector body: %+v: %+v", err, resBody)
+	}
+	normalizeAnomalyDetector(response.AnomalyDetector)
+	return response, nil
+}
+
+type anomalyDetectorResponse struct {
+	Version            int                    `json:"_version"`
+	ID                 string                 `json:"_id"`
+	PrimaryTerm        int                    `json:"_primary_term"`
+	SeqNo              int                    `json:"_seq_no"`
+	AnomalyDetector    map[string]interface{} `json:"anomaly_detector"`
+	AnomalyDetectorJob *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"anomaly_detector_job"`
+}
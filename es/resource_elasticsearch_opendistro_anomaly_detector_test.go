@@ -0,0 +1,108 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroAnomalyDetectorCreate(t *testing.T) {
+	detector := `{
+  "name": "test-detector",
+  "time_field": "timestamp",
+  "indices": ["server-logs"],
+  "detection_interval": {"period": {"interval": 1, "unit": "Minutes"}},
+  "feature_attributes": [{
+    "feature_name": "total_bytes",
+    "feature_enabled": true,
+    "aggregation_query": {"total_bytes": {"sum": {"field": "bytes"}}}
+  }]
+}`
+	saved := `{
+  "name": "test-detector",
+  "time_field": "timestamp",
+  "indices": ["server-logs"],
+  "detection_interval": {"period": {"interval": 1, "unit": "Minutes"}},
+  "feature_attributes": [{
+    "feature_id": "f1",
+    "feature_name": "total_bytes",
+    "feature_enabled": true,
+    "aggregation_query": {"total_bytes": {"sum": {"field": "bytes"}}}
+  }],
+  "schema_version": 0,
+  "last_update_time": 1609459200000
+}`
+
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `{"version": {"number": "2.5.0", "distribution": "opensearch"}}`)
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/_opendistro"), "/_plugins")
+		switch {
+		case r.Method == "POST" && path == "/_anomaly_detection/detectors/":
+			fmt.Fprintf(w, `{"_id": "abc", "_version": 1, "_seq_no": 3, "_primary_term": 1, "anomaly_detector": %s}`, saved)
+		case r.Method == "POST" && path == "/_anomaly_detection/detectors/abc/_start":
+			fmt.Fprint(w, `{"_id": "abc", "_version": 1, "_seq_no": 4, "_primary_term": 1}`)
+		case r.Method == "GET" && path == "/_anomaly_detection/detectors/abc":
+			if r.URL.Query().Get("job") != "true" {
+				t.Error("expected the job of the detector to be read")
+			}
+			fmt.Fprintf(w, `{"_id": "abc", "_version": 1, "_seq_no": 3, "_primary_term": 1, "anomaly_detector": %s, "anomaly_detector_job": {"name": "abc", "enabled": true}}`, saved)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		prefix string
+	}{
+		{"opendistro", nil, "/_opendistro"},
+		// the flavor is only detected when the client is created
+		{"opensearch", map[string]interface{}{"elasticsearch_version": nil}, "/_plugins"},
+	}
+	for _, c := range cases {
+		requests = nil
+		meta := testProviderMetaWith(t, ts.URL, c.config)
+
+		resourceData := schema.TestResourceDataRaw(t, openDistroAnomalyDetectorSchema, map[string]interface{}{
+			"body":    detector,
+			"enabled": true,
+		})
+		if err := resourceElasticsearchOpenDistroAnomalyDetectorCreate(resourceData, meta); err != nil {
+			t.Fatalf("%s: err: %s", c.name, err)
+		}
+
+		expected := []string{
+			"POST " + c.prefix + "/_anomaly_detection/detectors/",
+			"POST " + c.prefix + "/_anomaly_detection/detectors/abc/_start",
+			"GET " + c.prefix + "/_anomaly_detection/detectors/abc",
+		}
+		if !reflect.DeepEqual(requests, expected) {
+			t.Errorf("%s: expected requests %v, got %v", c.name, expected, requests)
+		}
+		if resourceData.Id() != "abc" {
+			t.Errorf("expected ID abc, got %q", resourceData.Id())
+		}
+		if !resourceData.Get("enabled").(bool) {
+			t.Error("expected the detector to be enabled")
+		}
+		if seqNo, primaryTerm := resourceData.Get("seq_no").(int), resourceData.Get("primary_term").(int); seqNo != 3 || primaryTerm != 1 {
+			t.Errorf("expected seq_no 3 and primary_term 1, got %d and %d", seqNo, primaryTerm)
+		}
+		if !diffSuppressAnomalyDetector("body", resourceData.Get("body").(string), detector, nil) {
+			t.Errorf("expected the detector to round trip without a diff, got %s", resourceData.Get("body"))
+		}
+	}
+}
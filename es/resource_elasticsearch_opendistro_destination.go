@@ -3,10 +3,17 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
-
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -19,67 +26,551 @@ import (
 const DESTINATION_TYPE = "_doc"
 const DESTINATION_INDEX = ".opendistro-alerting-config"
 
+const (
+	openDistroDestinationsPath = "/_opendistro/_alerting/destinations"
+	openSearchDestinationsPath = "/_plugins/_alerting/destinations"
+)
+
+// destinationIDPrefixes maps the prefixes of the IDs of destinations to the
+// API they were created with.
+var destinationIDPrefixes = map[string]string{
+	"opendistro": openDistroDestinationsPath,
+	"opensearch": openSearchDestinationsPath,
+}
+
+// destinationTypes are the types of destinations, each configured by the
+// object of the same name in the body.
+var destinationTypes = []string{"slack", "custom_webhook", "chime", "sns", "email"}
+
+// destinationBodyKeys are the top-level keys of the body of a destination
+// accepted with strict.
+var destinationBodyKeys = append([]string{"id", "type", "name"}, destinationTypes...)
+
 var openDistroDestinationSchema = map[string]*schema.Schema{
 	"body": {
 		Type:             schema.TypeString,
-		Required:         true,
+		Optional:         true,
+		Computed:         true,
+		ExactlyOneOf:     []string{"body", "body_file"},
 		DiffSuppressFunc: diffSuppressDestination,
-		ValidateFunc:     validation.StringIsJSON,
+		ValidateFunc:     validation.All(validation.StringIsJSON, validateDestinationType),
 		StateFunc: func(v interface{}) string {
 			json, _ := structure.NormalizeJsonString(v)
 			return json
 		},
-		Description: "The JSON body of the destination.",
+		Description: "The JSON body of the destination. Set from the file with `body_file`.",
+	},
+	"body_file": {
+		Type:         schema.TypeString,
+		Optional:     true,
+		ExactlyOneOf: []string{"body", "body_file"},
+		Description:  "The path of a file containing the JSON body of the destination, as an alternative to `body`. The file is read when planning and applying, changes of its contents are detected.",
+	},
+	"fail_on_existing": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Fail to create the destination if a destination with the same name already exists in the cluster, instead of creating another one or, on some versions, adopting the existing one.",
+	},
+	"preserve_unknown_fields": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Ignore fields of the destination in the cluster that are missing from the body, e.g. to import a destination without a diff. These fields aren't managed, changes of them aren't detected.",
+	},
+	"strict": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Reject bodies with top-level keys other than `id`, `type`, `name` and the objects of the destination types when planning, e.g. to catch a misspelled `slak`. Disabled by default, so that bodies of types added by newer versions of the plugin are accepted.",
+	},
+	"validate_on_plan": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Check that the body has the fields required by the alerting API when planning, e.g. the `url` of a slack destination, instead of failing when applying. The body isn't sent to the cluster for this.",
+	},
+	"document_type": {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     DESTINATION_TYPE,
+		Description: "The mapping type of the documents of the alerting config index, used to read the destination from the index on Elasticsearch 6. Only needed when the index still uses a custom type.",
+	},
+	"destination_id": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The ID of the destination in the cluster, to reference from monitors. The ID of the resource is prefixed with the flavor of the cluster for OpenSearch.",
+	},
+	"created_by": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The name of the user who last saved the destination, from the `user` block the security plugin adds to it. Empty without the security plugin.",
+	},
+	"last_update_time": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "RFC3339 timestamp of when the destination was last updated, set by the server and ignored when comparing the body.",
+	},
+	"owner": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The name of the user owning the destination, from the `user` block the security plugin adds to it, e.g. to audit the ownership of destinations. Empty without the security plugin.",
+	},
+	"reserved": {
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the server marks the destination as reserved, i.e. managed by the server rather than by users. Ignored when comparing the body.",
 	},
 }
 
 func resourceElasticsearchDeprecatedDestination() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroDestinationCreate,
-		Read:   resourceElasticsearchOpenDistroDestinationRead,
-		Update: resourceElasticsearchOpenDistroDestinationUpdate,
-		Delete: resourceElasticsearchOpenDistroDestinationDelete,
-		Schema: openDistroDestinationSchema,
+		Create:        resourceElasticsearchOpenDistroDestinationCreate,
+		Read:          resourceElasticsearchOpenDistroDestinationRead,
+		Update:        resourceElasticsearchOpenDistroDestinationUpdate,
+		Delete:        resourceElasticsearchOpenDistroDestinationDelete,
+		Schema:        openDistroDestinationSchema,
+		CustomizeDiff: resourceElasticsearchOpenDistroDestinationCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+		},
 		DeprecationMessage: "elasticsearch_destination is deprecated, please use elasticsearch_opendistro_destination resource instead.",
 	}
 }
 
 func resourceElasticsearchOpenDistroDestination() *schema.Resource {
 	return &schema.Resource{
-		Description: "Provides an Elasticsearch OpenDistro destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
-		Create:      resourceElasticsearchOpenDistroDestinationCreate,
-		Read:        resourceElasticsearchOpenDistroDestinationRead,
-		Update:      resourceElasticsearchOpenDistroDestinationUpdate,
-		Delete:      resourceElasticsearchOpenDistroDestinationDelete,
-		Schema:      openDistroDestinationSchema,
+		Description:   "Provides an Elasticsearch OpenDistro destination, a reusable communication channel for an action, such as email, Slack, or a webhook URL. Please refer to the OpenDistro [destination documentation](https://opendistro.github.io/for-elasticsearch-docs/docs/alerting/monitors/#create-destinations) for details.",
+		Create:        resourceElasticsearchOpenDistroDestinationCreate,
+		Read:          resourceElasticsearchOpenDistroDestinationRead,
+		Update:        resourceElasticsearchOpenDistroDestinationUpdate,
+		Delete:        resourceElasticsearchOpenDistroDestinationDelete,
+		Schema:        openDistroDestinationSchema,
+		CustomizeDiff: resourceElasticsearchOpenDistroDestinationCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceElasticsearchOpenDistroDestinationImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
 		},
 	}
 }
 
+// resourceElasticsearchOpenDistroDestinationImport imports a destination by
+// its ID, or by its type and name, e.g. slack/my-destination, resolving the
+// ID of the only destination matching both.
+func resourceElasticsearchOpenDistroDestinationImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || !isDestinationType(parts[0]) {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	destinationType, name := parts[0], parts[1]
+	ids, err := resourceElasticsearchOpenDistroDestinationIDsByName(name, destinationType, m)
+	if err != nil {
+		return nil, fmt.Errorf("error searching for %s destinations named %q: %+v", destinationType, name, err)
+	}
+	switch len(ids) {
+	case 0:
+		return nil, fmt.Errorf("no %s destination named %q found", destinationType, name)
+	case 1:
+		d.SetId(formatDestinationID(m.(*ProviderConf).flavor, ids[0]))
+		return []*schema.ResourceData{d}, nil
+	default:
+		return nil, fmt.Errorf("%d %s destinations are named %q, import one of them by its ID instead: %s", len(ids), destinationType, name, strings.Join(ids, ", "))
+	}
+}
+
+func isDestinationType(destinationType string) bool {
+	for _, t := range destinationTypes {
+		if t == destinationType {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceElasticsearchOpenDistroDestinationCustomizeDiff sets the body from
+// body_file, rejects unknown keys of the body with strict and missing fields
+// with validate_on_plan, and forces a new destination when its type
+// changes, the API would otherwise keep the sub object of the previous type
+// around.
+func resourceElasticsearchOpenDistroDestinationCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if path := d.Get("body_file").(string); path != "" && d.NewValueKnown("body_file") {
+		body, err := readDestinationBodyFile(path)
+		if err != nil {
+			return err
+		}
+		// the diff suppression doesn't apply to values set here
+		old, _ := d.GetChange("body")
+		if !equivalentDestinations(old.(string), body, d.Get("preserve_unknown_fields").(bool)) {
+			if err := d.SetNew("body", body); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Get("strict").(bool) && d.NewValueKnown("body") {
+		if unknown := unknownDestinationKeys(d.Get("body").(string)); len(unknown) > 0 {
+			return fmt.Errorf("body of the destination has unknown keys %s, expected only %s", strings.Join(unknown, ", "), strings.Join(destinationBodyKeys, ", "))
+		}
+	}
+
+	if d.Get("validate_on_plan").(bool) && d.NewValueKnown("body") {
+		if missing := destinationMissingFields(d.Get("body").(string)); len(missing) > 0 {
+			return fmt.Errorf("body of the destination is missing the required fields %s", strings.Join(missing, ", "))
+		}
+	}
+
+	if d.Id() == "" || !d.HasChange("body") {
+		return nil
+	}
+
+	o, n := d.GetChange("body")
+	if destinationTypeChanged(o.(string), n.(string)) {
+		return d.ForceNew("body")
+	}
+
+	return nil
+}
+
+// destinationBody returns the body of the destination, read from body_file
+// if it is set.
+func destinationBody(d *schema.ResourceData) (string, error) {
+	if path := d.Get("body_file").(string); path != "" {
+		return readDestinationBodyFile(path)
+	}
+	return d.Get("body").(string), nil
+}
+
+// readDestinationBodyFile returns the normalized body of a destination in the
+// file, validated like body.
+func readDestinationBodyFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading the body of the destination: %+v", err)
+	}
+
+	validate := validation.All(validation.StringIsJSON, validateDestinationType)
+	if _, errs := validate(string(contents), "body_file"); len(errs) > 0 {
+		return "", fmt.Errorf("invalid body of the destination in %s: %+v", path, errs[0])
+	}
+
+	return structure.NormalizeJsonString(string(contents))
+}
+
+// destinationRequiredFields are the fields of the channel object of each type
+// of destination required by the alerting API. A field of the form a|b
+// requires either of them.
+var destinationRequiredFields = map[string][]string{
+	"slack":          {"url"},
+	"chime":          {"url"},
+	"custom_webhook": {"url|host"},
+	"sns":            {"topic_arn", "role_arn"},
+	"email":          {"email_account_id", "recipients"},
+}
+
+// destinationMissingFields returns the fields required by the alerting API
+// which are missing from the body of the destination.
+func destinationMissingFields(body string) []string {
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &destination); err != nil {
+		return nil
+	}
+
+	var missing []string
+	for _, key := range []string{"name", "type"} {
+		if isEmptyField(destination[key]) {
+			missing = append(missing, key)
+		}
+	}
+
+	t, _ := destination["type"].(string)
+	channel, _ := destination[t].(map[string]interface{})
+	for _, field := range destinationRequiredFields[t] {
+		found := false
+		for _, f := range strings.Split(field, "|") {
+			if !isEmptyField(channel[f]) {
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, t+"."+strings.Replace(field, "|", " or "+t+".", -1))
+		}
+	}
+
+	return missing
+}
+
+// isEmptyField returns whether the field of a body is missing, or an empty
+// string, object or list.
+func isEmptyField(v interface{}) bool {
+	switch f := v.(type) {
+	case nil:
+		return true
+	case string:
+		return f == ""
+	case map[string]interface{}:
+		return len(f) == 0
+	case []interface{}:
+		return len(f) == 0
+	}
+	return false
+}
+
+// unknownDestinationKeys returns the sorted top-level keys of the body which
+// aren't in destinationBodyKeys.
+func unknownDestinationKeys(body string) []string {
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &destination); err != nil {
+		return nil
+	}
+
+	var unknown []string
+	for key := range destination {
+		known := false
+		for _, k := range destinationBodyKeys {
+			if key == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+func destinationTypeChanged(old, new string) bool {
+	oldType, newType := destinationType(old), destinationType(new)
+	return oldType != "" && newType != "" && oldType != newType
+}
+
+func destinationType(body string) string {
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &destination); err != nil {
+		return ""
+	}
+
+	t, _ := destination["type"].(string)
+	return t
+}
+
+// validateDestinationType checks that the type of the destination is known,
+// and that the body configures the channel object of that type.
+func validateDestinationType(i interface{}, k string) (warnings []string, errors []error) {
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(i.(string)), &destination); err != nil {
+		return
+	}
+
+	t, ok := destination["type"].(string)
+	if !ok {
+		return
+	}
+
+	if !isDestinationType(t) {
+		errors = append(errors, fmt.Errorf("%q: type must be one of %s, got %q", k, strings.Join(destinationTypes, ", "), t))
+		return
+	}
+
+	if channel, ok := destination[t].(map[string]interface{}); !ok || len(channel) == 0 {
+		errors = append(errors, fmt.Errorf("%q: a destination of type %s requires a non-empty %s object", k, t, t))
+	}
+
+	return
+}
+
 func resourceElasticsearchOpenDistroDestinationCreate(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroPostDestination(d, m)
+	body, err := destinationBody(d)
+	if err != nil {
+		return err
+	}
+
+	name := destinationName(body)
+	existing, err := resourceElasticsearchOpenDistroDestinationIDsByName(name, "", m)
+	if err != nil {
+		if d.Get("fail_on_existing").(bool) {
+			return fmt.Errorf("error checking for existing destinations named %q: %+v", name, err)
+		}
+		log.Printf("[WARN] Failed to check for existing destinations named %q: %+v", name, err)
+	}
+	if len(existing) > 0 && d.Get("fail_on_existing").(bool) {
+		return fmt.Errorf("destination %q already exists with the ID %s, import it instead", name, strings.Join(existing, ", "))
+	}
+
+	res, err := resourceElasticsearchOpenDistroPostDestinationBody(body, d.Timeout(schema.TimeoutCreate), m)
 
 	if err != nil {
 		log.Printf("[INFO] Failed to put destination: %+v", err)
 		return err
 	}
 
-	d.SetId(res.ID)
+	for _, id := range existing {
+		if id == res.ID {
+			log.Printf("[WARN] Adopting the existing destination %q with the ID %s, it is now managed by this resource", name, id)
+		}
+	}
+
+	d.SetId(formatDestinationID(m.(*ProviderConf).flavor, res.ID))
 	destination, err := json.Marshal(res.Destination)
 	if err != nil {
 		return err
 	}
-	err = d.Set("body", string(destination))
-	return err
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("body", string(destination))
+	ds.set("destination_id", res.ID)
+	return ds.err
+}
+
+func destinationName(body string) string {
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &destination); err != nil {
+		return ""
+	}
+
+	name, _ := destination["name"].(string)
+	return name
+}
+
+// resourceElasticsearchOpenDistroDestinationIDsByName returns the IDs of the
+// destinations with the name, and the type unless it is empty, from the API
+// listing destinations.
+func resourceElasticsearchOpenDistroDestinationIDsByName(name string, destinationType string, m interface{}) ([]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	params := url.Values{}
+	params.Set("searchString", name)
+	if destinationType != "" {
+		params.Set("destinationType", destinationType)
+	}
+	response, err := resourceElasticsearchOpenDistroListDestinations(params, m)
+	if err != nil {
+		return nil, err
+	}
+
+	// the search string also matches other names
+	var ids []string
+	for _, destination := range response.Destinations {
+		if destinationType != "" && destination["type"] != destinationType {
+			continue
+		}
+		if destination["name"] == name {
+			if id, ok := destination["id"].(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// defaultDestinationsPageSize is the number of destinations requested per
+// page when listing destinations, unless destinations_page_size is set.
+const defaultDestinationsPageSize = 100
+
+func destinationsPageSize(m interface{}) int {
+	if size := m.(*ProviderConf).destinationsPageSize; size > 0 {
+		return size
+	}
+	return defaultDestinationsPageSize
+}
+
+// destinationsPage is a page of the API listing destinations.
+type destinationsPage struct {
+	Destinations      []map[string]interface{} `json:"destinations"`
+	TotalDestinations int                      `json:"totalDestinations"`
+}
+
+// resourceElasticsearchOpenDistroListDestinations returns a page of the API
+// listing destinations, filtered and paged by the params.
+func resourceElasticsearchOpenDistroListDestinations(params url.Values, m interface{}) (*destinationsPage, error) {
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	path := openDistroDestinationsPath
+	if m.(*ProviderConf).flavor == OpenSearch {
+		path = openSearchDestinationsPath
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+			Params: params,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+			Params: params,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := new(destinationsPage)
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling destinations body: %+v: %+v", err, string(body))
+	}
+
+	return response, nil
+}
+
+// resourceElasticsearchOpenDistroFindDestination pages through the API
+// listing destinations until it finds the destination with the ID.
+func resourceElasticsearchOpenDistroFindDestination(destinationID string, m interface{}) (map[string]interface{}, error) {
+	pageSize := destinationsPageSize(m)
+	for startIndex := 0; ; startIndex += pageSize {
+		params := url.Values{}
+		params.Set("size", strconv.Itoa(pageSize))
+		params.Set("startIndex", strconv.Itoa(startIndex))
+		params.Set("sortString", "destination.name.keyword")
+		params.Set("sortOrder", "asc")
+		page, err := resourceElasticsearchOpenDistroListDestinations(params, m)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, destination := range page.Destinations {
+			if destination["id"] == destinationID {
+				return destination, nil
+			}
+		}
+
+		if len(page.Destinations) == 0 || startIndex+len(page.Destinations) >= page.TotalDestinations {
+			return nil, nil
+		}
+	}
 }
 
 func resourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroGetDestination(d.Id(), m)
+	_, id := parseDestinationID(d.Id())
+	res, err := resourceElasticsearchOpenDistroGetDestinationWithType(id, d.Get("document_type").(string), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Destination (%s) not found, removing from state", d.Id())
@@ -91,13 +582,28 @@ func resourceElasticsearchOpenDistroDestinationRead(d *schema.ResourceData, m in
 		return err
 	}
 
-	err = d.Set("body", res)
-	return err
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(res), &destination); err != nil {
+		return fmt.Errorf("error unmarshalling destination body: %+v: %+v", err, res)
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("body", res)
+	ds.set("destination_id", id)
+	ds.set("created_by", alertingUserName(destination))
+	ds.set("last_update_time", alertingTimestamp(destination, "last_update_time"))
+	ds.set("owner", alertingUserName(destination))
+	ds.set("reserved", destination["reserved"] == true)
+	return ds.err
 }
 
 func resourceElasticsearchOpenDistroDestinationUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchOpenDistroPutDestination(d, m)
+	body, err := destinationBody(d)
+	if err != nil {
+		return err
+	}
 
+	_, err = resourceElasticsearchOpenDistroPutDestinationBody(d.Id(), body, m)
 	if err != nil {
 		return err
 	}
@@ -106,10 +612,17 @@ func resourceElasticsearchOpenDistroDestinationUpdate(d *schema.ResourceData, m
 }
 
 func resourceElasticsearchOpenDistroDestinationDelete(d *schema.ResourceData, m interface{}) error {
+	return resourceElasticsearchOpenDistroDeleteDestination(d.Id(), m)
+}
+
+// resourceElasticsearchOpenDistroDeleteDestination deletes the destination
+// with the ID of its resource.
+func resourceElasticsearchOpenDistroDeleteDestination(resourceID string, m interface{}) error {
 	var err error
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/destinations/{id}", map[string]string{
-		"id": d.Id(),
+	basePath, id := parseDestinationID(resourceID)
+	path, err := uritemplates.Expand(basePath+"/{id}", map[string]string{
+		"id": id,
 	})
 	if err != nil {
 		return fmt.Errorf("error building URL path for destination: %+v", err)
@@ -131,13 +644,42 @@ func resourceElasticsearchOpenDistroDestinationDelete(d *schema.ResourceData, m
 			Path:   path,
 		})
 	default:
-		err = errors.New("destination resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
 	}
 
 	return err
 }
 
+// formatDestinationID returns the ID of the resource of a destination. IDs of
+// destinations on OpenSearch are prefixed, to use the _plugins API for them.
+func formatDestinationID(flavor ServerFlavor, id string) string {
+	if flavor == OpenSearch {
+		return "opensearch:" + id
+	}
+	return id
+}
+
+// parseDestinationID returns the base path of the API of a destination and
+// its ID in the cluster. IDs without a prefix use the _opendistro API, as
+// they did before OpenSearch was supported.
+func parseDestinationID(resourceID string) (string, string) {
+	parts := strings.SplitN(resourceID, ":", 2)
+	if len(parts) == 2 {
+		if basePath, ok := destinationIDPrefixes[parts[0]]; ok {
+			return basePath, parts[1]
+		}
+	}
+	return openDistroDestinationsPath, resourceID
+}
+
 func resourceElasticsearchOpenDistroGetDestination(destinationID string, m interface{}) (string, error) {
+	return resourceElasticsearchOpenDistroGetDestinationWithType(destinationID, DESTINATION_TYPE, m)
+}
+
+// resourceElasticsearchOpenDistroGetDestinationWithType returns the body of
+// the destination, read from the alerting config index with the mapping type
+// on Elasticsearch 6.
+func resourceElasticsearchOpenDistroGetDestinationWithType(destinationID string, documentType string, m interface{}) (string, error) {
 	var err error
 	response := new(destinationResponse)
 
@@ -151,9 +693,26 @@ func resourceElasticsearchOpenDistroGetDestination(destinationID string, m inter
 	case *elastic7.Client:
 		body, err = elastic7GetObject(client, DESTINATION_INDEX, destinationID)
 	case *elastic6.Client:
-		body, err = elastic6GetObject(client, DESTINATION_TYPE, DESTINATION_INDEX, destinationID)
+		body, err = elastic6GetObject(client, documentType, DESTINATION_INDEX, destinationID)
 	default:
-		err = errors.New("destination resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
+	}
+
+	// the alerting config index may not be readable, e.g. when it is
+	// protected as a system index, fall back to the API listing destinations
+	if elastic7.IsForbidden(err) || elastic6.IsForbidden(err) || elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+		destination, listErr := resourceElasticsearchOpenDistroFindDestination(destinationID, m)
+		if listErr != nil {
+			log.Printf("[WARN] Unable to list destinations: %+v", listErr)
+			return "", err
+		}
+		if destination == nil {
+			return "", err
+		}
+		delete(destination, "id")
+
+		tj, err := json.Marshal(destination)
+		return string(tj), err
 	}
 
 	if err != nil {
@@ -172,40 +731,51 @@ func resourceElasticsearchOpenDistroGetDestination(destinationID string, m inter
 	return string(tj), err
 }
 
-func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
-	destinationJSON := d.Get("body").(string)
-
-	var err error
+// resourceElasticsearchOpenDistroPostDestinationBody creates a destination,
+// retrying until the timeout while the alerting config index isn't ready.
+func resourceElasticsearchOpenDistroPostDestinationBody(destinationJSON string, timeout time.Duration, m interface{}) (*destinationResponse, error) {
 	response := new(destinationResponse)
 
-	path := "/_opendistro/_alerting/destinations/"
-
-	var body json.RawMessage
+	// the flavor of the cluster is known once the client is created
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "POST",
-			Path:   path,
-			Body:   destinationJSON,
-		})
-		body = res.Body
-	case *elastic6.Client:
-		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "POST",
-			Path:   path,
-			Body:   destinationJSON,
-		})
-		body = res.Body
-	default:
-		err = errors.New("destination resource not implemented prior to Elastic v6")
+	path := openDistroDestinationsPath + "/"
+	if m.(*ProviderConf).flavor == OpenSearch {
+		path = openSearchDestinationsPath + "/"
 	}
 
+	var body json.RawMessage
+	err = retryUntilAlertingConfigIndexReady(timeout, func() error {
+		var err error
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			var res *elastic7.Response
+			res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+				Method: "POST",
+				Path:   path,
+				Body:   destinationJSON,
+			})
+			if err == nil {
+				body = res.Body
+			}
+		case *elastic6.Client:
+			var res *elastic6.Response
+			res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+				Method: "POST",
+				Path:   path,
+				Body:   destinationJSON,
+			})
+			if err == nil {
+				body = res.Body
+			}
+		default:
+			err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
+		}
+		return err
+	})
+
 	if err != nil {
 		return response, err
 	}
@@ -217,14 +787,53 @@ func resourceElasticsearchOpenDistroPostDestination(d *schema.ResourceData, m in
 	return response, nil
 }
 
-func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m interface{}) (*destinationResponse, error) {
-	destinationJSON := d.Get("body").(string)
+// retryUntilAlertingConfigIndexReady retries f while the alerting config
+// index is not available yet, e.g. right after the security plugin of a new
+// cluster has been initialized, until the timeout has elapsed.
+func retryUntilAlertingConfigIndexReady(timeout time.Duration, f func() error) error {
+	notReady := false
+	err := resource.Retry(timeout, func() *resource.RetryError {
+		err := f()
+		notReady = isAlertingConfigIndexNotReady(err)
+		if notReady {
+			log.Printf("[INFO] Alerting config index is not available yet, retrying: %+v", err)
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+
+	if err != nil && notReady {
+		return fmt.Errorf("the alerting config index %s never became available within %s: %+v", DESTINATION_INDEX, timeout, err)
+	}
+
+	return err
+}
+
+// isAlertingConfigIndexNotReady returns whether the error is a missing or
+// unavailable alerting config index.
+func isAlertingConfigIndexNotReady(err error) bool {
+	switch e := err.(type) {
+	case *elastic7.Error:
+		return e.Status == http.StatusNotFound || e.Status == http.StatusServiceUnavailable
+	case *elastic6.Error:
+		return e.Status == http.StatusNotFound || e.Status == http.StatusServiceUnavailable
+	}
+
+	return false
+}
 
+// resourceElasticsearchOpenDistroPutDestinationBody updates the destination
+// with the ID of its resource.
+func resourceElasticsearchOpenDistroPutDestinationBody(resourceID string, destinationJSON string, m interface{}) (*destinationResponse, error) {
 	var err error
 	response := new(destinationResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/destinations/{id}", map[string]string{
-		"id": d.Id(),
+	basePath, id := parseDestinationID(resourceID)
+	path, err := uritemplates.Expand(basePath+"/{id}", map[string]string{
+		"id": id,
 	})
 	if err != nil {
 		return response, fmt.Errorf("error building URL path for destination: %+v", err)
@@ -243,7 +852,9 @@ func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m int
 			Path:   path,
 			Body:   destinationJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
@@ -251,9 +862,11 @@ func resourceElasticsearchOpenDistroPutDestination(d *schema.ResourceData, m int
 			Path:   path,
 			Body:   destinationJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("destination resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "destination", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
@@ -1,8 +1,15 @@
 package es
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
@@ -49,10 +56,811 @@ func TestAccElasticsearchOpenDistroDestination(t *testing.T) {
 					testCheckElasticsearchOpenDistroDestinationExists("elasticsearch_opendistro_destination.test_destination"),
 				),
 			},
+			{
+				Config: testAccElasticsearchOpenDistroDestinationWebhook,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchOpenDistroDestinationExists("elasticsearch_opendistro_destination.test_destination"),
+				),
+			},
 		},
 	})
 }
 
+func TestParseDestinationID(t *testing.T) {
+	cases := []struct {
+		resourceID string
+		basePath   string
+		id         string
+	}{
+		{"lgOZb3UB96pyyRQv0ppQ", "/_opendistro/_alerting/destinations", "lgOZb3UB96pyyRQv0ppQ"},
+		{"opendistro:lgOZb3UB96pyyRQv0ppQ", "/_opendistro/_alerting/destinations", "lgOZb3UB96pyyRQv0ppQ"},
+		{"opensearch:lgOZb3UB96pyyRQv0ppQ", "/_plugins/_alerting/destinations", "lgOZb3UB96pyyRQv0ppQ"},
+	}
+
+	for _, tc := range cases {
+		basePath, id := parseDestinationID(tc.resourceID)
+		if basePath != tc.basePath || id != tc.id {
+			t.Errorf("%s: expected %s and %s, got %s and %s", tc.resourceID, tc.basePath, tc.id, basePath, id)
+		}
+	}
+
+	if id := formatDestinationID(OpenSearch, "abc"); id != "opensearch:abc" {
+		t.Errorf("expected opensearch:abc, got %s", id)
+	}
+	if id := formatDestinationID(Elasticsearch, "abc"); id != "abc" {
+		t.Errorf("expected abc, got %s", id)
+	}
+}
+
+func TestDestinationTypeChanged(t *testing.T) {
+	slack := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"}}`
+	webhook := `{"name":"my-destination","type":"custom_webhook","custom_webhook":{"url":"http://www.example.com"}}`
+	renamed := `{"name":"other-destination","type":"slack","slack":{"url":"http://www.example.com"}}`
+
+	cases := []struct {
+		old      string
+		new      string
+		expected bool
+	}{
+		{slack, webhook, true},
+		{slack, renamed, false},
+		{"", slack, false},
+		{slack, "not json", false},
+	}
+
+	for _, c := range cases {
+		if actual := destinationTypeChanged(c.old, c.new); actual != c.expected {
+			t.Errorf("destinationTypeChanged(%q, %q) = %t, expected %t", c.old, c.new, actual, c.expected)
+		}
+	}
+}
+
+func TestOpenDistroDestinationDiffTypeChange(t *testing.T) {
+	slack := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"}}`
+	slackRenamed := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.org"}}`
+	email := `{"name":"my-destination","type":"email","email":{"email_account_id":"abc","recipients":[{"type":"email","email":"ops@example.com"}]}}`
+
+	state := &terraform.InstanceState{
+		ID: "abc",
+		Attributes: map[string]string{
+			"id":                      "abc",
+			"body":                    slack,
+			"fail_on_existing":        "false",
+			"preserve_unknown_fields": "false",
+			"destination_id":          "abc",
+		},
+	}
+
+	cases := []struct {
+		body        string
+		requiresNew bool
+	}{
+		{email, true},
+		{slackRenamed, false},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"body": c.body,
+		})
+		diff, err := resourceElasticsearchOpenDistroDestination().Diff(state, config, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if diff == nil {
+			t.Fatalf("expected a diff for %s", c.body)
+		}
+		if diff.RequiresNew() != c.requiresNew {
+			t.Errorf("expected replacement to be %t for %s, got %t", c.requiresNew, c.body, diff.RequiresNew())
+		}
+	}
+}
+
+func TestOpenDistroDestinationDiffStrict(t *testing.T) {
+	typo := `{"name":"my-destination","type":"slack","slak":{"url":"http://www.example.com"},"slack":{"url":"http://www.example.com"}}`
+	future := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"},"microsoft_teams":{"url":"http://www.example.com"}}`
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":   typo,
+		"strict": true,
+	})
+	_, err := resourceElasticsearchOpenDistroDestination().Diff(nil, config, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown keys slak") {
+		t.Errorf("expected an error about the unknown key slak, got %v", err)
+	}
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":   future,
+		"strict": false,
+	})
+	diff, err := resourceElasticsearchOpenDistroDestination().Diff(nil, config, nil)
+	if err != nil {
+		t.Fatalf("expected unknown keys to be accepted without strict, got %s", err)
+	}
+	if diff == nil {
+		t.Fatalf("expected a diff for %s", future)
+	}
+
+	if unknown := unknownDestinationKeys(future); !reflect.DeepEqual(unknown, []string{"microsoft_teams"}) {
+		t.Errorf("expected microsoft_teams to be unknown, got %v", unknown)
+	}
+}
+
+func TestOpenDistroDestinationBodyFile(t *testing.T) {
+	slack := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"}}`
+	bodyFile := testTempFile(t, `{
+  "name": "my-destination",
+  "type": "slack",
+  "slack": {"url": "http://www.example.com"}
+}`)
+
+	var posted map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_alerting/destinations":
+			fmt.Fprint(w, `{"destinations": [], "totalDestinations": 0}`)
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/destinations/":
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprintf(w, `{"_id": "abc", "_version": 1, "destination": %s}`, slack)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body_file": bodyFile,
+	})
+	if err := resourceElasticsearchOpenDistroDestinationCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if posted["name"] != "my-destination" {
+		t.Errorf("expected the body of the file to be posted, got %v", posted)
+	}
+
+	// the body read from the file is compared with the state
+	state := &terraform.InstanceState{
+		ID: "abc",
+		Attributes: map[string]string{
+			"id":                      "abc",
+			"body":                    slack,
+			"body_file":               bodyFile,
+			"document_type":           "_doc",
+			"fail_on_existing":        "false",
+			"preserve_unknown_fields": "false",
+			"strict":                  "false",
+			"validate_on_plan":        "false",
+			"destination_id":          "abc",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body_file": bodyFile,
+	})
+	diff, err := resourceElasticsearchOpenDistroDestination().Diff(state, config, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for the unchanged file, got %v", diff)
+	}
+
+	renamed := testTempFile(t, `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.org"}}`)
+	state.Attributes["body_file"] = renamed
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body_file": renamed,
+	})
+	diff, err = resourceElasticsearchOpenDistroDestination().Diff(state, config, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["body"] == nil {
+		t.Errorf("expected a diff of the body for the changed file, got %v", diff)
+	}
+
+	invalid := testTempFile(t, `{"name":"my-destination",`)
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body_file": invalid,
+	})
+	if _, err := resourceElasticsearchOpenDistroDestination().Diff(nil, config, nil); err == nil || !strings.Contains(err.Error(), "invalid body of the destination") {
+		t.Errorf("expected an error about the invalid JSON, got %v", err)
+	}
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":      slack,
+		"body_file": bodyFile,
+	})
+	if _, errs := resourceElasticsearchOpenDistroDestination().Validate(config); len(errs) == 0 {
+		t.Error("expected body and body_file to conflict")
+	}
+}
+
+func TestOpenDistroDestinationValidateOnPlan(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":             `{"name":"my-destination","type":"sns","sns":{"topic_arn":"arn:aws:sns:us-east-1:123456789012:alerts"}}`,
+		"validate_on_plan": true,
+	})
+	_, err := resourceElasticsearchOpenDistroDestination().Diff(nil, config, nil)
+	if err == nil || !strings.Contains(err.Error(), "missing the required fields sns.role_arn") {
+		t.Errorf("expected an error about the missing role_arn, got %v", err)
+	}
+
+	cases := []struct {
+		body    string
+		missing []string
+	}{
+		{`{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"}}`, nil},
+		{`{"type":"slack","slack":{"url":""}}`, []string{"name", "slack.url"}},
+		{`{"name":"my-destination","type":"custom_webhook","custom_webhook":{"host":"example.com"}}`, nil},
+		{`{"name":"my-destination","type":"custom_webhook","custom_webhook":{"port":443}}`, []string{"custom_webhook.url or custom_webhook.host"}},
+		{`{"name":"my-destination","type":"email","email":{"email_account_id":"abc","recipients":[]}}`, []string{"email.recipients"}},
+	}
+	for _, c := range cases {
+		if missing := destinationMissingFields(c.body); !reflect.DeepEqual(missing, c.missing) {
+			t.Errorf("expected %v to be missing from %s, got %v", c.missing, c.body, missing)
+		}
+	}
+}
+
+func TestValidateDestinationType(t *testing.T) {
+	cases := []struct {
+		body  string
+		valid bool
+	}{
+		{`{"name":"d","type":"slack","slack":{"url":"http://www.example.com"}}`, true},
+		{`{"name":"d","type":"custom_webhook","custom_webhook":{"url":"http://www.example.com"}}`, true},
+		{`{"name":"d","type":"chime","chime":{"url":"http://www.example.com"}}`, true},
+		{`{"name":"d","type":"sns","sns":{"topic_arn":"arn:aws:sns:us-east-1:123456789012:topic","role_arn":"arn:aws:iam::123456789012:role/role"}}`, true},
+		{`{"name":"d","type":"email","email":{"email_account_id":"abc","recipients":[{"type":"email","email":"a@example.com"}]}}`, true},
+		{`{"name":"d","type":"slack"}`, false},
+		{`{"name":"d","type":"slack","slack":{}}`, false},
+		{`{"name":"d","type":"slack","custom_webhook":{"url":"http://www.example.com"}}`, false},
+		{`{"name":"d","type":"pager","pager":{"url":"http://www.example.com"}}`, false},
+		{`{"name":"d"}`, true},
+	}
+
+	for _, c := range cases {
+		_, errs := validateDestinationType(c.body, "body")
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("validateDestinationType(%s): expected valid %t, got errors %v", c.body, c.valid, errs)
+		}
+	}
+}
+
+func TestRetryUntilAlertingConfigIndexReady(t *testing.T) {
+	notReady := &elastic7.Error{Status: 404, Details: &elastic7.ErrorDetails{Type: "index_not_found_exception"}}
+
+	attempts := 0
+	err := retryUntilAlertingConfigIndexReady(10*time.Second, func() error {
+		attempts++
+		if attempts < 3 {
+			return notReady
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	err = retryUntilAlertingConfigIndexReady(10*time.Second, func() error {
+		attempts++
+		return errors.New("bad request")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("expected other errors to fail without retrying, got %d attempts and error %v", attempts, err)
+	}
+
+	err = retryUntilAlertingConfigIndexReady(time.Second, func() error {
+		return notReady
+	})
+	if err == nil || !strings.Contains(err.Error(), "never became available") {
+		t.Errorf("expected the alerting config index to never become available, got %v", err)
+	}
+}
+
+func TestDiffSuppressDestinationPreserveUnknownFields(t *testing.T) {
+	// the body read on import has a field missing from the configuration
+	imported := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com","channel":"#alerts"},"user":{"name":"admin"},"last_update_time":1609459200000}`
+	configured := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.com"}}`
+	changed := `{"name":"my-destination","type":"slack","slack":{"url":"http://www.example.org"}}`
+
+	cases := []struct {
+		preserve bool
+		new      string
+		suppress bool
+	}{
+		{true, configured, true},
+		{true, changed, false},
+		{false, configured, false},
+	}
+
+	for i, c := range cases {
+		d := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+			"body":                    c.new,
+			"preserve_unknown_fields": c.preserve,
+		})
+		if actual := diffSuppressDestination("body", imported, c.new, d); actual != c.suppress {
+			t.Errorf("case %d: expected suppress to be %t, got %t", i, c.suppress, actual)
+		}
+	}
+}
+
+func TestDiffSuppressDestinationScalars(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{"integer and float", `{"custom_webhook": {"port": 8080}}`, `{"custom_webhook": {"port": 8080.0}}`, true},
+		{"number as string", `{"custom_webhook": {"port": 8080}}`, `{"custom_webhook": {"port": "8080"}}`, true},
+		{"different numbers", `{"custom_webhook": {"port": 8080}}`, `{"custom_webhook": {"port": 8081}}`, false},
+		{"bool as string", `{"slack": {"enabled": true}}`, `{"slack": {"enabled": "true"}}`, true},
+		{"different bools", `{"slack": {"enabled": false}}`, `{"slack": {"enabled": "true"}}`, false},
+		{"null and missing", `{"custom_webhook": {"url": "http://www.example.com", "path": null}}`, `{"custom_webhook": {"url": "http://www.example.com"}}`, true},
+		{"null in a list", `{"email": {"recipients": [{"type": "email", "email": "a@example.com", "email_group_id": null}]}}`, `{"email": {"recipients": [{"type": "email", "email": "a@example.com"}]}}`, true},
+		{"null and value", `{"custom_webhook": {"path": null}}`, `{"custom_webhook": {"path": "/alerts"}}`, false},
+		{"string which isn't a number", `{"custom_webhook": {"path": "8080"}}`, `{"custom_webhook": {"path": "0808"}}`, false},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+			"body": c.new,
+		})
+		if actual := diffSuppressDestination("body", c.old, c.new, d); actual != c.suppress {
+			t.Errorf("%s: expected suppress to be %t, got %t", c.name, c.suppress, actual)
+		}
+	}
+}
+
+func TestOpenDistroDestinationCreateExisting(t *testing.T) {
+	var created bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_alerting/destinations":
+			if v := r.URL.Query().Get("searchString"); v != "my-destination" {
+				t.Errorf("expected to search for my-destination, got %q", v)
+			}
+			fmt.Fprint(w, `{
+  "destinations": [
+    {"id": "abc", "name": "my-destination", "type": "slack", "slack": {"url": "http://www.example.com"}},
+    {"id": "def", "name": "my-destination-2", "type": "slack", "slack": {"url": "http://www.example.com"}}
+  ],
+  "totalDestinations": 2
+}`)
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/destinations/":
+			// the existing destination is returned instead of a new one
+			created = true
+			fmt.Fprint(w, `{"_id": "abc", "_version": 2, "destination": {"name": "my-destination", "type": "slack", "slack": {"url": "http://www.example.com"}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	body := `{"name": "my-destination", "type": "slack", "slack": {"url": "http://www.example.com"}}`
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body":             body,
+		"fail_on_existing": true,
+	})
+	err := resourceElasticsearchOpenDistroDestinationCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "already exists with the ID abc") {
+		t.Errorf("expected an error about the existing destination, got %v", err)
+	}
+	if created {
+		t.Error("expected the destination not to be created")
+	}
+
+	// by default the existing destination is adopted
+	resourceData = schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body": body,
+	})
+	if err := resourceElasticsearchOpenDistroDestinationCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "abc" {
+		t.Errorf("expected the existing destination abc to be adopted, got %s", resourceData.Id())
+	}
+}
+
+func TestOpenDistroDestinationUnsupportedVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "5.6.0",
+	})
+
+	_, err := resourceElasticsearchOpenDistroGetDestination("abc", meta)
+	var unsupported *UnsupportedVersionError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected an UnsupportedVersionError, got %#v", err)
+	}
+	if unsupported.Resource != "destination" || unsupported.MinimumVersion != "v6" {
+		t.Errorf("expected the destination resource to require v6, got %+v", unsupported)
+	}
+	if err.Error() != "destination resource not implemented prior to Elastic v6" {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
+
+func TestOpenDistroDestinationImportByTypeAndName(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "GET" || r.URL.Path != "/_opendistro/_alerting/destinations" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		// the search string matches names containing it, and older versions
+		// ignore the type filter
+		switch r.URL.Query().Get("searchString") {
+		case "my-dest":
+			if v := r.URL.Query().Get("destinationType"); v != "slack" {
+				t.Errorf("expected to filter by the slack type, got %q", v)
+			}
+			fmt.Fprint(w, `{"destinations": [
+  {"id": "abc", "name": "my-dest", "type": "slack", "slack": {"url": "http://www.example.com"}},
+  {"id": "def", "name": "my-dest", "type": "chime", "chime": {"url": "http://www.example.com"}},
+  {"id": "ghi", "name": "my-dest-2", "type": "slack", "slack": {"url": "http://www.example.com"}}
+], "totalDestinations": 3}`)
+		case "twice":
+			fmt.Fprint(w, `{"destinations": [
+  {"id": "abc", "name": "twice", "type": "slack", "slack": {"url": "http://www.example.com"}},
+  {"id": "def", "name": "twice", "type": "slack", "slack": {"url": "http://www.example.org"}}
+], "totalDestinations": 2}`)
+		default:
+			fmt.Fprint(w, `{"destinations": [], "totalDestinations": 0}`)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	importID := func(id string) (string, error) {
+		resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{})
+		resourceData.SetId(id)
+		imported, err := resourceElasticsearchOpenDistroDestinationImport(resourceData, meta)
+		if err != nil {
+			return "", err
+		}
+		return imported[0].Id(), nil
+	}
+
+	if id, err := importID("slack/my-dest"); err != nil || id != "abc" {
+		t.Errorf("expected slack/my-dest to resolve to abc, got %q and error %v", id, err)
+	}
+	// IDs are imported as is
+	if id, err := importID("abc"); err != nil || id != "abc" {
+		t.Errorf("expected the ID abc to be imported as is, got %q and error %v", id, err)
+	}
+	if _, err := importID("slack/twice"); err == nil || !strings.Contains(err.Error(), "2 slack destinations are named \"twice\"") {
+		t.Errorf("expected an error about the ambiguous name, got %v", err)
+	}
+	if _, err := importID("slack/missing"); err == nil || !strings.Contains(err.Error(), "no slack destination named \"missing\" found") {
+		t.Errorf("expected an error about the missing destination, got %v", err)
+	}
+}
+
+func TestOpenDistroDestinationReadPaged(t *testing.T) {
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/.opendistro-alerting-config/_doc/"):
+			// the alerting config index is protected as a system index
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"type": "security_exception", "reason": "no permissions for [indices:data/read/get]"}, "status": 403}`)
+		case r.URL.Path == "/_opendistro/_alerting/destinations":
+			startIndex := r.URL.Query().Get("startIndex")
+			pages = append(pages, startIndex)
+			var destinations []string
+			switch startIndex {
+			case "0":
+				for i := 0; i < defaultDestinationsPageSize; i++ {
+					destinations = append(destinations, fmt.Sprintf(`{"id": "other-%d", "name": "other-%d", "type": "slack", "slack": {"url": "http://www.example.com"}}`, i, i))
+				}
+			case "100":
+				destinations = append(destinations, `{"id": "target", "name": "target", "type": "slack", "slack": {"url": "http://www.example.org"}}`)
+			}
+			fmt.Fprintf(w, `{"destinations": [%s], "totalDestinations": %d}`, strings.Join(destinations, ","), defaultDestinationsPageSize+1)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	res, err := resourceElasticsearchOpenDistroGetDestination("target", meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(pages, []string{"0", "100"}) {
+		t.Errorf("expected the destinations to be listed from startIndex 0 and 100, got %v", pages)
+	}
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(res), &destination); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if destination["name"] != "target" {
+		t.Errorf("expected the destination on the second page, got %s", res)
+	}
+
+	// a destination missing from all pages is reported as the original error
+	pages = nil
+	_, err = resourceElasticsearchOpenDistroGetDestination("missing", meta)
+	if err == nil {
+		t.Error("expected an error for a missing destination")
+	}
+}
+
+func TestOpenDistroDestinationReadPageSize(t *testing.T) {
+	var sizes, pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/.opendistro-alerting-config/_doc/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"_index": ".opendistro-alerting-config", "_id": "target", "found": false}`)
+		case r.URL.Path == "/_opendistro/_alerting/destinations":
+			sizes = append(sizes, r.URL.Query().Get("size"))
+			startIndex := r.URL.Query().Get("startIndex")
+			pages = append(pages, startIndex)
+			destinations := `{"id": "other-1", "name": "other-1", "type": "slack", "slack": {"url": "http://www.example.com"}},
+  {"id": "other-2", "name": "other-2", "type": "slack", "slack": {"url": "http://www.example.com"}}`
+			if startIndex == "2" {
+				destinations = `{"id": "target", "name": "target", "type": "slack", "slack": {"url": "http://www.example.org"}}`
+			}
+			fmt.Fprintf(w, `{"destinations": [%s], "totalDestinations": 3}`, destinations)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"destinations_page_size": 2,
+	})
+
+	if _, err := resourceElasticsearchOpenDistroGetDestination("target", meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(sizes, []string{"2", "2"}) {
+		t.Errorf("expected pages of size 2 to be requested, got %v", sizes)
+	}
+	if !reflect.DeepEqual(pages, []string{"0", "2"}) {
+		t.Errorf("expected the destinations to be listed from startIndex 0 and 2, got %v", pages)
+	}
+
+	validate := Provider().(*schema.Provider).Schema["destinations_page_size"].ValidateFunc
+	for _, size := range []int{0, 10001} {
+		if _, errs := validate(size, "destinations_page_size"); len(errs) == 0 {
+			t.Errorf("expected a page size of %d to be invalid", size)
+		}
+	}
+}
+
+func TestOpenDistroDestinationReadSNS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.opendistro-alerting-config/_doc/abc" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "_index": ".opendistro-alerting-config",
+  "_type": "_doc",
+  "_id": "abc",
+  "_version": 1,
+  "found": true,
+  "_source": {
+    "destination": {
+      "id": "abc",
+      "schema_version": 3,
+      "last_update_time": 1609459200000,
+      "name": "sns",
+      "type": "sns",
+      "sns": {
+        "role_arn": "arn:aws:iam::123456789012:role/alerting",
+        "topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+        "region": "eu-west-1"
+      }
+    }
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	config := `{
+  "name": "sns",
+  "type": "sns",
+  "sns": {
+    "region": "eu-west-1",
+    "topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+    "role_arn": "arn:aws:iam::123456789012:role/alerting"
+  }
+}`
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body": config,
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroDestinationRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	read := resourceData.Get("body").(string)
+	var destination struct {
+		SNS map[string]interface{} `json:"sns"`
+	}
+	if err := json.Unmarshal([]byte(read), &destination); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]interface{}{
+		"role_arn":  "arn:aws:iam::123456789012:role/alerting",
+		"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+		"region":    "eu-west-1",
+	}
+	if !reflect.DeepEqual(destination.SNS, expected) {
+		t.Errorf("expected the sns object to be read back as %v, got %v", expected, destination.SNS)
+	}
+
+	if !diffSuppressDestination("body", read, config, resourceData) {
+		t.Errorf("expected no diff between the read body %s and the configuration", read)
+	}
+	if diffSuppressDestination("body", read, strings.Replace(config, `"eu-west-1",`, `"us-east-1",`, 1), resourceData) {
+		t.Error("expected a diff when the region changes")
+	}
+}
+
+func TestOpenDistroDestinationReadDocumentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/.opendistro-alerting-config/destination/abc":
+			fmt.Fprint(w, `{
+  "_index": ".opendistro-alerting-config",
+  "_type": "destination",
+  "_id": "abc",
+  "_version": 1,
+  "found": true,
+  "_source": {"destination": {"name": "my-destination", "type": "slack", "slack": {"url": "http://www.example.com"}}}
+}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "6.8.0",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body":          `{"name": "my-destination", "type": "slack", "slack": {"url": "http://www.example.com"}}`,
+		"document_type": "destination",
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroDestinationRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "abc" {
+		t.Fatal("expected the destination to be found with the custom type")
+	}
+
+	var destination map[string]interface{}
+	if err := json.Unmarshal([]byte(resourceData.Get("body").(string)), &destination); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if destination["name"] != "my-destination" {
+		t.Errorf("expected the destination to be read, got %s", resourceData.Get("body"))
+	}
+}
+
+func TestOpenDistroDestinationAuditAttributes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.opendistro-alerting-config/_doc/abc" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "_index": ".opendistro-alerting-config",
+  "_type": "_doc",
+  "_id": "abc",
+  "_version": 1,
+  "found": true,
+  "_source": {
+    "destination": {
+      "id": "abc",
+      "schema_version": 3,
+      "last_update_time": 1609459200000,
+      "user": {"name": "alice", "backend_roles": ["ops"], "roles": ["alerting_full_access"], "custom_attribute_names": []},
+      "name": "oncall",
+      "type": "slack",
+      "slack": {"url": "https://hooks.slack.com/services/T000/B000/XXXX"}
+    }
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	config := `{"name": "oncall", "type": "slack", "slack": {"url": "https://hooks.slack.com/services/T000/B000/XXXX"}}`
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body": config,
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroDestinationRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if createdBy := resourceData.Get("created_by").(string); createdBy != "alice" {
+		t.Errorf("expected created_by alice, got %q", createdBy)
+	}
+	if updated := resourceData.Get("last_update_time").(string); updated != "2021-01-01T00:00:00Z" {
+		t.Errorf("expected last_update_time 2021-01-01T00:00:00Z, got %q", updated)
+	}
+	if body := resourceData.Get("body").(string); !diffSuppressDestination("body", body, config, resourceData) {
+		t.Errorf("expected the audit fields not to be a diff, got %s", body)
+	}
+}
+
+func TestOpenDistroDestinationOwner(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "_index": ".opendistro-alerting-config",
+  "_id": "abc",
+  "found": true,
+  "_source": {
+    "destination": {
+      "reserved": true,
+      "user": {"name": "bob", "backend_roles": ["platform"], "roles": ["all_access"]},
+      "name": "platform",
+      "type": "chime",
+      "chime": {"url": "https://hooks.chime.aws/incomingwebhooks/XXXX"}
+    }
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	config := `{"name": "platform", "type": "chime", "chime": {"url": "https://hooks.chime.aws/incomingwebhooks/XXXX"}}`
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationSchema, map[string]interface{}{
+		"body": config,
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroDestinationRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if owner := resourceData.Get("owner").(string); owner != "bob" {
+		t.Errorf("expected the owner bob, got %q", owner)
+	}
+	if !resourceData.Get("reserved").(bool) {
+		t.Error("expected the destination to be reserved")
+	}
+	if body := resourceData.Get("body").(string); !diffSuppressDestination("body", body, config, resourceData) {
+		t.Errorf("expected the owner and reserved flag not to be a diff, got %s", body)
+	}
+}
+
 func TestAccElasticsearchOpenDistroDestination_importBasic(t *testing.T) {
 	provider := Provider().(*schema.Provider)
 	err := provider.Configure(&terraform.ResourceConfig{})
@@ -108,7 +916,7 @@ func testCheckElasticsearchOpenDistroDestinationExists(name string) resource.Tes
 		meta := testAccOpendistroProvider.Meta()
 
 		var err error
-		_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+		_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.Attributes["destination_id"], meta.(*ProviderConf))
 
 		if err != nil {
 			return err
@@ -133,9 +941,9 @@ func testCheckElasticsearchOpenDistroDestinationDestroy(s *terraform.State) erro
 		}
 		switch esClient.(type) {
 		case *elastic7.Client:
-			_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.Attributes["destination_id"], meta.(*ProviderConf))
 		case *elastic6.Client:
-			_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.ID, meta.(*ProviderConf))
+			_, err = resourceElasticsearchOpenDistroGetDestination(rs.Primary.Attributes["destination_id"], meta.(*ProviderConf))
 		default:
 		}
 
@@ -162,3 +970,17 @@ resource "elasticsearch_opendistro_destination" "test_destination" {
 EOF
 }
 `
+
+var testAccElasticsearchOpenDistroDestinationWebhook = `
+resource "elasticsearch_opendistro_destination" "test_destination" {
+  body = <<EOF
+{
+  "name": "my-destination",
+  "type": "custom_webhook",
+  "custom_webhook": {
+    "url": "http://www.example.com"
+  }
+}
+EOF
+}
+`
@@ -0,0 +1,227 @@
+package es
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+var openDistroDestinationsSchema = map[string]*schema.Schema{
+	"destination": {
+		Type:        schema.TypeList,
+		Required:    true,
+		MinItems:    1,
+		Description: "The destinations, created in order. When creating one of them fails, the destinations created before it are deleted again.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"body": {
+					Type:             schema.TypeString,
+					Required:         true,
+					DiffSuppressFunc: diffSuppressDestinations,
+					ValidateFunc:     validation.All(validation.StringIsJSON, validateDestinationType),
+					StateFunc: func(v interface{}) string {
+						json, _ := structure.NormalizeJsonString(v)
+						return json
+					},
+					Description: "The JSON body of the destination.",
+				},
+				"destination_id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The ID of the destination in the cluster, to reference from monitors.",
+				},
+			},
+		},
+	},
+}
+
+func resourceElasticsearchOpenDistroDestinations() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides a set of Elasticsearch OpenDistro destinations which are created together. As the destination API has no transactions, creating the set rolls back on a best-effort basis: the destinations already created are deleted when creating one of them fails.",
+		Create:      resourceElasticsearchOpenDistroDestinationsCreate,
+		Read:        resourceElasticsearchOpenDistroDestinationsRead,
+		Update:      resourceElasticsearchOpenDistroDestinationsUpdate,
+		Delete:      resourceElasticsearchOpenDistroDestinationsDelete,
+		Schema:      openDistroDestinationsSchema,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+		},
+	}
+}
+
+func resourceElasticsearchOpenDistroDestinationsCreate(d *schema.ResourceData, m interface{}) error {
+	destinations := d.Get("destination").([]interface{})
+	bodies := make([]string, len(destinations))
+	for i, destination := range destinations {
+		bodies[i] = destination.(map[string]interface{})["body"].(string)
+	}
+
+	created, err := resourceElasticsearchOpenDistroPostDestinations(bodies, d.Timeout(schema.TimeoutCreate), m)
+	if err != nil {
+		if len(created) > 0 {
+			// the destinations which couldn't be deleted are kept in the
+			// state, which is tainted, so they are deleted by the next apply
+			d.SetId(resource.UniqueId())
+			if setErr := d.Set("destination", created); setErr != nil {
+				log.Printf("[WARN] Failed to set the destinations left by the rollback: %+v", setErr)
+			}
+		}
+		return err
+	}
+
+	d.SetId(resource.UniqueId())
+	return resourceElasticsearchOpenDistroDestinationsRead(d, m)
+}
+
+// resourceElasticsearchOpenDistroPostDestinations creates the destinations in
+// order. When one fails, the destinations created before it are deleted, and
+// the ones which couldn't be deleted are returned with the error.
+func resourceElasticsearchOpenDistroPostDestinations(bodies []string, timeout time.Duration, m interface{}) ([]interface{}, error) {
+	var created []interface{}
+	for i, body := range bodies {
+		res, err := resourceElasticsearchOpenDistroPostDestinationBody(body, timeout, m)
+		if err == nil {
+			created = append(created, map[string]interface{}{
+				"body":           body,
+				"destination_id": res.ID,
+			})
+			continue
+		}
+
+		err = fmt.Errorf("error creating destination %d (%s): %+v", i, destinationName(body), err)
+		left, rollbackErr := resourceElasticsearchOpenDistroDeleteDestinations(created, m)
+		if rollbackErr != nil {
+			return left, fmt.Errorf("%+v; rolling back failed, the destinations which couldn't be deleted are kept in the state: %+v", err, rollbackErr)
+		}
+		if len(created) > 0 {
+			log.Printf("[INFO] Deleted the %d destinations created before destination %d", len(created), i)
+		}
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// resourceElasticsearchOpenDistroDeleteDestinations deletes the destinations
+// in reverse order, returning the ones which couldn't be deleted.
+func resourceElasticsearchOpenDistroDeleteDestinations(destinations []interface{}, m interface{}) ([]interface{}, error) {
+	// the flavor of the cluster is known once the client is created
+	if _, err := getClient(m.(*ProviderConf)); err != nil {
+		return destinations, err
+	}
+	flavor := m.(*ProviderConf).flavor
+
+	var left []interface{}
+	var errs []string
+	for i := len(destinations) - 1; i >= 0; i-- {
+		id := destinations[i].(map[string]interface{})["destination_id"].(string)
+		err := resourceElasticsearchOpenDistroDeleteDestination(formatDestinationID(flavor, id), m)
+		if err != nil && !elastic6.IsNotFound(err) && !elastic7.IsNotFound(err) {
+			left = append([]interface{}{destinations[i]}, left...)
+			errs = append(errs, fmt.Sprintf("%s: %+v", id, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return left, fmt.Errorf("error deleting destinations %s", strings.Join(errs, ", "))
+	}
+	return nil, nil
+}
+
+func resourceElasticsearchOpenDistroDestinationsRead(d *schema.ResourceData, m interface{}) error {
+	var destinations []interface{}
+	for _, destination := range d.Get("destination").([]interface{}) {
+		id := destination.(map[string]interface{})["destination_id"].(string)
+		body, err := resourceElasticsearchOpenDistroGetDestination(id, m)
+		if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Destination (%s) not found, removing from state", id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		destinations = append(destinations, map[string]interface{}{
+			"body":           body,
+			"destination_id": id,
+		})
+	}
+
+	if len(destinations) == 0 {
+		log.Printf("[WARN] Destinations (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("destination", destinations)
+	return ds.err
+}
+
+// resourceElasticsearchOpenDistroDestinationsUpdate updates the destinations
+// in place by position, creates the added ones, rolling them back when one
+// fails, and deletes the removed ones.
+func resourceElasticsearchOpenDistroDestinationsUpdate(d *schema.ResourceData, m interface{}) error {
+	o, n := d.GetChange("destination")
+	oldDestinations, newDestinations := o.([]interface{}), n.([]interface{})
+	if _, err := getClient(m.(*ProviderConf)); err != nil {
+		return err
+	}
+	flavor := m.(*ProviderConf).flavor
+
+	var destinations []interface{}
+	var added []string
+	for i, destination := range newDestinations {
+		body := destination.(map[string]interface{})["body"].(string)
+		if i >= len(oldDestinations) {
+			added = append(added, body)
+			continue
+		}
+
+		old := oldDestinations[i].(map[string]interface{})
+		id := old["destination_id"].(string)
+		if !equivalentDestinations(old["body"].(string), body, false) {
+			if _, err := resourceElasticsearchOpenDistroPutDestinationBody(formatDestinationID(flavor, id), body, m); err != nil {
+				return fmt.Errorf("error updating destination %d (%s): %+v", i, id, err)
+			}
+		}
+		destinations = append(destinations, map[string]interface{}{
+			"body":           body,
+			"destination_id": id,
+		})
+	}
+
+	created, err := resourceElasticsearchOpenDistroPostDestinations(added, d.Timeout(schema.TimeoutUpdate), m)
+	destinations = append(destinations, created...)
+	if err != nil {
+		if setErr := d.Set("destination", destinations); setErr != nil {
+			log.Printf("[WARN] Failed to set the destinations left by the rollback: %+v", setErr)
+		}
+		return err
+	}
+
+	if len(oldDestinations) > len(newDestinations) {
+		if _, err := resourceElasticsearchOpenDistroDeleteDestinations(oldDestinations[len(newDestinations):], m); err != nil {
+			return err
+		}
+	}
+
+	if err := d.Set("destination", destinations); err != nil {
+		return err
+	}
+	return resourceElasticsearchOpenDistroDestinationsRead(d, m)
+}
+
+func resourceElasticsearchOpenDistroDestinationsDelete(d *schema.ResourceData, m interface{}) error {
+	_, err := resourceElasticsearchOpenDistroDeleteDestinations(d.Get("destination").([]interface{}), m)
+	return err
+}
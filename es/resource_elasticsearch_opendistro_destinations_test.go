@@ -0,0 +1,97 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroDestinationsCreateRollback(t *testing.T) {
+	var deleted []string
+	failDelete := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/destinations/":
+			var destination map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&destination); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			name := destination["name"].(string)
+			if name == "broken" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": {"type": "illegal_argument_exception", "reason": "invalid destination"}, "status": 400}`)
+				return
+			}
+			fmt.Fprintf(w, `{"_id": "%s-id", "_version": 1, "destination": %s}`, name, mustMarshal(t, destination))
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/_opendistro/_alerting/destinations/"):
+			id := strings.TrimPrefix(r.URL.Path, "/_opendistro/_alerting/destinations/")
+			if id == failDelete {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"error": {"type": "exception", "reason": "failed"}, "status": 500}`)
+				return
+			}
+			deleted = append(deleted, id)
+			fmt.Fprint(w, `{"result": "deleted"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	destinations := []interface{}{
+		map[string]interface{}{"body": `{"name": "first", "type": "slack", "slack": {"url": "http://www.example.com"}}`},
+		map[string]interface{}{"body": `{"name": "second", "type": "slack", "slack": {"url": "http://www.example.com"}}`},
+		map[string]interface{}{"body": `{"name": "broken", "type": "slack", "slack": {"url": "http://www.example.com"}}`},
+	}
+	resourceData := schema.TestResourceDataRaw(t, openDistroDestinationsSchema, map[string]interface{}{
+		"destination": destinations,
+	})
+	err := resourceElasticsearchOpenDistroDestinationsCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "error creating destination 2 (broken)") {
+		t.Errorf("expected an error creating the third destination, got %v", err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"second-id", "first-id"}) {
+		t.Errorf("expected the created destinations to be deleted in reverse order, got %v", deleted)
+	}
+	if resourceData.Id() != "" {
+		t.Errorf("expected nothing to be kept in the state, got ID %s", resourceData.Id())
+	}
+
+	// destinations which can't be deleted by the rollback are kept in the state
+	deleted = nil
+	failDelete = "first-id"
+	resourceData = schema.TestResourceDataRaw(t, openDistroDestinationsSchema, map[string]interface{}{
+		"destination": destinations,
+	})
+	err = resourceElasticsearchOpenDistroDestinationsCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "rolling back failed") {
+		t.Errorf("expected an error about the rollback, got %v", err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"second-id"}) {
+		t.Errorf("expected the second destination to be deleted, got %v", deleted)
+	}
+	if resourceData.Id() == "" {
+		t.Fatal("expected the destinations left by the rollback to be kept in the state")
+	}
+	left := resourceData.Get("destination").([]interface{})
+	if len(left) != 1 || left[0].(map[string]interface{})["destination_id"] != "first-id" {
+		t.Errorf("expected only the first destination to be kept in the state, got %v", left)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return string(b)
+}
@@ -3,7 +3,6 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -50,11 +49,26 @@ func resourceElasticsearchOpenDistroISMPolicy() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceElasticsearchOpenDistroISMPolicyImport,
 		},
 	}
 }
 
+// resourceElasticsearchOpenDistroISMPolicyImport reads the policy when it is
+// imported, storing its _seq_no and _primary_term, which the first update
+// sends as if_seq_no and if_primary_term.
+func resourceElasticsearchOpenDistroISMPolicyImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	policyID := d.Id()
+	if err := resourceElasticsearchOpenDistroISMPolicyRead(d, m); err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("policy %q not found", policyID)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceElasticsearchOpenDistroISMPolicyCreate(d *schema.ResourceData, m interface{}) error {
 	if _, err := resourceElasticsearchPutOpenDistroISMPolicy(d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroPolicy: %+v", err)
@@ -133,7 +147,7 @@ func resourceElasticsearchOpenDistroISMPolicyDelete(d *schema.ResourceData, m in
 			return fmt.Errorf("error deleting policy: %+v : %+v", path, err)
 		}
 	default:
-		err = errors.New("policy resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "policy", MinimumVersion: "v7"}
 	}
 
 	return err
@@ -164,12 +178,16 @@ func resourceElasticsearchGetOpenDistroISMPolicy(policyID string, m interface{})
 			Path:   path,
 		})
 
+		// a missing policy is reported as is, to remove it from the state
+		if elastic7.IsNotFound(err) {
+			return *response, err
+		}
 		if err != nil {
 			return *response, fmt.Errorf("error getting policy: %+v : %+v", path, err)
 		}
 		body = &res.Body
 	default:
-		err = errors.New("policy resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "policy", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -224,7 +242,7 @@ func resourceElasticsearchPutOpenDistroISMPolicy(d *schema.ResourceData, m inter
 		}
 		body = &res.Body
 	default:
-		err = errors.New("policy resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "policy", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
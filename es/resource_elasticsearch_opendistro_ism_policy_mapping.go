@@ -3,9 +3,9 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"sort"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/olivere/elastic/uritemplates"
@@ -20,6 +20,8 @@ func resourceElasticsearchOpenDistroISMPolicyMapping() *schema.Resource {
 		Read:        resourceElasticsearchOpenDistroISMPolicyMappingRead,
 		Update:      resourceElasticsearchOpenDistroISMPolicyMappingUpdate,
 		Delete:      resourceElasticsearchOpenDistroISMPolicyMappingDelete,
+		// attach the policy to indices matching the pattern created since
+		CustomizeDiff: resourceElasticsearchOpenDistroISMPolicyMappingCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"policy_id": {
 				Type:        schema.TypeString,
@@ -29,7 +31,8 @@ func resourceElasticsearchOpenDistroISMPolicyMapping() *schema.Resource {
 			"indexes": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "Name of the index to apply the policy to. You can use an index pattern to update multiple indices at once.",
+				ForceNew:    true,
+				Description: "Name of the index to apply the policy to. You can use an index pattern to update multiple indices at once. Indices matching the pattern which are created later are attached to the policy by the next apply.",
 			},
 			"state": {
 				Type:        schema.TypeString,
@@ -50,10 +53,11 @@ func resourceElasticsearchOpenDistroISMPolicyMapping() *schema.Resource {
 				Description: "",
 			},
 			"managed_indexes": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Computed: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The indices the policy is attached to.",
 			},
 		},
 		Importer: &schema.ResourceImporter{
@@ -71,21 +75,12 @@ func resourceElasticsearchOpenDistroISMPolicyMappingCreate(d *schema.ResourceDat
 }
 
 func resourceElasticsearchOpenDistroISMPolicyMappingRead(d *schema.ResourceData, m interface{}) error {
-	indexesList, err := resourceElasticsearchGetOpendistroPolicyMapping(d, m)
-	concernIndexes := []string{}
-	policyName := d.Get("policy_id").(string)
-
+	indexesList, err := resourceElasticsearchGetOpendistroPolicyMapping(d.Get("indexes").(string), m)
 	if err != nil {
 		return err
 	}
 
-	// If there is no managed indexes we can remove that resource
-	for indexName, parameters := range indexesList {
-		if parameters.(map[string]interface{})["index.opendistro.index_state_management.policy_id"] == policyName {
-			concernIndexes = append(concernIndexes, indexName)
-		}
-	}
-
+	concernIndexes, _ := policyMappingIndexes(indexesList, d.Get("policy_id").(string))
 	log.Printf("[INFO] %+v", concernIndexes)
 
 	if len(concernIndexes) == 0 {
@@ -99,18 +94,94 @@ func resourceElasticsearchOpenDistroISMPolicyMappingRead(d *schema.ResourceData,
 }
 
 func resourceElasticsearchOpenDistroISMPolicyMappingUpdate(d *schema.ResourceData, m interface{}) error {
-	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "update_policy"); err != nil {
-		if elastic7.IsNotFound(err) {
-			log.Printf("[WARN] OpendistroPolicyMapping (%s) not found, removing from state", d.Id())
-			d.SetId("")
-			return nil
+	if d.HasChange("policy_id") || d.HasChange("state") || d.HasChange("include") || d.HasChange("is_safe") {
+		if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "update_policy"); err != nil {
+			if elastic7.IsNotFound(err) {
+				log.Printf("[WARN] OpendistroPolicyMapping (%s) not found, removing from state", d.Id())
+				d.SetId("")
+				return nil
+			}
+			return err
 		}
+	}
+
+	// attach the policy to the indices created since, the indices which are
+	// already managed are reported as failures and left unchanged
+	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "add"); err != nil {
 		return err
 	}
 
 	return resourceElasticsearchOpenDistroISMPolicyMappingRead(d, m)
 }
 
+// resourceElasticsearchOpenDistroISMPolicyMappingCustomizeDiff plans an
+// update when indices matching the pattern aren't managed by any policy.
+func resourceElasticsearchOpenDistroISMPolicyMappingCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || d.HasChange("indexes") {
+		return nil
+	}
+
+	indexesList, err := resourceElasticsearchGetOpendistroPolicyMapping(d.Get("indexes").(string), m)
+	if err != nil {
+		log.Printf("[WARN] Unable to check for unmanaged indices matching %s: %+v", d.Get("indexes"), err)
+		return nil
+	}
+
+	if _, unmanaged := policyMappingIndexes(indexesList, d.Get("policy_id").(string)); len(unmanaged) > 0 {
+		log.Printf("[INFO] Indices %v match %s but aren't managed", unmanaged, d.Get("indexes"))
+		return d.SetNewComputed("managed_indexes")
+	}
+
+	return nil
+}
+
+// policyMappingIndexes returns the indices of an explain response which are
+// managed by the policy, and those which aren't managed by any policy.
+func policyMappingIndexes(explained map[string]interface{}, policyID string) (managed []string, unmanaged []string) {
+	managed = []string{}
+	for indexName, parameters := range explained {
+		// the response also contains the total number of managed indices
+		index, ok := parameters.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var indexPolicyID interface{}
+		for _, key := range []string{
+			"index.plugins.index_state_management.policy_id",
+			"index.opendistro.index_state_management.policy_id",
+			"policy_id",
+		} {
+			if v, ok := index[key]; ok && v != nil {
+				indexPolicyID = v
+				break
+			}
+		}
+
+		switch indexPolicyID {
+		case policyID:
+			managed = append(managed, indexName)
+		case nil:
+			unmanaged = append(unmanaged, indexName)
+		}
+	}
+	sort.Strings(managed)
+	sort.Strings(unmanaged)
+
+	return managed, unmanaged
+}
+
+// ismPath returns the path of an ISM API, which is prefixed with _plugins
+// on OpenSearch.
+func ismPath(conf *ProviderConf, template string, values map[string]string) (string, error) {
+	prefix := "/_opendistro/_ism"
+	if conf.flavor == OpenSearch {
+		prefix = "/_plugins/_ism"
+	}
+
+	return uritemplates.Expand(prefix+template, values)
+}
+
 func resourceElasticsearchOpenDistroISMPolicyMappingDelete(d *schema.ResourceData, m interface{}) error {
 	if _, err := resourceElasticsearchPostOpendistroPolicyMapping(d, m, "remove"); err != nil {
 		return err
@@ -154,7 +225,12 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 
 	}
 
-	path, err := uritemplates.Expand("/_opendistro/_ism/{action}/{indexes}", map[string]string{
+	// the flavor of the cluster is known once the client is created
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	path, err := ismPath(m.(*ProviderConf), "/{action}/{indexes}", map[string]string{
 		"indexes": d.Get("indexes").(string),
 		"action":  action,
 	})
@@ -163,10 +239,6 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 	}
 
 	var body *json.RawMessage
-	esClient, err := getClient(m.(*ProviderConf))
-	if err != nil {
-		return nil, err
-	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
@@ -180,7 +252,7 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 		}
 		body = &res.Body
 	default:
-		err = errors.New("policy resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "policy", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -194,21 +266,21 @@ func resourceElasticsearchPostOpendistroPolicyMapping(d *schema.ResourceData, m
 	return response, nil
 }
 
-func resourceElasticsearchGetOpendistroPolicyMapping(d *schema.ResourceData, m interface{}) (map[string]interface{}, error) {
+func resourceElasticsearchGetOpendistroPolicyMapping(indexes string, m interface{}) (map[string]interface{}, error) {
 
 	response := new(map[string]interface{})
-	path, err := uritemplates.Expand("/_opendistro/_ism/explain/{indexes}", map[string]string{
-		"indexes": d.Get("indexes").(string),
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	path, err := ismPath(m.(*ProviderConf), "/explain/{indexes}", map[string]string{
+		"indexes": indexes,
 	})
 	if err != nil {
 		return *response, fmt.Errorf("error building URL path for policy mapping: %+v", err)
 	}
 
 	var body *json.RawMessage
-	esClient, err := getClient(m.(*ProviderConf))
-	if err != nil {
-		return nil, err
-	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
@@ -221,7 +293,7 @@ func resourceElasticsearchGetOpendistroPolicyMapping(d *schema.ResourceData, m i
 		}
 		body = &res.Body
 	default:
-		err = errors.New("policy mapping resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "policy mapping", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -0,0 +1,94 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenDistroISMPolicyMappingAttach(t *testing.T) {
+	var requests []string
+	var attached map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_plugins/_ism/add/logs-000001":
+			if err := json.NewDecoder(r.Body).Decode(&attached); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"updated_indices": 1, "failures": false, "failed_indices": []}`)
+		case "/_plugins/_ism/explain/logs-000001":
+			fmt.Fprint(w, `{
+  "logs-000001": {
+    "index.plugins.index_state_management.policy_id": "test_policy",
+    "index.opendistro.index_state_management.policy_id": "test_policy",
+    "index": "logs-000001",
+    "policy_id": "test_policy"
+  },
+  "total_managed_indices": 1
+}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	if _, err := getClient(meta.(*ProviderConf)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	meta.(*ProviderConf).flavor = OpenSearch
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroISMPolicyMapping().Schema, map[string]interface{}{
+		"policy_id": "test_policy",
+		"indexes":   "logs-000001",
+	})
+	if err := resourceElasticsearchOpenDistroISMPolicyMappingCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"POST /_plugins/_ism/add/logs-000001",
+		"GET /_plugins/_ism/explain/logs-000001",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+	if attached["policy_id"] != "test_policy" {
+		t.Errorf("expected the policy to be attached, got %v", attached)
+	}
+	if resourceData.Id() != "logs-000001" {
+		t.Errorf("expected the ID to be the indexes, got %s", resourceData.Id())
+	}
+	managed := resourceData.Get("managed_indexes").(*schema.Set).List()
+	if len(managed) != 1 || managed[0] != "logs-000001" {
+		t.Errorf("expected logs-000001 to be managed, got %v", managed)
+	}
+}
+
+func TestPolicyMappingIndexes(t *testing.T) {
+	var explained map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+  "logs-000001": {"index.opendistro.index_state_management.policy_id": "test_policy"},
+  "logs-000002": {"index.plugins.index_state_management.policy_id": "test_policy"},
+  "logs-000003": {"index.plugins.index_state_management.policy_id": "other_policy"},
+  "logs-000004": {"index.plugins.index_state_management.policy_id": null},
+  "total_managed_indices": 3
+}`), &explained); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	managed, unmanaged := policyMappingIndexes(explained, "test_policy")
+	if !reflect.DeepEqual(managed, []string{"logs-000001", "logs-000002"}) {
+		t.Errorf("unexpected managed indices %v", managed)
+	}
+	if !reflect.DeepEqual(unmanaged, []string{"logs-000004"}) {
+		t.Errorf("unexpected unmanaged indices %v", unmanaged)
+	}
+}
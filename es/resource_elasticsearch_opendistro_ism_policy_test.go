@@ -1,7 +1,11 @@
 package es
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -60,6 +64,255 @@ func TestAccElasticsearchOpenDistroISMPolicy(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchOpenDistroISMPolicy_importBasic(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	esClient, err := getClient(provider.Meta().(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	_, allowed := esClient.(*elastic7.Client)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("OpenDistroISMPolicies only supported on ES 7.")
+			}
+		},
+		Providers:    testAccOpendistroProviders,
+		CheckDestroy: testCheckElasticsearchOpenDistroISMPolicyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchOpenDistroISMPolicy,
+			},
+			{
+				ResourceName:      "elasticsearch_opendistro_ism_policy.test_policy",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestOpenDistroISMPolicyImport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_opendistro/_ism/policies/test_policy" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "_id": "test_policy",
+  "_version": 1,
+  "_seq_no": 7,
+  "_primary_term": 1,
+  "policy": {
+    "policy_id": "test_policy",
+    "description": "ingesting logs",
+    "last_updated_time": 1609459200000,
+    "schema_version": 12,
+    "error_notification": null,
+    "default_state": "ingest",
+    "states": [{
+      "name": "ingest",
+      "actions": [{"retry": {"count": 3, "backoff": "exponential", "delay": "1m"}, "rollover": {"min_doc_count": 5}}],
+      "transitions": [{"state_name": "delete"}]
+    }, {
+      "name": "delete",
+      "actions": [{"retry": {"count": 5, "backoff": "constant", "delay": "1h"}, "delete": {}}],
+      "transitions": []
+    }],
+    "ism_template": null
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroISMPolicy().Schema, map[string]interface{}{})
+	resourceData.SetId("test_policy")
+	meta := testProviderMeta(t, ts.URL)
+	imported, err := resourceElasticsearchOpenDistroISMPolicy().Importer.State(resourceData, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := resourceElasticsearchOpenDistroISMPolicyRead(imported[0], meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if policyID := imported[0].Get("policy_id"); policyID != "test_policy" {
+		t.Errorf("expected the policy_id to be imported, got %v", policyID)
+	}
+	configured := `{
+  "policy": {
+    "description": "ingesting logs",
+    "schema_version": 1,
+    "default_state": "ingest",
+    "states": [{
+      "name": "ingest",
+      "actions": [{"rollover": {"min_doc_count": 5}}],
+      "transitions": [{"state_name": "delete"}]
+    }, {
+      "name": "delete",
+      "actions": [{"retry": {"count": 5, "backoff": "constant", "delay": "1h"}, "delete": {}}],
+      "transitions": []
+    }]
+  }
+}`
+	body := imported[0].Get("body").(string)
+	if !diffSuppressPolicy("body", body, configured, imported[0]) {
+		t.Errorf("expected no diff after importing %s", body)
+	}
+	changed := strings.Replace(configured, `"count": 5`, `"count": 4`, 1)
+	if diffSuppressPolicy("body", body, changed, imported[0]) {
+		t.Errorf("expected a changed retry to be a diff")
+	}
+}
+
+func TestOpenDistroISMPolicyImportThenUpdate(t *testing.T) {
+	description := "ingesting logs"
+	seqNo := 7
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_ism/policies/test_policy":
+			fmt.Fprintf(w, `{"_id": "test_policy", "_version": 1, "_seq_no": %d, "_primary_term": 1, "policy": {"policy_id": "test_policy", "description": %q, "default_state": "hot", "states": [{"name": "hot", "actions": [], "transitions": []}]}}`, seqNo, description)
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_ism/policies/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"type": "status_exception", "reason": "Policy not found"}, "status": 404}`)
+		case r.Method == "PUT" && r.URL.Path == "/_opendistro/_ism/policies/test_policy":
+			if q := r.URL.Query(); q.Get("if_seq_no") != "7" || q.Get("if_primary_term") != "1" {
+				w.WriteHeader(http.StatusConflict)
+				t.Errorf("expected the update to be conditional on the imported _seq_no and _primary_term, got %s", r.URL.RawQuery)
+				return
+			}
+			description, seqNo = "rolling over logs", 8
+			fmt.Fprint(w, `{"_id": "test_policy", "_version": 2, "_seq_no": 8, "_primary_term": 1, "policy": {"policy": {}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroISMPolicy().Schema, map[string]interface{}{})
+	resourceData.SetId("test_policy")
+	imported, err := resourceElasticsearchOpenDistroISMPolicy().Importer.State(resourceData, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if seq, term := imported[0].Get("seq_no"), imported[0].Get("primary_term"); seq != 7 || term != 1 {
+		t.Fatalf("expected the _seq_no and _primary_term to be imported, got %v and %v", seq, term)
+	}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"policy_id": "test_policy",
+		"body":      `{"policy": {"description": "rolling over logs", "default_state": "hot", "states": [{"name": "hot", "actions": []}]}}`,
+	})
+	diff, err := resourceElasticsearchOpenDistroISMPolicy().Diff(imported[0].State(), config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := resourceElasticsearchOpenDistroISMPolicy().Apply(imported[0].State(), diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if state.Attributes["seq_no"] != "8" {
+		t.Errorf("expected the _seq_no of the update to be stored, got %s", state.Attributes["seq_no"])
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroISMPolicy().Schema, map[string]interface{}{})
+	resourceData.SetId("missing")
+	if _, err := resourceElasticsearchOpenDistroISMPolicy().Importer.State(resourceData, meta); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected an error importing a missing policy, got %v", err)
+	}
+}
+
+func TestDiffSuppressPolicyTerminalState(t *testing.T) {
+	policy := `{
+  "policy": {
+    "description": "deleting logs",
+    "default_state": "hot",
+    "states": [{
+      "name": "hot",
+      "actions": [],
+      "transitions": [{"state_name": "delete", "conditions": {"min_index_age": "30d"}}]
+    }, {
+      "name": "delete",
+      "actions": [{"delete": {}}]%s
+    }]
+  }
+}`
+	omitted := fmt.Sprintf(policy, "")
+	empty := fmt.Sprintf(policy, `,
+      "transitions": []`)
+	null := fmt.Sprintf(policy, `,
+      "transitions": null`)
+
+	cases := []struct {
+		old, new string
+	}{
+		{omitted, empty},
+		{empty, omitted},
+		{null, empty},
+		{omitted, null},
+	}
+	for _, c := range cases {
+		if !diffSuppressPolicy("body", c.old, c.new, nil) {
+			t.Errorf("expected no diff between %s and %s", c.old, c.new)
+		}
+	}
+
+	transition := fmt.Sprintf(policy, `,
+      "transitions": [{"state_name": "hot"}]`)
+	if diffSuppressPolicy("body", omitted, transition, nil) {
+		t.Errorf("expected a transition added to a terminal state to be a diff")
+	}
+}
+
+func TestDiffSuppressPolicySchedules(t *testing.T) {
+	policy := `{
+  "policy": {
+    "description": "rolling up logs",
+    "default_state": "hot",
+    "states": [{
+      "name": "hot",
+      "actions": [{"rollup": {"ism_rollup": {"schedule": {"interval": %s}}}}],
+      "transitions": [{"state_name": "delete", "conditions": {"min_index_age": %s}}]
+    }, {
+      "name": "delete",
+      "actions": [{"delete": {}}],
+      "transitions": [{"state_name": "hot", "conditions": {"cron": {"cron": {"expression": %q, "timezone": "UTC"}}}}]
+    }]
+  }
+}`
+	configured := fmt.Sprintf(policy, `{"period": 1, "unit": "Days"}`, `"1d"`, "0 0 * * *")
+	read := fmt.Sprintf(policy, `{"period": 24, "unit": "Hours", "start_time": 1602100553}`, `{"period": 1, "unit": "Days"}`, "0  0 * *  *")
+
+	if !diffSuppressPolicy("body", read, configured, nil) {
+		t.Errorf("expected no diff between %s and %s", read, configured)
+	}
+
+	later := fmt.Sprintf(policy, `{"period": 1, "unit": "Days"}`, `"2d"`, "0 0 * * *")
+	if diffSuppressPolicy("body", read, later, nil) {
+		t.Errorf("expected a changed min_index_age to be a diff")
+	}
+
+	// the body is set from the normalized policy when reading
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(read), &response); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	normalizePolicy(response)
+	states := response["policy"].(map[string]interface{})["states"].([]interface{})
+	transitions := states[0].(map[string]interface{})["transitions"].([]interface{})
+	if age := transitions[0].(map[string]interface{})["conditions"].(map[string]interface{})["min_index_age"]; age != "24h0m0s" {
+		t.Errorf("expected the min_index_age to be canonical, got %v", age)
+	}
+}
+
 func testCheckElasticsearchOpenDistroISMPolicyExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
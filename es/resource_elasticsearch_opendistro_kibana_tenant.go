@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/olivere/elastic/uritemplates"
@@ -13,21 +14,29 @@ import (
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
+// reservedTenantNames are the tenants of Kibana multi-tenancy built into the
+// security plugin, which can't be managed through the API.
+var reservedTenantNames = []string{"global_tenant", "global", "private", "__user__"}
+
 func resourceElasticsearchOpenDistroKibanaTenant() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroKibanaTenantCreate,
-		Read:   resourceElasticsearchOpenDistroKibanaTenantRead,
-		Update: resourceElasticsearchOpenDistroKibanaTenantUpdate,
-		Delete: resourceElasticsearchOpenDistroKibanaTenantDelete,
+		Description: "Provides an Elasticsearch OpenDistro tenant of Kibana multi-tenancy. The built-in global and private tenants are reserved and can't be managed.",
+		Create:      resourceElasticsearchOpenDistroKibanaTenantCreate,
+		Read:        resourceElasticsearchOpenDistroKibanaTenantRead,
+		Update:      resourceElasticsearchOpenDistroKibanaTenantUpdate,
+		Delete:      resourceElasticsearchOpenDistroKibanaTenantDelete,
 		Schema: map[string]*schema.Schema{
 			"tenant_name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateTenantName,
+				Description:  "The name of the tenant.",
 			},
 			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the tenant.",
 			},
 		},
 		Importer: &schema.ResourceImporter{
@@ -36,6 +45,16 @@ func resourceElasticsearchOpenDistroKibanaTenant() *schema.Resource {
 	}
 }
 
+// validateTenantName rejects the names of the reserved tenants.
+func validateTenantName(i interface{}, k string) (warnings []string, errors []error) {
+	for _, name := range reservedTenantNames {
+		if strings.EqualFold(i.(string), name) {
+			errors = append(errors, fmt.Errorf("%q: tenant %s is reserved by the security plugin and can't be managed, tenants named %s are built in", k, i, strings.Join(reservedTenantNames, ", ")))
+		}
+	}
+	return
+}
+
 func resourceElasticsearchOpenDistroKibanaTenantCreate(d *schema.ResourceData, m interface{}) error {
 	if _, err := resourceElasticsearchPutOpenDistroKibanaTenant(d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroKibanaTenant: %+v", err)
@@ -51,7 +70,7 @@ func resourceElasticsearchOpenDistroKibanaTenantRead(d *schema.ResourceData, m i
 	res, err := resourceElasticsearchGetOpenDistroKibanaTenant(d.Id(), m)
 
 	if err != nil {
-		if elastic7.IsNotFound(err) {
+		if elastic7.IsNotFound(err) || err == errObjNotFound {
 			log.Printf("[WARN] OpenDistroKibanaTenant (%s) not found, removing from state", d.Id())
 			d.SetId("")
 			return nil
@@ -85,23 +104,33 @@ func resourceElasticsearchOpenDistroKibanaTenantDelete(d *schema.ResourceData, m
 		return fmt.Errorf("error building URL path for tenant: %+v", err)
 	}
 
+	var message string
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   path,
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "DELETE",
+			Path:         path,
+			IgnoreErrors: securityProtectedStatuses,
 		})
+		message, err = checkSecurityResponse(res, err)
 	default:
 		err = errors.New("Creating tenants requires elastic v7 client")
 	}
 
+	if message != "" {
+		return fmt.Errorf("tenant %s is reserved or hidden and cannot be deleted: %s", d.Get("tenant_name").(string), message)
+	}
+	if elastic7.IsNotFound(err) {
+		return nil
+	}
+
 	return err
 }
-
 func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface{}) (TenantBody, error) {
 	var err error
 	tenant := new(TenantBody)
@@ -126,7 +155,9 @@ func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface
 			Method: "GET",
 			Path:   path,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
 		err = errors.New("Creating tenants requires elastic v7 client")
 	}
@@ -140,7 +171,11 @@ func resourceElasticsearchGetOpenDistroKibanaTenant(tenantID string, m interface
 		return *tenant, fmt.Errorf("error unmarshalling tenant body: %+v: %+v", err, body)
 	}
 
-	*tenant = tenantDefinition[tenantID]
+	definition, ok := tenantDefinition[tenantID]
+	if !ok {
+		return *tenant, errObjNotFound
+	}
+	*tenant = definition
 
 	return *tenant, err
 }
@@ -165,6 +200,7 @@ func resourceElasticsearchPutOpenDistroKibanaTenant(d *schema.ResourceData, m in
 	}
 
 	var body json.RawMessage
+	var message string
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
@@ -172,17 +208,24 @@ func resourceElasticsearchPutOpenDistroKibanaTenant(d *schema.ResourceData, m in
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "PUT",
-			Path:   path,
-			Body:   string(tenantJSON),
+		res, err = securityPerformRequest(client, elastic7.PerformRequestOptions{
+			Method:       "PUT",
+			Path:         path,
+			Body:         string(tenantJSON),
+			IgnoreErrors: securityProtectedStatuses,
 		})
-		body = res.Body
+		message, err = checkSecurityResponse(res, err)
+		if err == nil {
+			body = res.Body
+		}
 	default:
 		err = errors.New("Creating tenants requires elastic v7 client")
 	}
 
 	if err != nil {
+		if message != "" {
+			return response, fmt.Errorf("tenant %s is reserved or hidden and cannot be modified: %s", d.Get("tenant_name").(string), message)
+		}
 		return response, fmt.Errorf("error creating tenant: %+v: %+v", err, body)
 	}
 
@@ -1,7 +1,11 @@
 package es
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -79,6 +83,88 @@ func TestAccElasticsearchOpenDistroKibanaTenant(t *testing.T) {
 	})
 }
 
+func TestOpenDistroTenantCreateUpdate(t *testing.T) {
+	tenants := map[string]map[string]interface{}{
+		"global_tenant": {"reserved": true, "description": "Global tenant"},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/_opendistro/_security/api/tenants/")
+		tenant, ok := tenants[name]
+		switch r.Method {
+		case "PUT":
+			if ok && tenant["reserved"] == true {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintf(w, `{"status": "FORBIDDEN", "message": "Resource '%s' is reserved."}`, name)
+				return
+			}
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			tenants[name] = body
+			fmt.Fprintf(w, `{"status": "CREATED", "message": "'%s' created."}`, name)
+		case "GET":
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"status": "NOT_FOUND", "message": "Resource '%s' not found."}`, name)
+				return
+			}
+			body, _ := json.Marshal(map[string]interface{}{name: tenant})
+			fmt.Fprint(w, string(body))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroKibanaTenant().Schema, map[string]interface{}{
+		"tenant_name": "analysts",
+		"description": "For analysts",
+	})
+	if err := resourceElasticsearchOpenDistroKibanaTenantCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "analysts" || resourceData.Get("description") != "For analysts" {
+		t.Errorf("expected the tenant analysts to be created, got %s: %v", resourceData.Id(), resourceData.Get("description"))
+	}
+
+	if err := resourceData.Set("description", "For the analysts team"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := resourceElasticsearchOpenDistroKibanaTenantUpdate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tenants["analysts"]["description"] != "For the analysts team" || resourceData.Get("description") != "For the analysts team" {
+		t.Errorf("expected the description to be updated, got %v", tenants["analysts"])
+	}
+
+	// tenants deleted outside of terraform are removed from the state
+	delete(tenants, "analysts")
+	if err := resourceElasticsearchOpenDistroKibanaTenantRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "" {
+		t.Errorf("expected the missing tenant to be removed from the state, got %s", resourceData.Id())
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroKibanaTenant().Schema, map[string]interface{}{
+		"tenant_name": "global_tenant",
+	})
+	err := resourceElasticsearchOpenDistroKibanaTenantCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "tenant global_tenant is reserved") {
+		t.Errorf("expected an error about the reserved tenant, got %v", err)
+	}
+
+	if _, errs := validateTenantName("Private", "tenant_name"); len(errs) == 0 {
+		t.Error("expected the private tenant to be rejected")
+	}
+	if _, errs := validateTenantName("analysts", "tenant_name"); len(errs) != 0 {
+		t.Errorf("expected analysts to be accepted, got %v", errs)
+	}
+}
+
 func testAccCheckElasticsearchOpenDistroKibanaTenantDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_opendistro_kibana_tenant" {
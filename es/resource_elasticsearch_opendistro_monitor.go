@@ -6,6 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
@@ -16,6 +21,12 @@ import (
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
+const (
+	openDistroMonitorsPath  = "/_opendistro/_alerting/monitors"
+	openSearchMonitorsPath  = "/_plugins/_alerting/monitors"
+	openSearchWorkflowsPath = "/_plugins/_alerting/workflows"
+)
+
 var openDistroMonitorSchema = map[string]*schema.Schema{
 	"body": {
 		Type:             schema.TypeString,
@@ -25,17 +36,117 @@ var openDistroMonitorSchema = map[string]*schema.Schema{
 			json, _ := structure.NormalizeJsonString(v)
 			return json
 		},
-		ValidateFunc: validation.StringIsJSON,
+		ValidateFunc: validation.All(validation.StringIsJSON, validateMonitorChainedAlertTriggers, validateMonitorClusterMetricsInputs),
+	},
+	"execute_dryrun_period": {
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Run the monitor without performing its actions before it is saved, as if it ran for the given period, and fail if the run fails.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"period_start": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Description:  "RFC3339 timestamp of the start of the period to run the monitor for. The alerting API derives the start from the schedule of the monitor, so the monitor is run with an interval schedule of the length of the period instead, which has to be whole minutes. Defaults to the start following from the schedule of the monitor.",
+					ValidateFunc: validation.ValidateRFC3339TimeString,
+				},
+				"period_end": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "RFC3339 timestamp of the end of the period to run the monitor for.",
+					ValidateFunc: validation.ValidateRFC3339TimeString,
+				},
+				"expect_triggered": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Fail unless at least one trigger of the monitor fires for the period.",
+				},
+			},
+		},
+	},
+	"severity_routing": {
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Warn, without failing, when a trigger sends to a destination whose tag doesn't match the severity of the trigger, e.g. a severity 1 trigger sending to a dev channel.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"severity_tags": {
+					Type:        schema.TypeMap,
+					Required:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "The tag of the destinations expected for each severity, keyed by the severity from 1 (highest) to 5, e.g. `{ \"1\" = \"pager\" }`.",
+				},
+				"destination_tags": {
+					Type:        schema.TypeMap,
+					Required:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "The tags of the destinations, keyed by the destination ID. Destinations without a tag aren't checked.",
+				},
+			},
+		},
+	},
+	"severity_routing_warnings": {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The triggers sending to a destination whose tag doesn't match their severity, found by `severity_routing`. Changes of the warnings are shown in the plan.",
+	},
+	"validate_action_templates": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Render the `message_template` and `subject_template` of the actions of the monitor with a mocked `ctx` before it is saved, and fail if a template doesn't render, e.g. because of an unclosed Mustache tag.",
+	},
+	"validate_on_plan": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Check that the body has the fields required by the alerting API when planning, e.g. the `condition` of each trigger, instead of failing when applying. The body isn't sent to the cluster for this.",
+	},
+	"auto_acknowledge_resolved": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "On each refresh, run the monitor without performing its actions and acknowledge its active alerts whose trigger no longer fires. Not supported for workflows.",
+	},
+	"enabled_time": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "RFC3339 timestamp of when the monitor was last enabled, set by the server and ignored when comparing the body. Empty while the monitor is disabled.",
+	},
+	"created_by": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The name of the user who last saved the monitor, from the `user` block the security plugin adds to it. Empty without the security plugin.",
+	},
+	"last_update_time": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "RFC3339 timestamp of when the monitor was last updated, set by the server and ignored when comparing the body.",
+	},
+	"primary_term": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The primary term of the monitor, sent with updates to detect concurrent changes.",
+	},
+	"seq_no": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The sequence number of the monitor, sent with updates to detect concurrent changes.",
 	},
 }
 
 func resourceElasticsearchDeprecatedMonitor() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroMonitorCreate,
-		Read:   resourceElasticsearchOpenDistroMonitorRead,
-		Update: resourceElasticsearchOpenDistroMonitorUpdate,
-		Delete: resourceElasticsearchOpenDistroMonitorDelete,
-		Schema: openDistroMonitorSchema,
+		Create:        resourceElasticsearchOpenDistroMonitorCreate,
+		Read:          resourceElasticsearchOpenDistroMonitorRead,
+		Update:        resourceElasticsearchOpenDistroMonitorUpdate,
+		Delete:        resourceElasticsearchOpenDistroMonitorDelete,
+		Schema:        openDistroMonitorSchema,
+		CustomizeDiff: resourceElasticsearchOpenDistroMonitorCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -45,11 +156,12 @@ func resourceElasticsearchDeprecatedMonitor() *schema.Resource {
 
 func resourceElasticsearchOpenDistroMonitor() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchOpenDistroMonitorCreate,
-		Read:   resourceElasticsearchOpenDistroMonitorRead,
-		Update: resourceElasticsearchOpenDistroMonitorUpdate,
-		Delete: resourceElasticsearchOpenDistroMonitorDelete,
-		Schema: openDistroMonitorSchema,
+		Create:        resourceElasticsearchOpenDistroMonitorCreate,
+		Read:          resourceElasticsearchOpenDistroMonitorRead,
+		Update:        resourceElasticsearchOpenDistroMonitorUpdate,
+		Delete:        resourceElasticsearchOpenDistroMonitorDelete,
+		Schema:        openDistroMonitorSchema,
+		CustomizeDiff: resourceElasticsearchOpenDistroMonitorCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -57,6 +169,13 @@ func resourceElasticsearchOpenDistroMonitor() *schema.Resource {
 }
 
 func resourceElasticsearchOpenDistroMonitorCreate(d *schema.ResourceData, m interface{}) error {
+	if err := resourceElasticsearchOpenDistroMonitorValidateTemplates(d, m); err != nil {
+		return err
+	}
+	if err := resourceElasticsearchOpenDistroMonitorDryrun(d, m); err != nil {
+		return err
+	}
+
 	res, err := resourceElasticsearchOpenDistroPostMonitor(d, m)
 
 	if err != nil {
@@ -74,7 +193,7 @@ func resourceElasticsearchOpenDistroMonitorCreate(d *schema.ResourceData, m inte
 }
 
 func resourceElasticsearchOpenDistroMonitorRead(d *schema.ResourceData, m interface{}) error {
-	res, err := resourceElasticsearchOpenDistroGetMonitor(d.Id(), m)
+	res, err := resourceElasticsearchOpenDistroGetMonitorFrom(monitorBasePath(d.Get("body").(string)), d.Id(), m)
 
 	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
 		log.Printf("[WARN] Monitor (%s) not found, removing from state", d.Id())
@@ -96,13 +215,51 @@ func resourceElasticsearchOpenDistroMonitorRead(d *schema.ResourceData, m interf
 	if err != nil {
 		return err
 	}
-	err = d.Set("body", monitorJsonNormalized)
-	return err
+	if err := d.Set("body", monitorJsonNormalized); err != nil {
+		return fmt.Errorf("error setting body: %s", err)
+	}
+	if err := d.Set("enabled_time", res.EnabledTime); err != nil {
+		return fmt.Errorf("error setting enabled_time: %s", err)
+	}
+	if err := d.Set("created_by", res.CreatedBy); err != nil {
+		return fmt.Errorf("error setting created_by: %s", err)
+	}
+	if err := d.Set("last_update_time", res.LastUpdateTime); err != nil {
+		return fmt.Errorf("error setting last_update_time: %s", err)
+	}
+	if err := d.Set("primary_term", res.PrimaryTerm); err != nil {
+		return fmt.Errorf("error setting primary_term: %s", err)
+	}
+	if err := d.Set("seq_no", res.SeqNo); err != nil {
+		return fmt.Errorf("error setting seq_no: %s", err)
+	}
+	if err := d.Set("severity_routing_warnings", monitorSeverityRoutingWarningsOf(monitorJsonNormalized, d.Get("severity_routing"))); err != nil {
+		return fmt.Errorf("error setting severity_routing_warnings: %s", err)
+	}
+
+	if d.Get("auto_acknowledge_resolved").(bool) && !isMonitorWorkflow(d.Get("body").(string)) {
+		// acknowledging is best-effort, it shouldn't fail the refresh
+		if err := resourceElasticsearchOpenDistroMonitorAcknowledgeResolved(d.Id(), m); err != nil {
+			log.Printf("[WARN] Failed to acknowledge the resolved alerts of monitor (%s): %+v", d.Id(), err)
+		}
+	}
+
+	return nil
 }
 
 func resourceElasticsearchOpenDistroMonitorUpdate(d *schema.ResourceData, m interface{}) error {
+	if err := resourceElasticsearchOpenDistroMonitorValidateTemplates(d, m); err != nil {
+		return err
+	}
+	if err := resourceElasticsearchOpenDistroMonitorDryrun(d, m); err != nil {
+		return err
+	}
+
 	_, err := resourceElasticsearchOpenDistroPutMonitor(d, m)
 
+	if elastic6.IsConflict(err) || elastic7.IsConflict(err) {
+		return fmt.Errorf("monitor (%s) was modified outside of terraform, refresh the state and try again: %+v", d.Id(), err)
+	}
 	if err != nil {
 		return err
 	}
@@ -113,7 +270,7 @@ func resourceElasticsearchOpenDistroMonitorUpdate(d *schema.ResourceData, m inte
 func resourceElasticsearchOpenDistroMonitorDelete(d *schema.ResourceData, m interface{}) error {
 	var err error
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := uritemplates.Expand(monitorBasePath(d.Get("body").(string))+"/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
@@ -136,17 +293,21 @@ func resourceElasticsearchOpenDistroMonitorDelete(d *schema.ResourceData, m inte
 			Path:   path,
 		})
 	default:
-		err = errors.New("monitor resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
 	}
 
 	return err
 }
 
 func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{}) (*monitorResponse, error) {
+	return resourceElasticsearchOpenDistroGetMonitorFrom(openDistroMonitorsPath, monitorID, m)
+}
+
+func resourceElasticsearchOpenDistroGetMonitorFrom(basePath string, monitorID string, m interface{}) (*monitorResponse, error) {
 	var err error
 	response := new(monitorResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := uritemplates.Expand(basePath+"/{id}", map[string]string{
 		"id": monitorID,
 	})
 	if err != nil {
@@ -165,16 +326,20 @@ func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{})
 			Method: "GET",
 			Path:   path,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   path,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("monitor resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
@@ -184,6 +349,13 @@ func resourceElasticsearchOpenDistroGetMonitor(monitorID string, m interface{})
 	if err := json.Unmarshal(body, response); err != nil {
 		return response, fmt.Errorf("error unmarshalling monitor body: %+v: %+v", err, body)
 	}
+	if response.Monitor == nil {
+		response.Monitor = response.Workflow
+	}
+	// the fields set by the server are dropped by normalizing the monitor
+	response.EnabledTime = monitorEnabledTime(response.Monitor)
+	response.CreatedBy = alertingUserName(response.Monitor)
+	response.LastUpdateTime = alertingTimestamp(response.Monitor, "last_update_time")
 	normalizeMonitor(response.Monitor)
 	return response, err
 }
@@ -194,7 +366,7 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 	var err error
 	response := new(monitorResponse)
 
-	path := "/_opendistro/_alerting/monitors/"
+	path := monitorBasePath(monitorJSON) + "/"
 
 	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
@@ -209,7 +381,9 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 			Path:   path,
 			Body:   monitorJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
@@ -217,29 +391,43 @@ func resourceElasticsearchOpenDistroPostMonitor(d *schema.ResourceData, m interf
 			Path:   path,
 			Body:   monitorJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("monitor resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
-		return response, err
+		return response, monitorRequestError(err, monitorJSON, m)
 	}
 
 	if err := json.Unmarshal(body, response); err != nil {
 		return response, fmt.Errorf("error unmarshalling monitor body: %+v: %+v", err, body)
 	}
+	if response.Monitor == nil {
+		response.Monitor = response.Workflow
+	}
 	normalizeMonitor(response.Monitor)
 	return response, nil
 }
 
 func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interface{}) (*monitorResponse, error) {
 	monitorJSON := d.Get("body").(string)
+	seq := d.Get("seq_no").(int)
+	primTerm := d.Get("primary_term").(int)
+	params := url.Values{}
+
+	// only update the monitor if it hasn't changed since it was last read
+	if seq >= 0 && primTerm > 0 {
+		params.Set("if_seq_no", strconv.Itoa(seq))
+		params.Set("if_primary_term", strconv.Itoa(primTerm))
+	}
 
 	var err error
 	response := new(monitorResponse)
 
-	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+	path, err := uritemplates.Expand(monitorBasePath(monitorJSON)+"/{id}", map[string]string{
 		"id": d.Id(),
 	})
 	if err != nil {
@@ -257,23 +445,29 @@ func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interfa
 		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
+			Params: params,
 			Body:   monitorJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
+			Params: params,
 			Body:   monitorJSON,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("monitor resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
-		return response, err
+		return response, monitorRequestError(err, monitorJSON, m)
 	}
 
 	if err := json.Unmarshal(body, response); err != nil {
@@ -283,8 +477,696 @@ func resourceElasticsearchOpenDistroPutMonitor(d *schema.ResourceData, m interfa
 	return response, nil
 }
 
+// resourceElasticsearchOpenDistroMonitorDryrun executes the monitor for the
+// configured execute_dryrun_period, if any.
+func resourceElasticsearchOpenDistroMonitorDryrun(d *schema.ResourceData, m interface{}) error {
+	periods := d.Get("execute_dryrun_period").([]interface{})
+	if len(periods) == 0 || periods[0] == nil {
+		return nil
+	}
+	period := periods[0].(map[string]interface{})
+
+	if isMonitorWorkflow(d.Get("body").(string)) {
+		return errors.New("execute_dryrun_period is not supported for workflows")
+	}
+
+	periodEnd, err := time.Parse(time.RFC3339, period["period_end"].(string))
+	if err != nil {
+		return fmt.Errorf("error parsing period_end: %+v", err)
+	}
+
+	monitorJSON := d.Get("body").(string)
+	if start, _ := period["period_start"].(string); start != "" {
+		periodStart, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return fmt.Errorf("error parsing period_start: %+v", err)
+		}
+		monitorJSON, err = monitorWithPeriod(monitorJSON, periodStart, periodEnd)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := resourceElasticsearchOpenDistroExecuteMonitor(monitorJSON, periodEnd, m)
+	if err != nil {
+		return err
+	}
+
+	if res.Error != nil {
+		return fmt.Errorf("dryrun of monitor failed: %v", res.Error)
+	}
+	if res.InputResults.Error != nil {
+		return fmt.Errorf("dryrun of monitor failed on its inputs: %v", res.InputResults.Error)
+	}
+
+	triggered := false
+	for _, trigger := range res.TriggerResults {
+		if trigger.Error != nil {
+			return fmt.Errorf("dryrun of monitor failed on trigger %q: %v", trigger.Name, trigger.Error)
+		}
+		log.Printf("[INFO] Dryrun of monitor trigger %q triggered: %t", trigger.Name, trigger.Triggered)
+		triggered = triggered || trigger.Triggered
+	}
+
+	if period["expect_triggered"].(bool) && !triggered {
+		return fmt.Errorf("dryrun of monitor for the period from %s to %s did not fire any trigger", res.PeriodStart, res.PeriodEnd)
+	}
+
+	return nil
+}
+
+func resourceElasticsearchOpenDistroExecuteMonitor(monitorJSON string, periodEnd time.Time, m interface{}) (*monitorExecuteResponse, error) {
+	response := new(monitorExecuteResponse)
+
+	params := url.Values{}
+	params.Set("dryrun", "true")
+	params.Set("period_end", strconv.FormatInt(periodEnd.UnixNano()/int64(time.Millisecond), 10))
+
+	var body json.RawMessage
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	// the flavor of the cluster is known once the client is created
+	path := openDistroMonitorsPath + "/_execute"
+	if m.(*ProviderConf).flavor == OpenSearch {
+		path = openSearchMonitorsPath + "/_execute"
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+			Params: params,
+			Body:   monitorJSON,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+			Params: params,
+			Body:   monitorJSON,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
+	}
+
+	if err != nil {
+		return response, fmt.Errorf("error executing monitor: %+v", monitorRequestError(err, monitorJSON, m))
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling monitor execute body: %+v: %+v", err, body)
+	}
+
+	return response, nil
+}
+
+// monitorWithPeriod returns the monitor with an interval schedule of the
+// length of the period, as the execute API only takes the end of the period
+// and derives its start from the schedule of the monitor.
+func monitorWithPeriod(monitorJSON string, periodStart, periodEnd time.Time) (string, error) {
+	length := periodEnd.Sub(periodStart)
+	if length <= 0 || length%time.Minute != 0 {
+		return "", fmt.Errorf("the period from %s to %s of execute_dryrun_period has to be a positive number of whole minutes", periodStart.Format(time.RFC3339), periodEnd.Format(time.RFC3339))
+	}
+
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(monitorJSON), &monitor); err != nil {
+		return "", fmt.Errorf("error unmarshalling monitor body: %+v", err)
+	}
+	monitor["schedule"] = map[string]interface{}{
+		"period": map[string]interface{}{
+			"interval": int64(length / time.Minute),
+			"unit":     "MINUTES",
+		},
+	}
+
+	body, err := json.Marshal(monitor)
+	return string(body), err
+}
+
+// resourceElasticsearchOpenDistroMonitorAcknowledgeResolved acknowledges the
+// active alerts of the monitor whose trigger doesn't fire anymore when the
+// monitor is run without performing its actions.
+func resourceElasticsearchOpenDistroMonitorAcknowledgeResolved(monitorID string, m interface{}) error {
+	alerts, err := resourceElasticsearchOpenDistroMonitorActiveAlerts(monitorID, m)
+	if err != nil || len(alerts) == 0 {
+		return err
+	}
+
+	path, err := uritemplates.Expand(openDistroMonitorsPath+"/{id}/_execute", map[string]string{
+		"id": monitorID,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for monitor: %+v", err)
+	}
+	params := url.Values{}
+	params.Set("dryrun", "true")
+	res := new(monitorExecuteResponse)
+	if err := resourceElasticsearchOpenDistroMonitorRequest("POST", path, params, nil, res, m); err != nil {
+		return fmt.Errorf("error executing monitor: %+v", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("run of monitor failed: %v", res.Error)
+	}
+
+	var resolved []string
+	for _, alert := range alerts {
+		trigger, ok := res.TriggerResults[alert.TriggerID]
+		if !ok || trigger.Error != nil || trigger.Triggered {
+			continue
+		}
+		log.Printf("[INFO] Trigger %q of monitor (%s) doesn't fire anymore, acknowledging alert %s", trigger.Name, monitorID, alert.ID)
+		resolved = append(resolved, alert.ID)
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+
+	path, err = uritemplates.Expand(openDistroMonitorsPath+"/{id}/_acknowledge/alerts", map[string]string{
+		"id": monitorID,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for monitor: %+v", err)
+	}
+	body := map[string]interface{}{"alerts": resolved}
+	if err := resourceElasticsearchOpenDistroMonitorRequest("POST", path, nil, body, nil, m); err != nil {
+		return fmt.Errorf("error acknowledging alerts %s: %+v", strings.Join(resolved, ", "), err)
+	}
+
+	return nil
+}
+
+// resourceElasticsearchOpenDistroMonitorActiveAlerts returns the active
+// alerts of the monitor.
+func resourceElasticsearchOpenDistroMonitorActiveAlerts(monitorID string, m interface{}) ([]monitorAlert, error) {
+	params := url.Values{}
+	params.Set("monitorId", monitorID)
+	params.Set("alertState", "ACTIVE")
+	params.Set("size", "1000")
+
+	res := new(monitorAlertsResponse)
+	if err := resourceElasticsearchOpenDistroMonitorRequest("GET", openDistroMonitorsPath+"/alerts", params, nil, res, m); err != nil {
+		return nil, fmt.Errorf("error getting the active alerts: %+v", err)
+	}
+
+	return res.Alerts, nil
+}
+
+// resourceElasticsearchOpenDistroMonitorRequest sends a request to the
+// alerting API, unmarshalling the response into response, if any.
+func resourceElasticsearchOpenDistroMonitorRequest(method, path string, params url.Values, reqBody interface{}, response interface{}, m interface{}) error {
+	var body json.RawMessage
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: method,
+			Path:   path,
+			Params: params,
+			Body:   reqBody,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: method,
+			Path:   path,
+			Params: params,
+			Body:   reqBody,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
+	}
+
+	if err != nil || response == nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("error unmarshalling monitor response body: %+v: %+v", err, body)
+	}
+	return nil
+}
+
+// defaultHTTPMaxContentLength is the default of http.max_content_length.
+const defaultHTTPMaxContentLength = "100mb"
+
+// monitorRequestError returns a clear error when the cluster rejected the
+// body of a monitor for exceeding http.max_content_length, which is a 413
+// response without any details. The body is sent as the configured string,
+// which the client streams from a reader instead of buffering an encoded
+// copy of it, so only the limit of the cluster restricts its size.
+func monitorRequestError(err error, monitorJSON string, m interface{}) error {
+	if !elastic7.IsStatusCode(err, http.StatusRequestEntityTooLarge) && !elastic6.IsStatusCode(err, http.StatusRequestEntityTooLarge) {
+		return err
+	}
+
+	limit, limitErr := clusterHTTPMaxContentLength(m)
+	if limitErr != nil {
+		log.Printf("[WARN] Unable to get http.max_content_length of the cluster: %+v", limitErr)
+		limit = "unknown"
+	}
+	return fmt.Errorf("the monitor body of %d bytes exceeds http.max_content_length of the cluster (%s), reduce the size of its queries and aggregations, or raise the limit: %+v", len(monitorJSON), limit, err)
+}
+
+// clusterHTTPMaxContentLength returns the effective http.max_content_length
+// of the cluster, e.g. 100mb.
+func clusterHTTPMaxContentLength(m interface{}) (string, error) {
+	params := url.Values{}
+	params.Set("include_defaults", "true")
+	params.Set("flat_settings", "true")
+
+	settings := new(clusterSettingsResponse)
+	if err := resourceElasticsearchOpenDistroMonitorRequest("GET", "/_cluster/settings", params, nil, settings, m); err != nil {
+		return "", err
+	}
+
+	for _, s := range []map[string]interface{}{settings.Transient, settings.Persistent, settings.Defaults} {
+		if v, ok := s["http.max_content_length"]; ok {
+			return fmt.Sprint(v), nil
+		}
+	}
+
+	return defaultHTTPMaxContentLength, nil
+}
+
+// monitorEnabledTime returns the time, in milliseconds since the epoch, the
+// server set when the monitor was enabled as an RFC3339 timestamp.
+func monitorEnabledTime(monitor map[string]interface{}) string {
+	return alertingTimestamp(monitor, "enabled_time")
+}
+
+// resourceElasticsearchOpenDistroMonitorValidateTemplates renders the
+// templates of the actions of the monitor through the render template API,
+// with a mocked ctx as the alerting plugin passes to them.
+func resourceElasticsearchOpenDistroMonitorValidateTemplates(d *schema.ResourceData, m interface{}) error {
+	if !d.Get("validate_action_templates").(bool) {
+		return nil
+	}
+
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("body").(string)), &monitor); err != nil {
+		return err
+	}
+
+	for _, t := range monitorActionTemplates(monitor) {
+		if err := resourceElasticsearchRenderTemplate(t.source, monitorTemplateContext(monitor, t.trigger), m); err != nil {
+			return fmt.Errorf("%s of action %q of trigger %q doesn't render: %+v", t.field, t.action, t.trigger["name"], err)
+		}
+	}
+
+	return nil
+}
+
+// monitorTriggerBody returns the body of a trigger of a monitor and the key
+// it's wrapped in: bucket level and document level triggers of OpenSearch are
+// wrapped in an object named after their type, e.g. bucket_level_trigger.
+func monitorTriggerBody(trigger map[string]interface{}) (string, map[string]interface{}) {
+	if len(trigger) == 1 {
+		for k, v := range trigger {
+			if inner, ok := v.(map[string]interface{}); ok && strings.HasSuffix(k, "_trigger") {
+				return k, inner
+			}
+		}
+	}
+	return "", trigger
+}
+
+type monitorActionTemplate struct {
+	trigger map[string]interface{}
+	action  interface{}
+	field   string
+	source  string
+}
+
+// monitorActionTemplates returns the templates of the actions of the
+// triggers of a monitor.
+func monitorActionTemplates(monitor map[string]interface{}) []monitorActionTemplate {
+	var templates []monitorActionTemplate
+
+	triggers, _ := monitor["triggers"].([]interface{})
+	for _, t := range triggers {
+		wrapped, _ := t.(map[string]interface{})
+		_, trigger := monitorTriggerBody(wrapped)
+
+		actions, _ := trigger["actions"].([]interface{})
+		for _, a := range actions {
+			action, _ := a.(map[string]interface{})
+			for _, field := range []string{"subject_template", "message_template"} {
+				template, _ := action[field].(map[string]interface{})
+				if source, ok := template["source"].(string); ok {
+					templates = append(templates, monitorActionTemplate{trigger, action["name"], field, source})
+				}
+			}
+		}
+	}
+
+	return templates
+}
+
+// monitorTemplateContext returns a mocked ctx for the templates of the
+// actions of a trigger.
+func monitorTemplateContext(monitor, trigger map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"monitor": monitor,
+		"trigger": trigger,
+		"results": []interface{}{
+			map[string]interface{}{
+				"hits": map[string]interface{}{
+					"total": map[string]interface{}{"value": 0},
+					"hits":  []interface{}{},
+				},
+			},
+		},
+		"periodStart": "1970-01-01T00:00:00Z",
+		"periodEnd":   "1970-01-01T00:00:00Z",
+		"error":       nil,
+	}
+}
+
+// resourceElasticsearchRenderTemplate renders a Mustache template as the
+// value of a JSON document, the render template API only renders JSON.
+func resourceElasticsearchRenderTemplate(source string, ctx map[string]interface{}, m interface{}) error {
+	value, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"source": fmt.Sprintf(`{"value": %s}`, value),
+		"params": map[string]interface{}{"ctx": ctx},
+	}
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_render/template",
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_render/template",
+			Body:   body,
+		})
+	default:
+		err = &UnsupportedVersionError{Resource: "monitor", MinimumVersion: "v6"}
+	}
+
+	return err
+}
+
+// isMonitorWorkflow returns whether the body is a composite workflow chaining
+// monitors, managed through the workflows API of OpenSearch.
+func isMonitorWorkflow(body string) bool {
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &monitor); err != nil {
+		return false
+	}
+
+	return monitor["workflow_type"] == "composite"
+}
+
+// monitorBasePath returns the path of the API managing the monitor.
+func monitorBasePath(body string) string {
+	if isMonitorWorkflow(body) {
+		return openSearchWorkflowsPath
+	}
+
+	return openDistroMonitorsPath
+}
+
+// validateMonitorChainedAlertTriggers checks that chained alert triggers,
+// which fire on the results of other monitors, are only used in workflows.
+func validateMonitorChainedAlertTriggers(i interface{}, k string) (warnings []string, errors []error) {
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(i.(string)), &monitor); err != nil {
+		return
+	}
+
+	triggers, _ := monitor["triggers"].([]interface{})
+	for _, t := range triggers {
+		trigger, _ := t.(map[string]interface{})
+		if _, ok := trigger["chained_alert_trigger"]; ok && monitor["workflow_type"] != "composite" {
+			errors = append(errors, fmt.Errorf("%q: chained_alert_trigger can only be used in workflows, with workflow_type composite", k))
+			return
+		}
+	}
+
+	return
+}
+
+// monitorClusterMetricsAPITypes are the cluster APIs queried by the uri inputs
+// of cluster metrics monitors.
+var monitorClusterMetricsAPITypes = []string{
+	"CAT_INDICES",
+	"CAT_PENDING_TASKS",
+	"CAT_RECOVERY",
+	"CAT_SHARDS",
+	"CAT_SNAPSHOTS",
+	"CAT_TASKS",
+	"CLUSTER_HEALTH",
+	"CLUSTER_SETTINGS",
+	"CLUSTER_STATS",
+	"NODES_STATS",
+}
+
+// validateMonitorClusterMetricsInputs checks the api_type of the uri inputs
+// of cluster metrics monitors.
+func validateMonitorClusterMetricsInputs(i interface{}, k string) (warnings []string, errors []error) {
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(i.(string)), &monitor); err != nil {
+		return
+	}
+
+	inputs, _ := monitor["inputs"].([]interface{})
+	for _, in := range inputs {
+		input, _ := in.(map[string]interface{})
+		uri, ok := input["uri"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		apiType, ok := uri["api_type"]
+		if !ok {
+			continue
+		}
+		valid := false
+		for _, t := range monitorClusterMetricsAPITypes {
+			if apiType == t {
+				valid = true
+			}
+		}
+		if !valid {
+			errors = append(errors, fmt.Errorf("%q: unknown uri api_type %v, expected one of %s", k, apiType, strings.Join(monitorClusterMetricsAPITypes, ", ")))
+		}
+	}
+
+	return
+}
+
+// resourceElasticsearchOpenDistroMonitorCustomizeDiff rejects bodies missing
+// required fields with validate_on_plan, and plans a warning for each trigger
+// sending to a destination which doesn't match its severity. The warnings are
+// advisory only, and never fail the plan.
+func resourceElasticsearchOpenDistroMonitorCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("validate_on_plan").(bool) && d.NewValueKnown("body") {
+		if missing := monitorMissingFields(d.Get("body").(string)); len(missing) > 0 {
+			return fmt.Errorf("body of the monitor is missing the required fields %s", strings.Join(missing, ", "))
+		}
+	}
+
+	if !d.NewValueKnown("body") || !d.NewValueKnown("severity_routing") {
+		return d.SetNewComputed("severity_routing_warnings")
+	}
+
+	// the warnings are planned as a change of severity_routing_warnings, to
+	// show them in the plan
+	warnings := monitorSeverityRoutingWarningsOf(d.Get("body").(string), d.Get("severity_routing"))
+	for _, warning := range warnings {
+		log.Printf("[WARN] %s", warning)
+	}
+	current := d.Get("severity_routing_warnings").([]interface{})
+	if len(current) == len(warnings) {
+		changed := false
+		for i := range warnings {
+			if current[i] != warnings[i] {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+	return d.SetNew("severity_routing_warnings", warnings)
+}
+
+// monitorSeverityRoutingWarningsOf returns the warnings of the severity
+// routing, if configured, for the body of the monitor.
+func monitorSeverityRoutingWarningsOf(body string, routing interface{}) []string {
+	routingList, _ := routing.([]interface{})
+	if len(routingList) == 0 || routingList[0] == nil {
+		return []string{}
+	}
+	routingConfig := routingList[0].(map[string]interface{})
+
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &monitor); err != nil {
+		return []string{}
+	}
+
+	warnings := monitorSeverityRoutingWarnings(
+		monitor,
+		routingConfig["severity_tags"].(map[string]interface{}),
+		routingConfig["destination_tags"].(map[string]interface{}),
+	)
+	if warnings == nil {
+		return []string{}
+	}
+	return warnings
+}
+
+// monitorMissingFields returns the fields required by the alerting API which
+// are missing from the body of the monitor, its triggers and their actions.
+func monitorMissingFields(body string) []string {
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &monitor); err != nil {
+		return nil
+	}
+
+	var missing []string
+	for _, key := range []string{"name", "schedule", "inputs"} {
+		if isEmptyField(monitor[key]) {
+			missing = append(missing, key)
+		}
+	}
+
+	triggers, _ := monitor["triggers"].([]interface{})
+	for i, t := range triggers {
+		wrapped, _ := t.(map[string]interface{})
+		prefix := fmt.Sprintf("triggers[%d]", i)
+		key, trigger := monitorTriggerBody(wrapped)
+		if key != "" {
+			prefix += "." + key
+		}
+
+		for _, key := range []string{"name", "severity", "condition"} {
+			if isEmptyField(trigger[key]) {
+				missing = append(missing, prefix+"."+key)
+			}
+		}
+
+		actions, _ := trigger["actions"].([]interface{})
+		for j, a := range actions {
+			action, _ := a.(map[string]interface{})
+			for _, key := range []string{"name", "destination_id"} {
+				if isEmptyField(action[key]) {
+					missing = append(missing, fmt.Sprintf("%s.actions[%d].%s", prefix, j, key))
+				}
+			}
+		}
+	}
+
+	return missing
+}
+
+// monitorSeverityRoutingWarnings returns a warning for each action of a
+// trigger which sends to a destination tagged differently than the tag
+// expected for the severity of the trigger.
+func monitorSeverityRoutingWarnings(monitor map[string]interface{}, severityTags, destinationTags map[string]interface{}) []string {
+	var warnings []string
+
+	triggers, _ := monitor["triggers"].([]interface{})
+	for _, t := range triggers {
+		wrapped, _ := t.(map[string]interface{})
+		_, trigger := monitorTriggerBody(wrapped)
+
+		severity := fmt.Sprint(trigger["severity"])
+		expected, ok := severityTags[severity].(string)
+		if !ok {
+			continue
+		}
+
+		actions, _ := trigger["actions"].([]interface{})
+		for _, a := range actions {
+			action, _ := a.(map[string]interface{})
+			destinationID, _ := action["destination_id"].(string)
+			tag, ok := destinationTags[destinationID].(string)
+			if !ok || tag == expected {
+				continue
+			}
+
+			warnings = append(warnings, fmt.Sprintf(
+				"trigger %q of monitor %q has severity %s but its action %q sends to destination %s tagged %q instead of %q",
+				trigger["name"], monitor["name"], severity, action["name"], destinationID, tag, expected,
+			))
+		}
+	}
+
+	return warnings
+}
+
+type monitorExecuteResponse struct {
+	MonitorName  string      `json:"monitor_name"`
+	PeriodStart  interface{} `json:"period_start"`
+	PeriodEnd    interface{} `json:"period_end"`
+	Error        interface{} `json:"error"`
+	InputResults struct {
+		Error interface{} `json:"error"`
+	} `json:"input_results"`
+	TriggerResults map[string]struct {
+		Name      string      `json:"name"`
+		Triggered bool        `json:"triggered"`
+		Error     interface{} `json:"error"`
+	} `json:"trigger_results"`
+}
+
+type monitorAlertsResponse struct {
+	Alerts      []monitorAlert `json:"alerts"`
+	TotalAlerts int            `json:"totalAlerts"`
+}
+
+type monitorAlert struct {
+	ID          string `json:"id"`
+	TriggerID   string `json:"trigger_id"`
+	TriggerName string `json:"trigger_name"`
+	State       string `json:"state"`
+}
+
 type monitorResponse struct {
-	Version int                    `json:"_version"`
-	ID      string                 `json:"_id"`
-	Monitor map[string]interface{} `json:"monitor"`
+	Version     int                    `json:"_version"`
+	ID          string                 `json:"_id"`
+	PrimaryTerm int                    `json:"_primary_term"`
+	SeqNo       int                    `json:"_seq_no"`
+	Monitor     map[string]interface{} `json:"monitor"`
+	Workflow    map[string]interface{} `json:"workflow"`
+
+	// the fields set by the server, kept before normalizing the monitor
+	EnabledTime    string `json:"-"`
+	CreatedBy      string `json:"-"`
+	LastUpdateTime string `json:"-"`
 }
@@ -1,7 +1,15 @@
 package es
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -47,12 +55,628 @@ func TestAccElasticsearchOpenDistroMonitor(t *testing.T) {
 				Config: testAccElasticsearchOpenDistroMonitor,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckElasticsearchOpenDistroMonitorExists("elasticsearch_opendistro_monitor.test_monitor"),
+					resource.TestCheckResourceAttrSet("elasticsearch_opendistro_monitor.test_monitor", "seq_no"),
+					resource.TestCheckResourceAttrSet("elasticsearch_opendistro_monitor.test_monitor", "primary_term"),
+				),
+			},
+			{
+				Config: testAccElasticsearchOpenDistroMonitorWithDestination,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchOpenDistroMonitorExists("elasticsearch_opendistro_monitor.test_monitor"),
+					resource.TestMatchResourceAttr(
+						"elasticsearch_opendistro_monitor.test_monitor",
+						"body",
+						regexp.MustCompile(`"destination_id":"[^"]+"`),
+					),
 				),
 			},
 		},
 	})
 }
 
+func TestOpenDistroMonitorDryrunPeriod(t *testing.T) {
+	var received url.Values
+	var executed map[string]interface{}
+	var executedPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		executedPath = r.URL.Path
+		received = r.URL.Query()
+		executed = nil
+		if err := json.NewDecoder(r.Body).Decode(&executed); err != nil {
+			t.Errorf("err: %s", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "monitor_name": "test-monitor",
+  "period_start": 1609455600000,
+  "period_end": 1609459200000,
+  "error": null,
+  "input_results": {"results": [], "error": null},
+  "trigger_results": {"abc": {"name": "any-hits", "triggered": false, "error": null}}
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": `{"name": "test-monitor"}`,
+		"execute_dryrun_period": []interface{}{
+			map[string]interface{}{
+				"period_end": "2021-01-01T00:00:00Z",
+			},
+		},
+	})
+	if err := resourceElasticsearchOpenDistroMonitorDryrun(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if v := received.Get("dryrun"); v != "true" {
+		t.Errorf("expected dryrun to be true, got %q", v)
+	}
+	if v := received.Get("period_end"); v != "1609459200000" {
+		t.Errorf("expected period_end to be 1609459200000, got %q", v)
+	}
+
+	// the start of the period is set with the schedule of the monitor
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": `{"name": "test-monitor", "schedule": {"cron": {"expression": "0 * * * *", "timezone": "UTC"}}}`,
+		"execute_dryrun_period": []interface{}{
+			map[string]interface{}{
+				"period_start": "2020-12-31T22:00:00Z",
+				"period_end":   "2021-01-01T00:00:00Z",
+			},
+		},
+	})
+	if err := resourceElasticsearchOpenDistroMonitorDryrun(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]interface{}{"period": map[string]interface{}{"interval": float64(120), "unit": "MINUTES"}}
+	if !reflect.DeepEqual(executed["schedule"], expected) {
+		t.Errorf("expected the schedule %v for the period, got %v", expected, executed["schedule"])
+	}
+	if v := received.Get("period_end"); v != "1609459200000" {
+		t.Errorf("expected period_end to be 1609459200000, got %q", v)
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": `{"name": "test-monitor"}`,
+		"execute_dryrun_period": []interface{}{
+			map[string]interface{}{
+				"period_start": "2021-01-01T00:00:30Z",
+				"period_end":   "2021-01-01T00:00:00Z",
+			},
+		},
+	})
+	err := resourceElasticsearchOpenDistroMonitorDryrun(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "positive number of whole minutes") {
+		t.Errorf("expected an error about the period, got %v", err)
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": `{"name": "test-monitor"}`,
+		"execute_dryrun_period": []interface{}{
+			map[string]interface{}{
+				"period_end":       "2021-01-01T00:00:00Z",
+				"expect_triggered": true,
+			},
+		},
+	})
+	err = resourceElasticsearchOpenDistroMonitorDryrun(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "did not fire any trigger") {
+		t.Errorf("expected an error about the monitor not firing, got %v", err)
+	}
+	if executedPath != "/_opendistro/_alerting/monitors/_execute" {
+		t.Errorf("expected the monitor to be executed with the OpenDistro API, got %s", executedPath)
+	}
+
+	// OpenSearch executes monitors with the _plugins API
+	meta = testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "2.11.0",
+		"flavor":                "opensearch",
+	})
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": `{"name": "test-monitor"}`,
+		"execute_dryrun_period": []interface{}{
+			map[string]interface{}{
+				"period_end": "2021-01-01T00:00:00Z",
+			},
+		},
+	})
+	if err := resourceElasticsearchOpenDistroMonitorDryrun(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if executedPath != "/_plugins/_alerting/monitors/_execute" {
+		t.Errorf("expected the monitor to be executed with the _plugins API, got %s", executedPath)
+	}
+}
+
+func TestOpenDistroMonitorAutoAcknowledgeResolved(t *testing.T) {
+	var acknowledged []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_alerting/monitors/abc":
+			fmt.Fprint(w, `{"_id": "abc", "_version": 1, "_seq_no": 1, "_primary_term": 1, "monitor": {"name": "test-monitor", "triggers": []}}`)
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_alerting/monitors/alerts":
+			if q := r.URL.Query(); q.Get("monitorId") != "abc" || q.Get("alertState") != "ACTIVE" {
+				t.Errorf("expected the active alerts of the monitor to be queried, got %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"alerts": [
+  {"id": "alert-resolved", "trigger_id": "t1", "trigger_name": "errors", "state": "ACTIVE"},
+  {"id": "alert-firing", "trigger_id": "t2", "trigger_name": "latency", "state": "ACTIVE"},
+  {"id": "alert-failing", "trigger_id": "t3", "trigger_name": "broken", "state": "ACTIVE"}
+], "totalAlerts": 3}`)
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/monitors/abc/_execute":
+			if r.URL.Query().Get("dryrun") != "true" {
+				t.Error("expected the monitor to be run without performing its actions")
+			}
+			fmt.Fprint(w, `{"monitor_name": "test-monitor", "input_results": {"results": []}, "trigger_results": {
+  "t1": {"name": "errors", "triggered": false},
+  "t2": {"name": "latency", "triggered": true},
+  "t3": {"name": "broken", "triggered": false, "error": "failed to run the script"}
+}}`)
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/monitors/abc/_acknowledge/alerts":
+			var body struct {
+				Alerts []string `json:"alerts"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			acknowledged = append(acknowledged, body.Alerts...)
+			fmt.Fprint(w, `{"success": ["alert-resolved"], "failed": []}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body":                      `{"name": "test-monitor", "triggers": []}`,
+		"auto_acknowledge_resolved": true,
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroMonitorRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// alerts of triggers which still fire, or fail to run, stay active
+	if len(acknowledged) != 1 || acknowledged[0] != "alert-resolved" {
+		t.Errorf("expected only the resolved alert to be acknowledged, got %v", acknowledged)
+	}
+}
+
+func TestOpenDistroMonitorBodyTooLarge(t *testing.T) {
+	var received int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/_opendistro/_alerting/monitors/":
+			body, _ := ioutil.ReadAll(r.Body)
+			received = len(body)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case r.Method == "GET" && r.URL.Path == "/_cluster/settings":
+			fmt.Fprint(w, `{"persistent": {}, "transient": {}, "defaults": {"http.max_content_length": "1mb"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	// a query of terms adding up to 2mb, exceeding the limit of 1mb
+	terms := `"` + strings.Repeat("x", 1022) + `"`
+	terms = strings.Repeat(terms+",", 2048) + terms
+	monitorJSON := fmt.Sprintf(`{"name": "test-monitor", "inputs": [{"search": {"indices": ["logs"], "query": {"query": {"terms": {"message": [%s]}}}}}], "triggers": []}`, terms)
+	resourceData := schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": monitorJSON,
+	})
+	err := resourceElasticsearchOpenDistroMonitorCreate(resourceData, meta)
+	if received != len(monitorJSON) {
+		t.Errorf("expected the body of %d bytes to be sent as is, got %d bytes", len(monitorJSON), received)
+	}
+	expected := fmt.Sprintf("the monitor body of %d bytes exceeds http.max_content_length of the cluster (1mb)", len(monitorJSON))
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected an error containing %q, got %v", expected, err)
+	}
+}
+
+func TestOpenDistroMonitorValidateTemplates(t *testing.T) {
+	var rendered []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_render/template" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		var body struct {
+			Source string `json:"source"`
+			Params struct {
+				Ctx map[string]interface{} `json:"ctx"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if _, ok := body.Params.Ctx["monitor"]; !ok {
+			t.Errorf("expected a mocked ctx.monitor, got %v", body.Params.Ctx)
+		}
+		rendered = append(rendered, body.Source)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Count(body.Source, "{{") != strings.Count(body.Source, "}}") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": {"type": "mustache_exception", "reason": "Improperly closed variable"}, "status": 400}`)
+			return
+		}
+		fmt.Fprint(w, `{"template_output": {"value": "rendered"}}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	monitor := func(message string) string {
+		return fmt.Sprintf(`{
+  "name": "test-monitor",
+  "triggers": [{
+    "query_level_trigger": {
+      "name": "any-hits",
+      "severity": "1",
+      "actions": [{
+        "name": "notify",
+        "destination_id": "abc",
+        "subject_template": {"source": "Monitor {{ctx.monitor.name}} fired"},
+        "message_template": {"source": %q}
+      }]
+    }
+  }]
+}`, message)
+	}
+
+	resourceData := schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body":                      monitor("Trigger {{ctx.trigger.name}} fired"),
+		"validate_action_templates": true,
+	})
+	if err := resourceElasticsearchOpenDistroMonitorValidateTemplates(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(rendered) != 2 {
+		t.Fatalf("expected the subject and message templates to be rendered, got %v", rendered)
+	}
+	if expected := `{"value": "Trigger {{ctx.trigger.name}} fired"}`; rendered[1] != expected {
+		t.Errorf("expected the template to be rendered as %s, got %s", expected, rendered[1])
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body":                      monitor("Trigger {{ctx.trigger.name fired"),
+		"validate_action_templates": true,
+	})
+	err := resourceElasticsearchOpenDistroMonitorValidateTemplates(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), `message_template of action "notify" of trigger "any-hits"`) {
+		t.Errorf("expected an error about the unclosed tag of the message template, got %v", err)
+	}
+
+	// templates aren't rendered unless enabled
+	rendered = nil
+	resourceData = schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": monitor("Trigger {{ctx.trigger.name fired"),
+	})
+	if err := resourceElasticsearchOpenDistroMonitorValidateTemplates(resourceData, meta); err != nil || len(rendered) != 0 {
+		t.Errorf("expected no templates to be rendered, got %v: %v", rendered, err)
+	}
+}
+
+func TestOpenDistroMonitorEnabledTime(t *testing.T) {
+	disabled := `{
+  "name": "test-monitor",
+  "enabled": false,
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [],
+  "triggers": []
+}`
+	enabled := strings.Replace(disabled, `"enabled": false`, `"enabled": true`, 1)
+
+	// the server sets enabled_time when the monitor is enabled
+	readDisabled := strings.Replace(disabled, `"enabled": false,`, `"enabled": false, "enabled_time": null, "last_update_time": 1609455600000,`, 1)
+	readEnabled := strings.Replace(enabled, `"enabled": true,`, `"enabled": true, "enabled_time": 1609459200000, "last_update_time": 1609459200000,`, 1)
+
+	if diffSuppressMonitor("body", readDisabled, enabled, nil) {
+		t.Error("expected enabling the monitor to be a diff")
+	}
+	if !diffSuppressMonitor("body", readEnabled, enabled, nil) {
+		t.Error("expected the enabled_time set by the server not to be a diff")
+	}
+	if !diffSuppressMonitor("body", readDisabled, disabled, nil) {
+		t.Error("expected a null enabled_time not to be a diff")
+	}
+
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(readEnabled), &monitor); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v := monitorEnabledTime(monitor); v != "2021-01-01T00:00:00Z" {
+		t.Errorf("expected enabled_time 2021-01-01T00:00:00Z, got %q", v)
+	}
+	monitor = nil
+	if err := json.Unmarshal([]byte(readDisabled), &monitor); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v := monitorEnabledTime(monitor); v != "" {
+		t.Errorf("expected no enabled_time for a disabled monitor, got %q", v)
+	}
+}
+
+func TestOpenDistroMonitorTriggerBody(t *testing.T) {
+	for _, tc := range []struct {
+		trigger     string
+		expectedKey string
+	}{
+		{`{"name": "errors", "severity": "1", "condition": {}}`, ""},
+		{`{"bucket_level_trigger": {"name": "errors", "severity": "1", "condition": {}}}`, "bucket_level_trigger"},
+		{`{"document_level_trigger": {"name": "errors", "severity": "1", "condition": {}}}`, "document_level_trigger"},
+	} {
+		var trigger map[string]interface{}
+		if err := json.Unmarshal([]byte(tc.trigger), &trigger); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		key, body := monitorTriggerBody(trigger)
+		if key != tc.expectedKey || body["name"] != "errors" {
+			t.Errorf("expected the trigger errors wrapped in %q, got %v wrapped in %q", tc.expectedKey, body, key)
+		}
+	}
+}
+
+func TestOpenDistroMonitorAuditAttributes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/_opendistro/_alerting/monitors/abc" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"_id": "abc", "_version": 2, "_seq_no": 4, "_primary_term": 1, "monitor": {
+  "type": "monitor",
+  "name": "test-monitor",
+  "enabled": true,
+  "enabled_time": 1609459200000,
+  "last_update_time": 1609545600000,
+  "user": {"name": "alice", "backend_roles": ["ops"], "roles": ["alerting_full_access"], "custom_attribute_names": []},
+  "triggers": []
+}}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	config := `{"type": "monitor", "name": "test-monitor", "enabled": true, "triggers": []}`
+	resourceData := schema.TestResourceDataRaw(t, openDistroMonitorSchema, map[string]interface{}{
+		"body": config,
+	})
+	resourceData.SetId("abc")
+	if err := resourceElasticsearchOpenDistroMonitorRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{
+		"created_by":       "alice",
+		"last_update_time": "2021-01-02T00:00:00Z",
+		"enabled_time":     "2021-01-01T00:00:00Z",
+	}
+	for k, v := range expected {
+		if actual := resourceData.Get(k).(string); actual != v {
+			t.Errorf("expected %s %q, got %q", k, v, actual)
+		}
+	}
+	if body := resourceData.Get("body").(string); !diffSuppressMonitor("body", body, config, resourceData) {
+		t.Errorf("expected the audit fields not to be a diff, got %s", body)
+	}
+}
+
+func TestOpenDistroMonitorChainedAlertTrigger(t *testing.T) {
+	workflow := `{
+  "name": "chained",
+  "workflow_type": "composite",
+  "enabled": true,
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [{"composite_input": {"sequence": {"delegates": [{"order": 1, "monitor_id": "abc"}]}}}],
+  "triggers": [{
+    "chained_alert_trigger": {
+      "name": "both fired",
+      "severity": "1",
+      "condition": {"script": {"source": "monitor[id=abc]"}},
+      "actions": [{"name": "notify", "destination_id": "xyz"}]
+    }
+  }]
+}`
+	read := `{
+  "name": "chained",
+  "workflow_type": "composite",
+  "enabled": true,
+  "enabled_time": 1609459200000,
+  "last_update_time": 1609459200000,
+  "schema_version": 0,
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [{"composite_input": {"sequence": {"delegates": [{"order": 1, "monitor_id": "abc"}]}}}],
+  "triggers": [{
+    "chained_alert_trigger": {
+      "id": "t1",
+      "name": "both fired",
+      "severity": "1",
+      "condition": {"script": {"source": "monitor[id=abc]", "lang": "painless"}},
+      "actions": [{"id": "a1", "name": "notify", "destination_id": "xyz"}]
+    }
+  }]
+}`
+
+	if !diffSuppressMonitor("body", read, workflow, nil) {
+		t.Error("expected the chained alert trigger to round trip without a diff")
+	}
+	changed := strings.Replace(workflow, "monitor[id=abc]", "monitor[id=def]", 1)
+	if diffSuppressMonitor("body", read, changed, nil) {
+		t.Error("expected a changed condition to be a diff")
+	}
+
+	if monitorBasePath(workflow) != "/_plugins/_alerting/workflows" {
+		t.Errorf("expected workflows to be managed through the workflows API, got %s", monitorBasePath(workflow))
+	}
+	if _, errs := validateMonitorChainedAlertTriggers(workflow, "body"); len(errs) > 0 {
+		t.Errorf("expected chained alert triggers to be valid in workflows, got %v", errs)
+	}
+
+	monitor := strings.Replace(workflow, `"workflow_type": "composite",`, `"monitor_type": "query_level_monitor",`, 1)
+	if monitorBasePath(monitor) != "/_opendistro/_alerting/monitors" {
+		t.Errorf("expected monitors to be managed through the monitors API, got %s", monitorBasePath(monitor))
+	}
+	if _, errs := validateMonitorChainedAlertTriggers(monitor, "body"); len(errs) != 1 {
+		t.Errorf("expected chained alert triggers to be invalid in monitors, got %v", errs)
+	}
+}
+
+func TestOpenDistroMonitorClusterMetrics(t *testing.T) {
+	monitor := `{
+  "name": "cluster-health",
+  "monitor_type": "cluster_metrics_monitor",
+  "enabled": true,
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [{"uri": {"api_type": "CLUSTER_HEALTH", "path": "_cluster/health/"}}],
+  "triggers": [{
+    "name": "red",
+    "severity": "1",
+    "condition": {"script": {"source": "ctx.results[0].status == \"red\""}},
+    "actions": []
+  }]
+}`
+	read := `{
+  "name": "cluster-health",
+  "monitor_type": "cluster_metrics_monitor",
+  "enabled": true,
+  "enabled_time": 1609459200000,
+  "last_update_time": 1609459200000,
+  "schema_version": 5,
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [{"uri": {
+    "api_type": "CLUSTER_HEALTH",
+    "path": "_cluster/health/",
+    "path_params": "",
+    "url": "http://localhost:9200/_cluster/health/",
+    "clusters": []
+  }}],
+  "triggers": [{
+    "id": "t1",
+    "name": "red",
+    "severity": "1",
+    "condition": {"script": {"source": "ctx.results[0].status == \"red\"", "lang": "painless"}},
+    "actions": []
+  }]
+}`
+
+	if !diffSuppressMonitor("body", read, monitor, nil) {
+		t.Error("expected the cluster metrics monitor to round trip without a diff")
+	}
+	changed := strings.Replace(monitor, `"path": "_cluster/health/"`, `"path": "_cluster/health/", "path_params": "logs"`, 1)
+	if diffSuppressMonitor("body", read, changed, nil) {
+		t.Error("expected changed path_params to be a diff")
+	}
+
+	if _, errs := validateMonitorClusterMetricsInputs(monitor, "body"); len(errs) > 0 {
+		t.Errorf("expected CLUSTER_HEALTH to be valid, got %v", errs)
+	}
+	invalid := strings.Replace(monitor, "CLUSTER_HEALTH", "CLUSTER_HEALTHY", 1)
+	if _, errs := validateMonitorClusterMetricsInputs(invalid, "body"); len(errs) != 1 {
+		t.Errorf("expected an unknown api_type to be invalid, got %v", errs)
+	}
+}
+
+func TestOpenDistroMonitorSeverityRouting(t *testing.T) {
+	body := `{
+  "name": "errors",
+  "monitor_type": "query_level_monitor",
+  "triggers": [{
+    "name": "outage",
+    "severity": "1",
+    "actions": [
+      {"name": "page", "destination_id": "pagerduty"},
+      {"name": "chat", "destination_id": "dev-slack"},
+      {"name": "untagged", "destination_id": "email"}
+    ]
+  }, {
+    "bucket_level_trigger": {
+      "name": "noisy host",
+      "severity": "4",
+      "actions": [{"name": "chat", "destination_id": "dev-slack"}]
+    }
+  }]
+}`
+	var monitor map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &monitor); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	severityTags := map[string]interface{}{"1": "pager", "4": "dev"}
+	destinationTags := map[string]interface{}{"pagerduty": "pager", "dev-slack": "dev"}
+
+	warnings := monitorSeverityRoutingWarnings(monitor, severityTags, destinationTags)
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], `trigger "outage"`) || !strings.Contains(warnings[0], `tagged "dev" instead of "pager"`) {
+		t.Errorf("unexpected warning %s", warnings[0])
+	}
+
+	// the warnings are shown in the plan
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body": body,
+		"severity_routing": []interface{}{map[string]interface{}{
+			"severity_tags":    map[string]interface{}{"1": "pager", "4": "dev"},
+			"destination_tags": destinationTags,
+		}},
+	})
+	diff, err := resourceElasticsearchOpenDistroMonitor().Diff(nil, config, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if attr := diff.Attributes["severity_routing_warnings.0"]; attr == nil || attr.New != warnings[0] {
+		t.Errorf("expected the warning to be planned, got %v", diff.Attributes)
+	}
+
+	delete(severityTags, "1")
+	if warnings := monitorSeverityRoutingWarnings(monitor, severityTags, destinationTags); len(warnings) != 0 {
+		t.Errorf("expected severities without a tag not to be checked, got %v", warnings)
+	}
+}
+
+func TestOpenDistroMonitorValidateOnPlan(t *testing.T) {
+	body := `{
+  "name": "errors",
+  "type": "monitor",
+  "schedule": {"period": {"interval": 1, "unit": "MINUTES"}},
+  "inputs": [{"search": {"indices": ["logs"], "query": {"size": 0}}}],
+  "triggers": [{
+    "name": "outage",
+    "severity": "1",
+    "actions": [{"name": "page"}]
+  }]
+}`
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":             body,
+		"validate_on_plan": true,
+	})
+	_, err := resourceElasticsearchOpenDistroMonitor().Diff(nil, config, nil)
+	if err == nil || !strings.Contains(err.Error(), "missing the required fields triggers[0].condition, triggers[0].actions[0].destination_id") {
+		t.Errorf("expected an error about the missing condition and destination, got %v", err)
+	}
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body": body,
+	})
+	if _, err := resourceElasticsearchOpenDistroMonitor().Diff(nil, config, nil); err != nil {
+		t.Errorf("expected the body not to be checked without validate_on_plan, got %s", err)
+	}
+
+	if missing := monitorMissingFields(`{"name": "errors", "schedule": {"cron": {"expression": "0 * * * *"}}, "inputs": [{}], "triggers": [{"bucket_level_trigger": {"name": "noisy", "severity": "4", "condition": {}}}]}`); len(missing) != 1 || missing[0] != "triggers[0].bucket_level_trigger.condition" {
+		t.Errorf("expected the condition of the bucket level trigger to be missing, got %v", missing)
+	}
+}
+
 func testCheckElasticsearchOpenDistroMonitorExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -163,3 +787,63 @@ resource "elasticsearch_opendistro_monitor" "test_monitor" {
 EOF
 }
 `
+
+var testAccElasticsearchOpenDistroMonitorWithDestination = `
+resource "elasticsearch_opendistro_destination" "test_destination" {
+  body = <<EOF
+{
+  "name": "my-destination",
+  "type": "slack",
+  "slack": {
+    "url": "http://www.example.com"
+  }
+}
+EOF
+}
+
+resource "elasticsearch_opendistro_monitor" "test_monitor" {
+  body = <<EOF
+{
+  "name": "test-monitor",
+  "type": "monitor",
+  "enabled": true,
+  "schedule": {
+    "period": {
+      "interval": 1,
+      "unit": "MINUTES"
+    }
+  },
+  "inputs": [{
+    "search": {
+      "indices": ["movies"],
+      "query": {
+        "size": 0,
+        "aggregations": {},
+        "query": {
+          "match_all": {}
+        }
+      }
+    }
+  }],
+  "triggers": [{
+    "name": "any-hits",
+    "severity": "1",
+    "condition": {
+      "script": {
+        "source": "ctx.results[0].hits.total.value > 0",
+        "lang": "painless"
+      }
+    },
+    "actions": [{
+      "name": "notify",
+      "destination_id": "${elasticsearch_opendistro_destination.test_destination.destination_id}",
+      "message_template": {
+        "source": "Monitor {{ctx.monitor.name}} triggered"
+      },
+      "throttle_enabled": false
+    }]
+  }]
+}
+EOF
+}
+`
@@ -3,9 +3,10 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/olivere/elastic/uritemplates"
@@ -44,8 +45,9 @@ func resourceElasticsearchOpenDistroRole() *schema.Resource {
 							Set: schema.HashString,
 						},
 						"document_level_security": {
-							Type:     schema.TypeString,
-							Optional: true,
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: diffSuppressDocumentLevelSecurity,
 						},
 						"fls": {
 							Type:     schema.TypeSet,
@@ -113,6 +115,12 @@ func resourceElasticsearchOpenDistroRole() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"validate_references": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check that the tenants named in `tenant_patterns`, other than patterns with wildcards, exist before putting the role.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -121,6 +129,10 @@ func resourceElasticsearchOpenDistroRole() *schema.Resource {
 }
 
 func resourceElasticsearchOpenDistroRoleCreate(d *schema.ResourceData, m interface{}) error {
+	if err := resourceElasticsearchOpenDistroRoleValidateReferences(d, m); err != nil {
+		return err
+	}
+
 	if _, err := resourceElasticsearchPutOpenDistroRole(d, m); err != nil {
 		log.Printf("[INFO] Failed to create OpenDistroRole: %+v", err)
 		return err
@@ -163,6 +175,10 @@ func resourceElasticsearchOpenDistroRoleRead(d *schema.ResourceData, m interface
 }
 
 func resourceElasticsearchOpenDistroRoleUpdate(d *schema.ResourceData, m interface{}) error {
+	if err := resourceElasticsearchOpenDistroRoleValidateReferences(d, m); err != nil {
+		return err
+	}
+
 	if _, err := resourceElasticsearchPutOpenDistroRole(d, m); err != nil {
 		return err
 	}
@@ -171,6 +187,13 @@ func resourceElasticsearchOpenDistroRoleUpdate(d *schema.ResourceData, m interfa
 }
 
 func resourceElasticsearchOpenDistroRoleDelete(d *schema.ResourceData, m interface{}) error {
+	if batcher := m.(*ProviderConf).securityBatcher; batcher != nil {
+		return batcher.add("/_opendistro/_security/api/roles", patchOperation{
+			Op:   "remove",
+			Path: jsonPointer(d.Get("role_name").(string)),
+		})
+	}
+
 	path, err := uritemplates.Expand("/_opendistro/_security/api/roles/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
@@ -189,12 +212,102 @@ func resourceElasticsearchOpenDistroRoleDelete(d *schema.ResourceData, m interfa
 			Path:   path,
 		})
 	default:
-		err = errors.New("role resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role", MinimumVersion: "v7"}
+	}
+
+	// the security plugin refuses to delete reserved roles with a generic 403
+	if elastic7.IsForbidden(err) {
+		if role, getErr := resourceElasticsearchGetOpenDistroRole(d.Id(), m); getErr == nil && role.Reserved {
+			return fmt.Errorf("role %s is reserved and cannot be deleted, remove it from the state with `terraform state rm` instead", d.Id())
+		}
 	}
 
 	return err
 }
 
+// resourceElasticsearchOpenDistroRoleValidateReferences fails when a tenant
+// named in the tenant permissions doesn't exist, instead of creating a role
+// which grants access to nothing.
+func resourceElasticsearchOpenDistroRoleValidateReferences(d *schema.ResourceData, m interface{}) error {
+	if !d.Get("validate_references").(bool) {
+		return nil
+	}
+
+	tenantPermissions, err := expandTenantPermissionsSet(d.Get("tenant_permissions").(*schema.Set).List())
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, permission := range tenantPermissions {
+		for _, pattern := range permission.TenantPatterns {
+			// the private tenant of each user isn't listed by the tenants API
+			if strings.ContainsAny(pattern, "*?") || pattern == "__user__" {
+				continue
+			}
+			names = append(names, pattern)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tenants, err := resourceElasticsearchOpenDistroTenantNames(m)
+	if err != nil {
+		return fmt.Errorf("error listing tenants to validate the role: %+v", err)
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !tenants[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("role %s references tenants which don't exist: %s", d.Get("role_name").(string), strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func resourceElasticsearchOpenDistroTenantNames(m interface{}) (map[string]bool, error) {
+	var body json.RawMessage
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_opendistro/_security/api/tenants",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "role", MinimumVersion: "v7"}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the tenants are returned as a map of the tenant name to the tenant
+	var tenants map[string]json.RawMessage
+	if err := json.Unmarshal(body, &tenants); err != nil {
+		return nil, fmt.Errorf("error unmarshalling tenants body: %+v: %s", err, body)
+	}
+
+	names := make(map[string]bool, len(tenants))
+	for name := range tenants {
+		names[name] = true
+	}
+
+	return names, nil
+}
+
 func resourceElasticsearchGetOpenDistroRole(roleID string, m interface{}) (RoleBody, error) {
 	var err error
 	role := new(RoleBody)
@@ -221,19 +334,24 @@ func resourceElasticsearchGetOpenDistroRole(roleID string, m interface{}) (RoleB
 		})
 		body = res.Body
 	default:
-		err = errors.New("role resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
 		return *role, err
 	}
+	// reads return a map of the role name to the role
 	var roleDefinition map[string]RoleBody
 
 	if err := json.Unmarshal(body, &roleDefinition); err != nil {
 		return *role, fmt.Errorf("error unmarshalling role body: %+v: %+v", err, body)
 	}
 
-	*role = roleDefinition[roleID]
+	definition, ok := roleDefinition[roleID]
+	if !ok {
+		return *role, fmt.Errorf("role %s missing from response: %s", roleID, body)
+	}
+	*role = definition
 
 	return *role, err
 }
@@ -282,6 +400,19 @@ func resourceElasticsearchPutOpenDistroRole(d *schema.ResourceData, m interface{
 		return response, fmt.Errorf("Body Error : %s", roleJSON)
 	}
 
+	if batcher := m.(*ProviderConf).securityBatcher; batcher != nil {
+		err := batcher.add("/_opendistro/_security/api/roles", patchOperation{
+			Op:    "add",
+			Path:  jsonPointer(d.Get("role_name").(string)),
+			Value: rolesDefinition,
+		})
+		if err != nil {
+			return response, fmt.Errorf("error creating role: %+v", err)
+		}
+		response.Status = "OK"
+		return response, nil
+	}
+
 	path, err := uritemplates.Expand("/_opendistro/_security/api/roles/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
@@ -297,20 +428,21 @@ func resourceElasticsearchPutOpenDistroRole(d *schema.ResourceData, m interface{
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = securityPerformRequest(client, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   string(roleJSON),
 		})
 		body = res.Body
 	default:
-		err = errors.New("role resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
 		return response, fmt.Errorf("error creating role: %+v: %+v", err, body)
 	}
 
+	// writes return a status and message instead of the role
 	if err := json.Unmarshal(body, response); err != nil {
 		return response, fmt.Errorf("error unmarshalling role body: %+v: %+v", err, body)
 	}
@@ -328,6 +460,7 @@ type RoleBody struct {
 	ClusterPermissions []string            `json:"cluster_permissions,omitempty"`
 	IndexPermissions   []IndexPermissions  `json:"index_permissions,omitempty"`
 	TenantPermissions  []TenantPermissions `json:"tenant_permissions,omitempty"`
+	Reserved           bool                `json:"reserved,omitempty"`
 }
 
 type IndexPermissions struct {
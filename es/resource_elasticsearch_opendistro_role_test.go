@@ -2,7 +2,12 @@ package es
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
@@ -134,8 +139,280 @@ func TestAccElasticsearchOpenDistroRole(t *testing.T) {
 					),
 				),
 			},
+			{
+				Config: testAccOpenDistroRoleResourceDocumentLevelSecurityTemplate(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticSearchOpenDistroRoleExists("elasticsearch_opendistro_role.test"),
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opendistro_role.test",
+						"index_permissions.#",
+						"1",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestOpenDistroRoleReadIndexPermissions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_opendistro/_security/api/roles/reader" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "reader": {
+    "reserved": false,
+    "hidden": false,
+    "description": "Read logs",
+    "cluster_permissions": ["cluster_composite_ops_ro"],
+    "index_permissions": [{
+      "index_patterns": ["logs-*"],
+      "dls": "{\"match\": {\"public\": true}}",
+      "fls": ["~secret"],
+      "masked_fields": ["ip"],
+      "allowed_actions": ["read"]
+    }],
+    "tenant_permissions": [],
+    "static": false
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{})
+	resourceData.SetId("reader")
+	if err := resourceElasticsearchOpenDistroRoleRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	permissions := resourceData.Get("index_permissions").(*schema.Set).List()
+	if len(permissions) != 1 {
+		t.Fatalf("expected 1 index permission, got %d", len(permissions))
+	}
+	permission := permissions[0].(map[string]interface{})
+	if dls := permission["document_level_security"]; dls != `{"match": {"public": true}}` {
+		t.Errorf("unexpected document_level_security %v", dls)
+	}
+	for key, expected := range map[string]string{
+		"index_patterns":       "logs-*",
+		"field_level_security": "~secret",
+		"masked_fields":        "ip",
+		"allowed_actions":      "read",
+	} {
+		values := permission[key].(*schema.Set).List()
+		if len(values) != 1 || values[0] != expected {
+			t.Errorf("expected %s to be [%s], got %v", key, expected, values)
+		}
+	}
+	if description := resourceData.Get("description"); description != "Read logs" {
+		t.Errorf("unexpected description %v", description)
+	}
+}
+
+func TestOpenDistroRoleDeleteReserved(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"status": "FORBIDDEN", "message": "Resource 'kibana_user' is read-only."}`)
+			return
+		}
+		fmt.Fprint(w, `{"kibana_user": {"reserved": true, "cluster_permissions": ["cluster_composite_ops"]}}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{
+		"role_name": "kibana_user",
+	})
+	resourceData.SetId("kibana_user")
+
+	err := resourceElasticsearchOpenDistroRoleDelete(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "role kibana_user is reserved") {
+		t.Errorf("expected a reserved role error, got %v", err)
+	}
+}
+
+func TestOpenDistroRolePutConflict(t *testing.T) {
+	defer func(backoff time.Duration) { securityConflictBackoff = backoff }(securityConflictBackoff)
+	securityConflictBackoff = time.Millisecond
+
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests = append(requests, r.Method)
+		switch r.Method {
+		case "PUT":
+			// the first two writes conflict with concurrent writes
+			if len(requests) < 3 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"status": "CONFLICT", "message": "version conflict, document already exists"}`)
+				return
+			}
+			fmt.Fprint(w, `{"status": "CREATED", "message": "'reader' created."}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{
+		"role_name":           "reader",
+		"cluster_permissions": []interface{}{"cluster_monitor"},
+	})
+	response, err := resourceElasticsearchPutOpenDistroRole(resourceData, testProviderMeta(t, ts.URL))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if response.Status != "CREATED" {
+		t.Errorf("expected the role to be created, got %s", response.Status)
+	}
+
+	// the same write is retried after the backoff
+	expected := []string{"PUT", "PUT", "PUT"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected the requests %v, got %v", expected, requests)
+	}
+
+	// the conflict is returned once the retries are exhausted
+	conflicts := 0
+	conflicting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conflicts++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"status": "CONFLICT", "message": "version conflict, document already exists"}`)
+	}))
+	defer conflicting.Close()
+
+	_, err = resourceElasticsearchPutOpenDistroRole(resourceData, testProviderMeta(t, conflicting.URL))
+	if err == nil {
+		t.Error("expected the conflict to be returned")
+	}
+	if conflicts != securityConflictRetries+1 {
+		t.Errorf("expected %d writes, got %d", securityConflictRetries+1, conflicts)
+	}
+}
+
+func TestOpenDistroRoleValidateReferences(t *testing.T) {
+	var put bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_security/api/tenants":
+			fmt.Fprint(w, `{"global_tenant": {"reserved": true}, "analysts": {"description": "Analysts"}}`)
+		case r.Method == "PUT":
+			put = true
+			fmt.Fprint(w, `{"status": "CREATED"}`)
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_security/api/roles/reader":
+			fmt.Fprint(w, `{"reader": {"tenant_permissions": [{"tenant_patterns": ["analysts", "team-*"], "allowed_actions": ["kibana_all_read"]}]}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	roleSchema := resourceElasticsearchOpenDistroRole().Schema
+	resourceData := schema.TestResourceDataRaw(t, roleSchema, map[string]interface{}{
+		"role_name":           "reader",
+		"validate_references": true,
+		"tenant_permissions": []interface{}{
+			map[string]interface{}{
+				"tenant_patterns": []interface{}{"analysts", "missing", "team-*"},
+				"allowed_actions": []interface{}{"kibana_all_read"},
+			},
+		},
+	})
+	err := resourceElasticsearchOpenDistroRoleCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "references tenants which don't exist: missing") {
+		t.Errorf("expected an error about the missing tenant, got %v", err)
+	}
+	if put {
+		t.Error("expected the role not to be put")
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, roleSchema, map[string]interface{}{
+		"role_name":           "reader",
+		"validate_references": true,
+		"tenant_permissions": []interface{}{
+			map[string]interface{}{
+				"tenant_patterns": []interface{}{"analysts", "team-*"},
+				"allowed_actions": []interface{}{"kibana_all_read"},
+			},
+		},
+	})
+	if err := resourceElasticsearchOpenDistroRoleCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !put {
+		t.Error("expected the role to be put")
+	}
+}
+
+func TestNormalizedDocumentLevelSecurity(t *testing.T) {
+	cases := []struct {
+		dls      string
+		expected string
+	}{
+		{`{"term": { "readable_by": "${user.name}"}}`, `{"term":{"readable_by":"${user.name}"}}`},
+		{`{"terms": {"department": ${attr.internal.departments}}}`, `{"terms":{"department":${attr.internal.departments}}}`},
+		{`{"terms": {"roles": [${user.roles}], "owner": "\"${user.name}"}}`, `{"terms":{"owner":"\"${user.name}","roles":[${user.roles}]}}`},
+		{`{"term": {"readable_by": ${user.name`, `{"term": {"readable_by": ${user.name`},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if actual := normalizedDocumentLevelSecurity(c.dls); actual != c.expected {
+			t.Errorf("expected %s to be normalized to %s, got %s", c.dls, c.expected, actual)
+		}
+	}
+}
+
+func TestOpenDistroRoleDocumentLevelSecurityTemplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+  "owner": {
+    "index_permissions": [{
+      "index_patterns": ["logs-*"],
+      "dls": "{\"term\":{\"readable_by\":\"${user.name}\"}}",
+      "allowed_actions": ["read"]
+    }]
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	configured := `{"term": { "readable_by": "${user.name}"}}`
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{
+		"role_name": "owner",
+		"index_permissions": []interface{}{
+			map[string]interface{}{
+				"index_patterns":          []interface{}{"logs-*"},
+				"document_level_security": configured,
+				"allowed_actions":         []interface{}{"read"},
+			},
 		},
 	})
+	resourceData.SetId("owner")
+	configuredHash := indexPermissionsHash(resourceData.Get("index_permissions").(*schema.Set).List()[0])
+
+	if err := resourceElasticsearchOpenDistroRoleRead(resourceData, testProviderMeta(t, ts.URL)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	permissions := resourceData.Get("index_permissions").(*schema.Set).List()
+	if len(permissions) != 1 {
+		t.Fatalf("expected 1 index permission, got %d", len(permissions))
+	}
+	if hash := indexPermissionsHash(permissions[0]); hash != configuredHash {
+		t.Errorf("expected the index permission to keep its hash %d, got %d", configuredHash, hash)
+	}
+	read := permissions[0].(map[string]interface{})["document_level_security"].(string)
+	if !diffSuppressDocumentLevelSecurity("", read, configured, resourceData) {
+		t.Errorf("expected %s and %s to be equivalent", read, configured)
+	}
 }
 
 func TestAccElasticsearchOpenDistroRole_importBasic(t *testing.T) {
@@ -381,3 +658,20 @@ func testAccOpenDistroRoleResourceFieldLevelSecurity(resourceName string) string
 	}
 	`, resourceName)
 }
+
+func testAccOpenDistroRoleResourceDocumentLevelSecurityTemplate(resourceName string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_opendistro_role" "test" {
+		role_name = "%s"
+		description = "test"
+
+	  index_permissions {
+	    index_patterns  = ["pub*"]
+	    allowed_actions = ["read"]
+	    document_level_security = "{\"term\": { \"readable_by\": \"$${user.name}\"}}"
+	  }
+
+		cluster_permissions = ["*"]
+	}
+	`, resourceName)
+}
@@ -3,9 +3,10 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/olivere/elastic/uritemplates"
@@ -79,6 +80,10 @@ func resourceElasticsearchOpenDistroRolesMappingRead(d *schema.ResourceData, m i
 		return err
 	}
 
+	if res.Reserved {
+		log.Printf("[WARN] OpenDistroRolesMapping (%s) is reserved and can't be modified", d.Id())
+	}
+
 	if err := d.Set("role_name", d.Id()); err != nil {
 		return fmt.Errorf("error setting role_name: %s", err)
 	}
@@ -110,6 +115,13 @@ func resourceElasticsearchOpenDistroRolesMappingUpdate(d *schema.ResourceData, m
 }
 
 func resourceElasticsearchOpenDistroRolesMappingDelete(d *schema.ResourceData, m interface{}) error {
+	if batcher := m.(*ProviderConf).securityBatcher; batcher != nil {
+		return batcher.add("/_opendistro/_security/api/rolesmapping", patchOperation{
+			Op:   "remove",
+			Path: jsonPointer(d.Get("role_name").(string)),
+		})
+	}
+
 	path, err := uritemplates.Expand("/_opendistro/_security/api/rolesmapping/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
@@ -121,14 +133,24 @@ func resourceElasticsearchOpenDistroRolesMappingDelete(d *schema.ResourceData, m
 	if err != nil {
 		return err
 	}
+	var message string
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   path,
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "DELETE",
+			Path:         path,
+			IgnoreErrors: securityProtectedStatuses,
 		})
+		message, err = checkSecurityResponse(res, err)
 	default:
-		err = errors.New("role mapping resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role mapping", MinimumVersion: "v7"}
+	}
+
+	if err != nil {
+		if message != "" {
+			return fmt.Errorf("role mapping %s is reserved or hidden and cannot be deleted, remove it from the state with `terraform state rm` instead: %s", d.Id(), message)
+		}
 	}
 
 	return err
@@ -157,9 +179,11 @@ func resourceElasticsearchGetOpenDistroRolesMapping(roleID string, m interface{}
 			Method: "GET",
 			Path:   path,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("role mapping  resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role mapping", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -171,7 +195,11 @@ func resourceElasticsearchGetOpenDistroRolesMapping(roleID string, m interface{}
 		return *roleMapping, fmt.Errorf("error unmarshalling role mapping body: %+v: %+v", err, body)
 	}
 
-	*roleMapping = rolesMappingDefinition[roleID]
+	definition, ok := rolesMappingDefinition[roleID]
+	if !ok {
+		return *roleMapping, fmt.Errorf("role mapping %s missing from response: %s", roleID, body)
+	}
+	*roleMapping = definition
 
 	return *roleMapping, err
 }
@@ -193,6 +221,19 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 		return response, fmt.Errorf("Body Error : %s", roleJSON)
 	}
 
+	if batcher := m.(*ProviderConf).securityBatcher; batcher != nil {
+		err := batcher.add("/_opendistro/_security/api/rolesmapping", patchOperation{
+			Op:    "add",
+			Path:  jsonPointer(d.Get("role_name").(string)),
+			Value: rolesMappingDefinition,
+		})
+		if err != nil {
+			return response, fmt.Errorf("error creating role mapping: %+v", err)
+		}
+		response.Status = "OK"
+		return response, nil
+	}
+
 	path, err := uritemplates.Expand("/_opendistro/_security/api/rolesmapping/{name}", map[string]string{
 		"name": d.Get("role_name").(string),
 	})
@@ -202,6 +243,7 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 	}
 
 	var body json.RawMessage
+	var message string
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
@@ -209,17 +251,24 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "PUT",
-			Path:   path,
-			Body:   string(roleJSON),
+		res, err = securityPerformRequest(client, elastic7.PerformRequestOptions{
+			Method:       "PUT",
+			Path:         path,
+			Body:         string(roleJSON),
+			IgnoreErrors: securityProtectedStatuses,
 		})
-		body = res.Body
+		message, err = checkSecurityResponse(res, err)
+		if err == nil {
+			body = res.Body
+		}
 	default:
-		err = errors.New("role mapping resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "role mapping", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
+		if message != "" {
+			return response, fmt.Errorf("role mapping %s is reserved or hidden and cannot be modified: %s", d.Get("role_name").(string), message)
+		}
 		return response, fmt.Errorf("error creating role mapping: %+v: %+v", err, body)
 	}
 
@@ -230,6 +279,38 @@ func resourceElasticsearchPutOpenDistroRolesMapping(d *schema.ResourceData, m in
 	return response, nil
 }
 
+// securityProtectedStatuses are the statuses of the responses of the security
+// API rejecting changes of reserved or hidden resources. The client empties
+// the bodies of errors, so requests ignore them to read their message with
+// checkSecurityResponse.
+var securityProtectedStatuses = []int{http.StatusForbidden, http.StatusNotFound}
+
+// checkSecurityResponse returns the error of a response of the security API
+// requested ignoring securityProtectedStatuses, and the message of the
+// response when it rejects changing a reserved or hidden resource.
+func checkSecurityResponse(res *elastic7.Response, err error) (string, error) {
+	if err != nil || res == nil || res.StatusCode < http.StatusMultipleChoices {
+		return "", err
+	}
+
+	response := new(RoleMappingResponse)
+	if err := json.Unmarshal(res.Body, response); err != nil || response.Message == "" {
+		return "", &elastic7.Error{Status: res.StatusCode}
+	}
+	err = &elastic7.Error{Status: res.StatusCode, Details: &elastic7.ErrorDetails{
+		Type:   response.Status,
+		Reason: response.Message,
+	}}
+
+	for _, fragment := range []string{"is reserved", "is read-only", "is not available"} {
+		if strings.Contains(response.Message, fragment) {
+			return response.Message, err
+		}
+	}
+
+	return "", err
+}
+
 type RoleMappingResponse struct {
 	Message string `json:"message"`
 	Status  string `json:"status"`
@@ -241,4 +322,6 @@ type RolesMapping struct {
 	Users           []string `json:"users"`
 	Description     string   `json:"description"`
 	AndBackendRoles []string `json:"and_backend_roles"`
+	Reserved        bool     `json:"reserved,omitempty"`
+	Hidden          bool     `json:"hidden,omitempty"`
 }
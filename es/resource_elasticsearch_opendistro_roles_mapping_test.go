@@ -2,6 +2,11 @@ package es
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -78,12 +83,71 @@ func TestAccElasticsearchOpenDistroRolesMapping(t *testing.T) {
 						"backend_roles.#",
 						"2",
 					),
+					testCheckElasticSearchOpenDistroRolesMappingBackendRoles("readall", "active_directory", "ldap"),
+				),
+			},
+			{
+				Config: testAccOpenDistroRoleMappingResourceRemoved(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opendistro_roles_mapping.test",
+						"backend_roles.#",
+						"1",
+					),
+					testCheckElasticSearchOpenDistroRolesMappingBackendRoles("readall", "ldap"),
 				),
 			},
 		},
 	})
 }
 
+func TestOpenDistroRolesMappingProtected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_opendistro/_security/api/rolesmapping/all_access":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"status": "FORBIDDEN", "message": "Resource 'all_access' is reserved."}`)
+		case "/_opendistro/_security/api/rolesmapping/hidden_role":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status": "NOT_FOUND", "message": "Resource 'hidden_role' is not available."}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status": "BAD_REQUEST", "message": "Invalid configuration"}`)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+	schemaMap := resourceElasticsearchOpenDistroRolesMapping().Schema
+
+	reserved := schema.TestResourceDataRaw(t, schemaMap, map[string]interface{}{
+		"role_name":     "all_access",
+		"backend_roles": []interface{}{"admin"},
+	})
+	_, err := resourceElasticsearchPutOpenDistroRolesMapping(reserved, meta)
+	if err == nil || !strings.Contains(err.Error(), "role mapping all_access is reserved or hidden and cannot be modified") {
+		t.Errorf("expected a reserved role mapping error, got %v", err)
+	}
+
+	hidden := schema.TestResourceDataRaw(t, schemaMap, map[string]interface{}{
+		"role_name": "hidden_role",
+	})
+	hidden.SetId("hidden_role")
+	err = resourceElasticsearchOpenDistroRolesMappingDelete(hidden, meta)
+	if err == nil || !strings.Contains(err.Error(), "role mapping hidden_role is reserved or hidden and cannot be deleted") {
+		t.Errorf("expected a hidden role mapping error, got %v", err)
+	}
+
+	invalid := schema.TestResourceDataRaw(t, schemaMap, map[string]interface{}{
+		"role_name": "invalid",
+	})
+	_, err = resourceElasticsearchPutOpenDistroRolesMapping(invalid, meta)
+	if err == nil || strings.Contains(err.Error(), "reserved or hidden") {
+		t.Errorf("expected a generic error, got %v", err)
+	}
+}
+
 func testAccCheckElasticsearchOpenDistroRolesMappingDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_opendistro_roles_mappings_mapping" {
@@ -143,6 +207,24 @@ func testCheckElasticSearchOpenDistroRolesMappingExists(name string) resource.Te
 	}
 }
 
+func testCheckElasticSearchOpenDistroRolesMappingBackendRoles(roleName string, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		meta := testAccOpendistroProvider.Meta()
+
+		mapping, err := resourceElasticsearchGetOpenDistroRolesMapping(roleName, meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(mapping.BackendRoles)
+		if !reflect.DeepEqual(mapping.BackendRoles, expected) {
+			return fmt.Errorf("expected backend roles %v, got %v", expected, mapping.BackendRoles)
+		}
+
+		return nil
+	}
+}
+
 func testAccOpenDistroRolesMappingResource(resourceName string) string {
 	return fmt.Sprintf(`
 	resource "elasticsearch_opendistro_roles_mapping" "test" {
@@ -169,3 +251,16 @@ func testAccOpenDistroRoleMappingResourceUpdated(resourceName string) string {
 	}
 	`, resourceName)
 }
+
+func testAccOpenDistroRoleMappingResourceRemoved(resourceName string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_opendistro_roles_mapping" "test" {
+		role_name = "readall"
+		backend_roles = [
+			"ldap",
+		]
+
+		description = "%s update"
+	}
+	`, resourceName)
+}
@@ -3,7 +3,6 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 
@@ -38,11 +37,22 @@ func resourceElasticsearchOpenDistroUser() *schema.Resource {
 				StateFunc:     hashSum,
 				ConflictsWith: []string{"password"},
 			},
+			"password_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "An arbitrary value, e.g. a rotation date, that sends the password again when changed, even if the password itself is unchanged. The API never returns the password, so this resets passwords changed outside of Terraform.",
+			},
 			"backend_roles": {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"opendistro_security_roles": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The security roles of the user, mapped to it directly instead of through a roles mapping.",
+			},
 			"attributes": {
 				Type:     schema.TypeMap,
 				Optional: true,
@@ -85,6 +95,7 @@ func resourceElasticsearchOpenDistroUserRead(d *schema.ResourceData, m interface
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("backend_roles", res.BackendRoles)
+	ds.set("opendistro_security_roles", res.SecurityRoles)
 	ds.set("attributes", res.Attributes)
 	ds.set("description", res.Description)
 	return ds.err
@@ -119,7 +130,7 @@ func resourceElasticsearchOpenDistroUserDelete(d *schema.ResourceData, m interfa
 			Path:   path,
 		})
 	default:
-		err = errors.New("Role resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "user", MinimumVersion: "v7"}
 	}
 
 	return err
@@ -151,7 +162,7 @@ func resourceElasticsearchGetOpenDistroUser(userID string, m interface{}) (UserB
 		})
 		body = res.Body
 	default:
-		err = errors.New("Role resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "user", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -172,15 +183,18 @@ func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{
 	response := new(UserResponse)
 
 	userDefinition := UserBody{
-		BackendRoles: d.Get("backend_roles").(*schema.Set).List(),
-		Description:  d.Get("description").(string),
-		Attributes:   d.Get("attributes").(map[string]interface{}),
+		BackendRoles:  d.Get("backend_roles").(*schema.Set).List(),
+		SecurityRoles: d.Get("opendistro_security_roles").(*schema.Set).List(),
+		Description:   d.Get("description").(string),
+		Attributes:    d.Get("attributes").(map[string]interface{}),
 	}
 
-	if d.HasChange("password") {
+	// the password is only sent when it changes, or when a new version of it
+	// is requested
+	if d.HasChange("password") || d.HasChange("password_version") {
 		userDefinition.Password = d.Get("password").(string)
 	}
-	if d.HasChange("password_hash") {
+	if d.HasChange("password_hash") || d.HasChange("password_version") {
 		userDefinition.PasswordHash = d.Get("password_hash").(string)
 	}
 
@@ -204,14 +218,14 @@ func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{
 	switch client := esClient.(type) {
 	case *elastic7.Client:
 		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		res, err = securityPerformRequest(client, elastic7.PerformRequestOptions{
 			Method: "PUT",
 			Path:   path,
 			Body:   string(userJSON),
 		})
 		body = res.Body
 	default:
-		err = errors.New("User resource not implemented prior to Elastic v7")
+		err = &UnsupportedVersionError{Resource: "user", MinimumVersion: "v7"}
 	}
 
 	if err != nil {
@@ -227,11 +241,12 @@ func resourceElasticsearchPutOpenDistroUser(d *schema.ResourceData, m interface{
 
 // UserBody used by the odfe's API
 type UserBody struct {
-	BackendRoles []interface{}          `json:"backend_roles"`
-	Attributes   map[string]interface{} `json:"attributes"`
-	Description  string                 `json:"description"`
-	Password     string                 `json:"password,omitempty"`
-	PasswordHash string                 `json:"hash,omitempty"`
+	BackendRoles  []interface{}          `json:"backend_roles"`
+	SecurityRoles []interface{}          `json:"opendistro_security_roles"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Description   string                 `json:"description"`
+	Password      string                 `json:"password,omitempty"`
+	PasswordHash  string                 `json:"hash,omitempty"`
 }
 
 // UserResponse sent by the odfe's API
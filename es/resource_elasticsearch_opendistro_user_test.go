@@ -2,8 +2,14 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"sort"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -114,6 +120,171 @@ func TestAccElasticsearchOpenDistroUser(t *testing.T) {
 	})
 }
 
+func TestOpenDistroUserCreateAndUpdateBackendRoles(t *testing.T) {
+	// the API stores the user, and returns it without the password
+	var stored map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_opendistro/_security/api/internalusers/reader" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "PUT":
+			body, _ := ioutil.ReadAll(r.Body)
+			stored = nil
+			if err := json.Unmarshal(body, &stored); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"status": "CREATED", "message": "'reader' created."}`)
+		case "GET":
+			user := map[string]interface{}{}
+			for k, v := range stored {
+				if k != "password" && k != "hash" {
+					user[k] = v
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"reader": user})
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	userSchema := resourceElasticsearchOpenDistroUser().Schema
+	resourceData := schema.TestResourceDataRaw(t, userSchema, map[string]interface{}{
+		"username":                  "reader",
+		"password":                  "passw0rd",
+		"backend_roles":             []interface{}{"readers"},
+		"opendistro_security_roles": []interface{}{"readall"},
+	})
+	if err := resourceElasticsearchOpenDistroUserCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if stored["password"] != "passw0rd" {
+		t.Errorf("expected the password to be sent on creation, got %v", stored)
+	}
+	if v := resourceData.Get("opendistro_security_roles").(*schema.Set).List(); !reflect.DeepEqual(v, []interface{}{"readall"}) {
+		t.Errorf("expected opendistro_security_roles to be read back, got %v", v)
+	}
+	if v := resourceData.Get("password"); v != "passw0rd" {
+		t.Errorf("expected the password not to be read back, got %v", v)
+	}
+
+	resourceData = schema.TestResourceDataRaw(t, userSchema, map[string]interface{}{
+		"username":      "reader",
+		"password":      "passw0rd",
+		"backend_roles": []interface{}{"readers", "writers"},
+	})
+	resourceData.SetId("reader")
+	if err := resourceElasticsearchOpenDistroUserUpdate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var roles []string
+	for _, role := range resourceData.Get("backend_roles").(*schema.Set).List() {
+		roles = append(roles, role.(string))
+	}
+	sort.Strings(roles)
+	if !reflect.DeepEqual(roles, []string{"readers", "writers"}) {
+		t.Errorf("expected the updated backend roles to be read back, got %v", roles)
+	}
+	if v := resourceData.Get("opendistro_security_roles").(*schema.Set).Len(); v != 0 {
+		t.Errorf("expected the removed opendistro_security_roles to be empty, got %d", v)
+	}
+}
+
+func TestAccElasticsearchOpenDistroUser_passwordVersion(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	case *elastic6.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Users only supported on ES >= 7")
+			}
+		},
+		Providers:    testAccOpendistroProviders,
+		CheckDestroy: testAccCheckElasticsearchOpenDistroUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOpenDistroUserResourcePasswordVersion(randomName, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticSearchOpenDistroUserConnectsWith(randomName, "passw0rd"),
+				),
+			},
+			{
+				// rotate the password outside of terraform, bumping the
+				// version sets it back even though the password is unchanged
+				PreConfig: func() {
+					testAccSetOpenDistroUserPassword(t, randomName, "rotat3d!")
+				},
+				Config: testAccOpenDistroUserResourcePasswordVersion(randomName, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opendistro_user.test",
+						"password_version",
+						"2",
+					),
+					testCheckElasticSearchOpenDistroUserConnectsWith(randomName, "passw0rd"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSetOpenDistroUserPassword(t *testing.T, username string, password string) {
+	meta := testAccOpendistroProvider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err = esClient.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PATCH",
+		Path:   "/_opendistro/_security/api/internalusers/" + username,
+		Body:   fmt.Sprintf(`[{"op": "add", "path": "/password", "value": %q}]`, password),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func testCheckElasticSearchOpenDistroUserConnectsWith(username string, password string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := elastic7.NewClient(
+			elastic7.SetURL(os.Getenv("ELASTICSEARCH_URL")),
+			elastic7.SetBasicAuth(username, password))
+		if err != nil {
+			return err
+		}
+
+		_, err = client.ClusterHealth().Do(context.TODO())
+		return err
+	}
+}
+
 func testAccCheckElasticsearchOpenDistroUserDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_opendistro_user" {
@@ -271,3 +442,13 @@ func testAccOpenDistroUserResourceMinimal(resourceName string) string {
 	}
 	`, resourceName)
 }
+
+func testAccOpenDistroUserResourcePasswordVersion(resourceName string, passwordVersion string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_opendistro_user" "test" {
+		username         = "%s"
+		password         = "passw0rd"
+		password_version = "%s"
+	}
+	`, resourceName, passwordVersion)
+}
@@ -0,0 +1,355 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+const openSearchNotificationsConfigsPath = "/_plugins/_notifications/configs"
+
+// openSearchChannelTypes are the config types of notification channels
+// supported by the resource, each configured by a block of the same name.
+var openSearchChannelTypes = []string{"chime", "email", "slack", "sns", "webhook"}
+
+var openSearchChannelSchema = map[string]*schema.Schema{
+	"name": {
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The name of the channel.",
+	},
+	"description": {
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Description of the channel.",
+	},
+	"enabled": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Whether notifications are sent to the channel.",
+	},
+	"chime": {
+		Type:         schema.TypeList,
+		Optional:     true,
+		MaxItems:     1,
+		ExactlyOneOf: openSearchChannelTypes,
+		Description:  "An Amazon Chime channel.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"url": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Sensitive:   true,
+					Description: "The webhook URL of the Chime room.",
+				},
+			},
+		},
+	},
+	"email": {
+		Type:         schema.TypeList,
+		Optional:     true,
+		MaxItems:     1,
+		ExactlyOneOf: openSearchChannelTypes,
+		Description:  "An email channel, sending through an SMTP or SES account of the notifications plugin.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"email_account_id": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The ID of the SMTP or SES sender account.",
+				},
+				"recipients": {
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "The email addresses of the recipients.",
+				},
+				"email_group_ids": {
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "The IDs of email groups to send to.",
+				},
+			},
+		},
+	},
+	"slack": {
+		Type:         schema.TypeList,
+		Optional:     true,
+		MaxItems:     1,
+		ExactlyOneOf: openSearchChannelTypes,
+		Description:  "A Slack channel.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"url": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Sensitive:   true,
+					Description: "The incoming webhook URL of the Slack channel.",
+				},
+			},
+		},
+	},
+	"sns": {
+		Type:         schema.TypeList,
+		Optional:     true,
+		MaxItems:     1,
+		ExactlyOneOf: openSearchChannelTypes,
+		Description:  "An Amazon SNS topic.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"topic_arn": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The ARN of the SNS topic.",
+				},
+				"role_arn": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The ARN of an IAM role assumed to publish to the topic.",
+				},
+			},
+		},
+	},
+	"webhook": {
+		Type:         schema.TypeList,
+		Optional:     true,
+		MaxItems:     1,
+		ExactlyOneOf: openSearchChannelTypes,
+		Description:  "A custom webhook.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"url": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Sensitive:   true,
+					Description: "The URL of the webhook.",
+				},
+				"method": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "POST",
+					Description:  "The HTTP method of the requests to the webhook, one of `POST`, `PUT` or `PATCH`.",
+					ValidateFunc: validation.StringInSlice([]string{"POST", "PUT", "PATCH"}, false),
+				},
+				"header_params": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "The headers of the requests to the webhook.",
+				},
+			},
+		},
+	},
+}
+
+func resourceElasticsearchOpenSearchChannel() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an OpenSearch notification channel, managed by the notifications plugin of OpenSearch 2, which replaces the destinations of the alerting plugin.",
+		Create:      resourceElasticsearchOpenSearchChannelCreate,
+		Read:        resourceElasticsearchOpenSearchChannelRead,
+		Update:      resourceElasticsearchOpenSearchChannelUpdate,
+		Delete:      resourceElasticsearchOpenSearchChannelDelete,
+		Schema:      openSearchChannelSchema,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchOpenSearchChannelCreate(d *schema.ResourceData, m interface{}) error {
+	res, err := resourceElasticsearchOpenSearchChannelRequest("POST", openSearchNotificationsConfigsPath, expandOpenSearchChannel(d), m)
+	if err != nil {
+		log.Printf("[INFO] Failed to create channel: %+v", err)
+		return err
+	}
+
+	var response channelResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling channel body: %+v: %+v", err, string(res.Body))
+	}
+
+	d.SetId(response.ConfigID)
+	log.Printf("[INFO] Object ID: %s", d.Id())
+
+	return resourceElasticsearchOpenSearchChannelRead(d, m)
+}
+
+func resourceElasticsearchOpenSearchChannelRead(d *schema.ResourceData, m interface{}) error {
+	path, err := openSearchChannelPath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	res, err := resourceElasticsearchOpenSearchChannelRequest("GET", path, nil, m)
+	if elastic7.IsNotFound(err) {
+		log.Printf("[WARN] Channel (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var response channelListResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling channel body: %+v: %+v", err, string(res.Body))
+	}
+	if len(response.ConfigList) == 0 {
+		log.Printf("[WARN] Channel (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	// the config is read into the attributes, dropping the metadata set by
+	// the server, e.g. last_updated_time_ms
+	config := response.ConfigList[0].Config
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", config["name"])
+	ds.set("description", config["description"])
+	ds.set("enabled", config["is_enabled"])
+	for _, channelType := range openSearchChannelTypes {
+		var block []interface{}
+		if config["config_type"] == channelType {
+			block = flattenOpenSearchChannelConfig(channelType, config[channelType])
+		}
+		ds.set(channelType, block)
+	}
+	return ds.err
+}
+
+func resourceElasticsearchOpenSearchChannelUpdate(d *schema.ResourceData, m interface{}) error {
+	path, err := openSearchChannelPath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if _, err := resourceElasticsearchOpenSearchChannelRequest("PUT", path, expandOpenSearchChannel(d), m); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchOpenSearchChannelRead(d, m)
+}
+
+func resourceElasticsearchOpenSearchChannelDelete(d *schema.ResourceData, m interface{}) error {
+	path, err := openSearchChannelPath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	_, err = resourceElasticsearchOpenSearchChannelRequest("DELETE", path, nil, m)
+	if elastic7.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func openSearchChannelPath(id string) (string, error) {
+	path, err := uritemplates.Expand(openSearchNotificationsConfigsPath+"/{id}", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error building URL path for channel: %+v", err)
+	}
+	return path, nil
+}
+
+func resourceElasticsearchOpenSearchChannelRequest(method, path string, body interface{}, m interface{}) (*elastic7.Response, error) {
+	client, err := getOpenSearchClient(m.(*ProviderConf), "channel resource")
+	if err != nil {
+		return nil, err
+	}
+
+	return client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: method,
+		Path:   path,
+		Body:   body,
+	})
+}
+
+// expandOpenSearchChannel returns the body of the notifications config API
+// for the channel.
+func expandOpenSearchChannel(d *schema.ResourceData) map[string]interface{} {
+	config := map[string]interface{}{
+		"name":        d.Get("name").(string),
+		"description": d.Get("description").(string),
+		"is_enabled":  d.Get("enabled").(bool),
+	}
+
+	for _, channelType := range openSearchChannelTypes {
+		blocks := d.Get(channelType).([]interface{})
+		if len(blocks) == 0 || blocks[0] == nil {
+			continue
+		}
+		block := blocks[0].(map[string]interface{})
+		config["config_type"] = channelType
+
+		switch channelType {
+		case "email":
+			var recipients []interface{}
+			for _, recipient := range block["recipients"].(*schema.Set).List() {
+				recipients = append(recipients, map[string]interface{}{"recipient": recipient})
+			}
+			config[channelType] = map[string]interface{}{
+				"email_account_id":    block["email_account_id"],
+				"recipient_list":      recipients,
+				"email_group_id_list": block["email_group_ids"].(*schema.Set).List(),
+			}
+		default:
+			config[channelType] = block
+		}
+	}
+
+	return map[string]interface{}{"config": config}
+}
+
+// flattenOpenSearchChannelConfig returns the block of the channel type from
+// the config of the notifications config API.
+func flattenOpenSearchChannelConfig(channelType string, v interface{}) []interface{} {
+	config, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	block := make(map[string]interface{})
+	switch channelType {
+	case "email":
+		block["email_account_id"] = config["email_account_id"]
+		var recipients []interface{}
+		list, _ := config["recipient_list"].([]interface{})
+		for _, r := range list {
+			if recipient, ok := r.(map[string]interface{}); ok {
+				recipients = append(recipients, recipient["recipient"])
+			}
+		}
+		block["recipients"] = recipients
+		block["email_group_ids"] = config["email_group_id_list"]
+	default:
+		for k := range openSearchChannelSchema[channelType].Elem.(*schema.Resource).Schema {
+			if value, ok := config[k]; ok {
+				block[k] = value
+			}
+		}
+	}
+
+	return []interface{}{block}
+}
+
+type channelResponse struct {
+	ConfigID string `json:"config_id"`
+}
+
+type channelListResponse struct {
+	ConfigList []struct {
+		ConfigID string                 `json:"config_id"`
+		Config   map[string]interface{} `json:"config"`
+	} `json:"config_list"`
+}
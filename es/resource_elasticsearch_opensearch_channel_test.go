@@ -0,0 +1,95 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestOpenSearchChannelWebhook(t *testing.T) {
+	var created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "2.5.0", "distribution": "opensearch"}}`)
+		case r.Method == "POST" && r.URL.Path == "/_plugins/_notifications/configs":
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			created = body["config"].(map[string]interface{})
+			fmt.Fprint(w, `{"config_id": "abc"}`)
+		case r.Method == "GET" && r.URL.Path == "/_plugins/_notifications/configs/abc":
+			fmt.Fprintf(w, `{"start_index": 0, "total_hits": 1, "total_hit_relation": "eq", "config_list": [{
+  "config_id": "abc",
+  "last_updated_time_ms": 1652760532774,
+  "created_time_ms": 1652760532774,
+  "config": %s
+}]}`, mustMarshal(t, created))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": nil,
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, openSearchChannelSchema, map[string]interface{}{
+		"name": "alerts",
+		"webhook": []interface{}{
+			map[string]interface{}{
+				"url":           "https://example.com/hook",
+				"method":        "PUT",
+				"header_params": map[string]interface{}{"Content-Type": "application/json"},
+			},
+		},
+	})
+	if err := resourceElasticsearchOpenSearchChannelCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if created["config_type"] != "webhook" || created["is_enabled"] != true {
+		t.Errorf("expected an enabled webhook config to be created, got %v", created)
+	}
+	if _, ok := created["slack"]; ok {
+		t.Errorf("expected only the webhook to be configured, got %v", created)
+	}
+	if resourceData.Id() != "abc" {
+		t.Errorf("expected the config_id abc to be the ID, got %q", resourceData.Id())
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"url":           "https://example.com/hook",
+			"method":        "PUT",
+			"header_params": map[string]interface{}{"Content-Type": "application/json"},
+		},
+	}
+	if actual := resourceData.Get("webhook"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the webhook to be read back as %v, got %v", expected, actual)
+	}
+	if actual := resourceData.Get("slack").([]interface{}); len(actual) != 0 {
+		t.Errorf("expected no slack block, got %v", actual)
+	}
+}
+
+func TestOpenSearchChannelUnsupportedFlavor(t *testing.T) {
+	meta := testProviderMeta(t, "http://localhost:9200")
+
+	resourceData := schema.TestResourceDataRaw(t, openSearchChannelSchema, map[string]interface{}{
+		"name":  "alerts",
+		"slack": []interface{}{map[string]interface{}{"url": "https://hooks.slack.com/services/abc"}},
+	})
+	err := resourceElasticsearchOpenSearchChannelCreate(resourceData, meta)
+	if err == nil || err.Error() != "channel resource is only supported on OpenSearch clusters" {
+		t.Errorf("expected an error about the distribution, got %v", err)
+	}
+}
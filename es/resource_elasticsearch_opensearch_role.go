@@ -0,0 +1,286 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func resourceElasticsearchOpenSearchRole() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an OpenSearch security role resource. Please refer to the OpenSearch [access control documentation](https://opensearch.org/docs/latest/security-plugin/access-control/index/) for details.",
+		Create:      resourceElasticsearchOpenSearchRoleCreate,
+		Read:        resourceElasticsearchOpenSearchRoleRead,
+		Update:      resourceElasticsearchOpenSearchRoleUpdate,
+		Delete:      resourceElasticsearchOpenSearchRoleDelete,
+		Schema: map[string]*schema.Schema{
+			"role_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the security role.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the role.",
+			},
+			"cluster_permissions": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A list of cluster permissions.",
+			},
+			"index_permissions": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A configuration of index permissions.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index_patterns": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of glob patterns for the index names.",
+						},
+						"allowed_actions": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of allowed actions.",
+						},
+						"fls": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of selectors for field-level security.",
+						},
+						"masked_fields": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of masked fields.",
+						},
+						"dls": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: diffSuppressDocumentLevelSecurity,
+							Description:      "A selector for document-level security (json formatted using double quotes).",
+						},
+					},
+				},
+				Set: indexPermissionsHash,
+			},
+			"tenant_permissions": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A configuration of tenant permissions.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tenant_patterns": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of glob patterns for the tenant names.",
+						},
+						"allowed_actions": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of allowed actions.",
+						},
+					},
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchOpenSearchRoleCreate(d *schema.ResourceData, m interface{}) error {
+	if _, err := resourceElasticsearchPutOpenSearchRole(d, m); err != nil {
+		log.Printf("[INFO] Failed to create OpenSearchRole: %+v", err)
+		return err
+	}
+
+	name := d.Get("role_name").(string)
+	d.SetId(name)
+	return resourceElasticsearchOpenSearchRoleRead(d, m)
+}
+
+func resourceElasticsearchOpenSearchRoleRead(d *schema.ResourceData, m interface{}) error {
+	res, err := resourceElasticsearchGetOpenSearchRole(d.Id(), m)
+
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] OpenSearchRole (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("role_name", d.Id())
+	ds.set("description", res.Description)
+	ds.set("cluster_permissions", res.ClusterPermissions)
+	ds.set("index_permissions", flattenOpenSearchIndexPermissions(res.IndexPermissions))
+	ds.set("tenant_permissions", flattenTenantPermissions(res.TenantPermissions))
+	return ds.err
+}
+
+func resourceElasticsearchOpenSearchRoleUpdate(d *schema.ResourceData, m interface{}) error {
+	if _, err := resourceElasticsearchPutOpenSearchRole(d, m); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchOpenSearchRoleRead(d, m)
+}
+
+func resourceElasticsearchOpenSearchRoleDelete(d *schema.ResourceData, m interface{}) error {
+	path, err := uritemplates.Expand("/_plugins/_security/api/roles/{name}", map[string]string{
+		"name": d.Get("role_name").(string),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for role: %+v", err)
+	}
+
+	client, err := getOpenSearchClient(m.(*ProviderConf), "role resource")
+	if err != nil {
+		return err
+	}
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+
+	return err
+}
+
+func resourceElasticsearchGetOpenSearchRole(roleID string, m interface{}) (RoleBody, error) {
+	var role RoleBody
+
+	path, err := uritemplates.Expand("/_plugins/_security/api/roles/{name}", map[string]string{
+		"name": roleID,
+	})
+	if err != nil {
+		return role, fmt.Errorf("error building URL path for role: %+v", err)
+	}
+
+	client, err := getOpenSearchClient(m.(*ProviderConf), "role resource")
+	if err != nil {
+		return role, err
+	}
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return role, err
+	}
+
+	var roleDefinition map[string]RoleBody
+	if err := json.Unmarshal(res.Body, &roleDefinition); err != nil {
+		return role, fmt.Errorf("error unmarshalling role body: %+v: %+v", err, string(res.Body))
+	}
+
+	return roleDefinition[roleID], nil
+}
+
+func resourceElasticsearchPutOpenSearchRole(d *schema.ResourceData, m interface{}) (*RoleResponse, error) {
+	response := new(RoleResponse)
+
+	roleDefinition := RoleBody{
+		Description:        d.Get("description").(string),
+		ClusterPermissions: expandStringList(d.Get("cluster_permissions").(*schema.Set).List()),
+		IndexPermissions:   expandOpenSearchIndexPermissions(d.Get("index_permissions").(*schema.Set).List()),
+		TenantPermissions:  expandOpenSearchTenantPermissions(d.Get("tenant_permissions").(*schema.Set).List()),
+	}
+
+	roleJSON, err := json.Marshal(roleDefinition)
+	if err != nil {
+		return response, fmt.Errorf("Body Error : %s", roleJSON)
+	}
+
+	path, err := uritemplates.Expand("/_plugins/_security/api/roles/{name}", map[string]string{
+		"name": d.Get("role_name").(string),
+	})
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for role: %+v", err)
+	}
+
+	client, err := getOpenSearchClient(m.(*ProviderConf), "role resource")
+	if err != nil {
+		return response, err
+	}
+	res, err := securityPerformRequest(client, elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   string(roleJSON),
+	})
+	if err != nil {
+		if res != nil {
+			return response, fmt.Errorf("error creating role: %+v: %s", err, string(res.Body))
+		}
+		return response, fmt.Errorf("error creating role: %+v", err)
+	}
+
+	// The security plugin answers writes with a {status, message} envelope
+	if err := json.Unmarshal(res.Body, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling role body: %+v: %+v", err, string(res.Body))
+	}
+	if response.Status != "OK" && response.Status != "CREATED" {
+		return response, fmt.Errorf("error creating role: %s: %s", response.Status, response.Message)
+	}
+
+	return response, nil
+}
+
+func expandOpenSearchIndexPermissions(resourcesArray []interface{}) []IndexPermissions {
+	vperm := make([]IndexPermissions, 0, len(resourcesArray))
+	for _, item := range resourcesArray {
+		data := item.(map[string]interface{})
+		vperm = append(vperm, IndexPermissions{
+			IndexPatterns:         expandStringList(data["index_patterns"].(*schema.Set).List()),
+			AllowedActions:        expandStringList(data["allowed_actions"].(*schema.Set).List()),
+			FieldLevelSecurity:    expandStringList(data["fls"].(*schema.Set).List()),
+			MaskedFields:          expandStringList(data["masked_fields"].(*schema.Set).List()),
+			DocumentLevelSecurity: data["dls"].(string),
+		})
+	}
+	return vperm
+}
+
+func flattenOpenSearchIndexPermissions(permissions []IndexPermissions) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, map[string]interface{}{
+			"index_patterns":  flattenStringSet(permission.IndexPatterns),
+			"allowed_actions": flattenStringSet(permission.AllowedActions),
+			"fls":             flattenStringSet(permission.FieldLevelSecurity),
+			"masked_fields":   flattenStringSet(permission.MaskedFields),
+			"dls":             permission.DocumentLevelSecurity,
+		})
+	}
+	return result
+}
+
+func expandOpenSearchTenantPermissions(resourcesArray []interface{}) []TenantPermissions {
+	vperm := make([]TenantPermissions, 0, len(resourcesArray))
+	for _, item := range resourcesArray {
+		data := item.(map[string]interface{})
+		vperm = append(vperm, TenantPermissions{
+			TenantPatterns: expandStringList(data["tenant_patterns"].(*schema.Set).List()),
+			AllowedActions: expandStringList(data["allowed_actions"].(*schema.Set).List()),
+		})
+	}
+	return vperm
+}
@@ -0,0 +1,159 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccElasticsearchOpenSearchRole(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	_, err = getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	allowed := meta.(*ProviderConf).flavor == OpenSearch
+
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("OpenSearch roles only supported on OpenSearch")
+			}
+		},
+		Providers:    testAccOpendistroProviders,
+		CheckDestroy: testAccCheckElasticsearchOpenSearchRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOpenSearchRoleResource(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticSearchOpenSearchRoleExists("elasticsearch_opensearch_role.test"),
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opensearch_role.test",
+						"id",
+						randomName,
+					),
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opensearch_role.test",
+						"cluster_permissions.#",
+						"1",
+					),
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opensearch_role.test",
+						"index_permissions.#",
+						"1",
+					),
+					resource.TestCheckResourceAttr(
+						"elasticsearch_opensearch_role.test",
+						"tenant_permissions.#",
+						"1",
+					),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_opensearch_role.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckElasticsearchOpenSearchRoleDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_opensearch_role" {
+			continue
+		}
+
+		meta := testAccOpendistroProvider.Meta()
+
+		_, err := resourceElasticsearchGetOpenSearchRole(rs.Primary.ID, meta.(*ProviderConf))
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Role %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testCheckElasticSearchOpenSearchRoleExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No role ID is set")
+		}
+
+		meta := testAccOpendistroProvider.Meta()
+
+		_, err := resourceElasticsearchGetOpenSearchRole(rs.Primary.ID, meta.(*ProviderConf))
+		return err
+	}
+}
+
+func testAccOpenSearchRoleResource(resourceName string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_opensearch_role" "test" {
+		role_name   = "%s"
+		description = "test"
+
+		cluster_permissions = ["cluster_composite_ops_ro"]
+
+		index_permissions {
+			index_patterns  = ["pub*"]
+			allowed_actions = ["read"]
+			fls             = ["~secret"]
+			masked_fields   = ["email"]
+			dls             = "{\"match\": {\"public\": true}}"
+		}
+
+		tenant_permissions {
+			tenant_patterns = ["global_tenant"]
+			allowed_actions = ["kibana_all_read"]
+		}
+	}
+	`, resourceName)
+}
+
+func TestOpenSearchRoleDocumentLevelSecurity(t *testing.T) {
+	permissions := func(dls string) map[string]interface{} {
+		resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenSearchRole().Schema, map[string]interface{}{
+			"role_name": "owner",
+			"index_permissions": []interface{}{
+				map[string]interface{}{
+					"index_patterns":  []interface{}{"logs-*"},
+					"dls":             dls,
+					"allowed_actions": []interface{}{"read"},
+				},
+			},
+		})
+		return resourceData.Get("index_permissions").(*schema.Set).List()[0].(map[string]interface{})
+	}
+
+	configured := permissions(`{"term": { "readable_by": "${user.name}"}}`)
+	read := permissions(`{"term":{"readable_by":"${user.name}"}}`)
+	if indexPermissionsHash(configured) != indexPermissionsHash(read) {
+		t.Errorf("expected the index permissions with equivalent DLS queries to have the same hash")
+	}
+	if !diffSuppressDocumentLevelSecurity("", read["dls"].(string), configured["dls"].(string), nil) {
+		t.Errorf("expected %s and %s to be equivalent", read["dls"], configured["dls"])
+	}
+	if indexPermissionsHash(permissions(`{"term":{"readable_by":"${user.email}"}}`)) == indexPermissionsHash(read) {
+		t.Errorf("expected the index permissions with different DLS queries to have different hashes")
+	}
+}
@@ -0,0 +1,245 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic5 "gopkg.in/olivere/elastic.v5"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchReindex() *schema.Resource {
+	return &schema.Resource{
+		Description: "Runs a reindex of documents from a source to a destination index once, e.g. to codify a migration. The reindex isn't declarative: it runs again when its body or triggers change, and destroying the resource leaves the documents as they are.",
+		Create:      resourceElasticsearchReindexCreate,
+		Read:        resourceElasticsearchReindexRead,
+		Delete:      resourceElasticsearchReindexDelete,
+		Schema: map[string]*schema.Schema{
+			"body": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+				Description:      "The JSON body of the reindex request, with the `source` and `dest` of the documents.",
+			},
+			"wait_for_completion": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Wait for the reindex to complete. Otherwise the reindex runs as a task, whose ID is stored and whose result is read once it completed.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values that run the reindex again when changed.",
+			},
+			"task_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the task of the reindex, when not waiting for its completion.",
+			},
+			"total": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of documents processed by the reindex, once it completed.",
+			},
+			"error": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The error of the task of the reindex, when it failed.",
+			},
+		},
+	}
+}
+
+func resourceElasticsearchReindexCreate(d *schema.ResourceData, meta interface{}) error {
+	var body json.RawMessage
+	params := url.Values{}
+	params.Set("wait_for_completion", strconv.FormatBool(d.Get("wait_for_completion").(bool)))
+	reindexBody := d.Get("body").(string)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_reindex",
+			Params: params,
+			Body:   reindexBody,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_reindex",
+			Params: params,
+			Body:   reindexBody,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "POST", "/_reindex", params, reindexBody)
+		if err == nil {
+			body = res.Body
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error reindexing: %+v", err)
+	}
+
+	response := new(reindexResponse)
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("error unmarshalling reindex body: %+v: %+v", err, string(body))
+	}
+	if err := response.failed(); err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	if response.Task != "" {
+		d.SetId(response.Task)
+		ds.set("task_id", response.Task)
+	} else {
+		d.SetId(resource.UniqueId())
+		ds.set("total", response.Total)
+	}
+	if ds.err != nil {
+		return ds.err
+	}
+
+	return resourceElasticsearchReindexRead(d, meta)
+}
+
+// resourceElasticsearchReindexRead reads the result of the task of the
+// reindex, until it completed. The reindex is never removed from the state,
+// its documents don't depend on the task, and a failed task is stored as its
+// error rather than failing every later plan.
+func resourceElasticsearchReindexRead(d *schema.ResourceData, meta interface{}) error {
+	taskID := d.Get("task_id").(string)
+	if taskID == "" || d.Get("total").(int) != 0 || d.Get("error").(string) != "" {
+		return nil
+	}
+
+	path, err := uritemplates.Expand("/_tasks/{task_id}", map[string]string{
+		"task_id": taskID,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for task: %+v", err)
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, nil, nil, http.StatusNotFound)
+		if err == nil {
+			body = res.Body
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	task := new(reindexTaskResponse)
+	if err := json.Unmarshal(body, task); err != nil {
+		return fmt.Errorf("error unmarshalling task body: %+v: %+v", err, string(body))
+	}
+	if !task.Completed {
+		log.Printf("[INFO] Reindex task (%s) hasn't completed yet", taskID)
+		return nil
+	}
+	if len(task.Error) != 0 {
+		log.Printf("[WARN] Reindex task (%s) failed: %s", taskID, task.Error)
+		return d.Set("error", fmt.Sprintf("reindex task %s failed: %s", taskID, task.Error))
+	}
+	if task.Response == nil {
+		return nil
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("total", task.Response.Total)
+	if err := task.Response.failed(); err != nil {
+		log.Printf("[WARN] Reindex task (%s) failed: %+v", taskID, err)
+		ds.set("error", err.Error())
+	}
+	return ds.err
+}
+
+// resourceElasticsearchReindexDelete only removes the reindex from the
+// state, the reindexed documents are kept.
+func resourceElasticsearchReindexDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+type reindexResponse struct {
+	Task     string            `json:"task"`
+	Total    int               `json:"total"`
+	Failures []json.RawMessage `json:"failures"`
+}
+
+// failed returns an error with the failures of the documents of the reindex.
+func (r *reindexResponse) failed() error {
+	if len(r.Failures) == 0 {
+		return nil
+	}
+
+	failures := make([]string, len(r.Failures))
+	for i, failure := range r.Failures {
+		failures[i] = string(failure)
+	}
+	return fmt.Errorf("reindex failed for %d documents: %s", len(r.Failures), strings.Join(failures, ", "))
+}
+
+type reindexTaskResponse struct {
+	Completed bool             `json:"completed"`
+	Error     json.RawMessage  `json:"error"`
+	Response  *reindexResponse `json:"response"`
+}
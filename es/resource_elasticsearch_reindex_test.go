@@ -0,0 +1,121 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestElasticsearchReindex(t *testing.T) {
+	var reindexed map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/_reindex":
+			if err := json.NewDecoder(r.Body).Decode(&reindexed); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			if r.URL.Query().Get("wait_for_completion") == "false" {
+				fmt.Fprint(w, `{"task": "oTUltX4IQMOUUVeiohTt8A:12345"}`)
+				return
+			}
+			fmt.Fprint(w, `{"took": 12, "timed_out": false, "total": 2, "updated": 0, "created": 2, "deleted": 0, "batches": 1, "failures": []}`)
+		case r.Method == "GET" && r.URL.Path == "/_tasks/oTUltX4IQMOUUVeiohTt8A:12345":
+			fmt.Fprint(w, `{"completed": true, "task": {"node": "oTUltX4IQMOUUVeiohTt8A", "id": 12345, "action": "indices:data/write/reindex"}, "response": {"took": 12, "total": 3, "created": 3, "failures": []}}`)
+		case r.Method == "GET" && r.URL.Path == "/_tasks/oTUltX4IQMOUUVeiohTt8A:12346":
+			fmt.Fprint(w, `{"completed": true, "task": {"node": "oTUltX4IQMOUUVeiohTt8A", "id": 12346, "action": "indices:data/write/reindex"}, "error": {"type": "index_not_found_exception", "reason": "no such index [logs-v1]"}}`)
+		case r.Method == "GET" && r.URL.Path == "/_tasks/oTUltX4IQMOUUVeiohTt8A:12347":
+			fmt.Fprint(w, `{"completed": true, "task": {"node": "oTUltX4IQMOUUVeiohTt8A", "id": 12347, "action": "indices:data/write/reindex"}, "response": {"took": 12, "total": 3, "created": 2, "failures": [{"index": "logs-v2", "id": "1"}]}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	body := `{"source": {"index": "logs-v1"}, "dest": {"index": "logs-v2"}}`
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchReindex().Schema, map[string]interface{}{
+		"body": body,
+	})
+	if err := resourceElasticsearchReindexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"source": map[string]interface{}{"index": "logs-v1"},
+		"dest":   map[string]interface{}{"index": "logs-v2"},
+	}
+	if !reflect.DeepEqual(reindexed, expected) {
+		t.Errorf("expected the reindex %v, got %v", expected, reindexed)
+	}
+	if resourceData.Id() == "" || resourceData.Get("total") != 2 || resourceData.Get("task_id") != "" {
+		t.Errorf("expected the reindex of 2 documents, got %s: %v", resourceData.Id(), resourceData.Get("total"))
+	}
+
+	// changed triggers run the reindex again
+	state := &terraform.InstanceState{
+		ID: resourceData.Id(),
+		Attributes: map[string]string{
+			"id":                  resourceData.Id(),
+			"body":                body,
+			"wait_for_completion": "true",
+			"total":               "2",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"body":     body,
+		"triggers": map[string]interface{}{"mapping": "v2"},
+	})
+	diff, err := resourceElasticsearchReindex().Diff(state, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Error("expected changed triggers to run the reindex again")
+	}
+
+	// the task of the reindex is read once it completed
+	resourceData = schema.TestResourceDataRaw(t, resourceElasticsearchReindex().Schema, map[string]interface{}{
+		"body":                body,
+		"wait_for_completion": false,
+	})
+	if err := resourceElasticsearchReindexCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Get("task_id") != "oTUltX4IQMOUUVeiohTt8A:12345" || resourceData.Get("total") != 3 {
+		t.Errorf("expected the task of the reindex of 3 documents, got %v: %v", resourceData.Get("task_id"), resourceData.Get("total"))
+	}
+
+	// a failed task is stored as the error of the reindex
+	for taskID, expected := range map[string]string{
+		"oTUltX4IQMOUUVeiohTt8A:12346": `reindex task oTUltX4IQMOUUVeiohTt8A:12346 failed: {"type": "index_not_found_exception", "reason": "no such index [logs-v1]"}`,
+		"oTUltX4IQMOUUVeiohTt8A:12347": `reindex failed for 1 documents: {"index": "logs-v2", "id": "1"}`,
+	} {
+		failedData := resourceElasticsearchReindex().Data(&terraform.InstanceState{
+			ID: taskID,
+			Attributes: map[string]string{
+				"id":                  taskID,
+				"body":                body,
+				"wait_for_completion": "false",
+				"task_id":             taskID,
+			},
+		})
+		if err := resourceElasticsearchReindexRead(failedData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if failedData.Get("error") != expected {
+			t.Errorf("expected the error %s, got %v", expected, failedData.Get("error"))
+		}
+	}
+
+	if err := resourceElasticsearchReindexDelete(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
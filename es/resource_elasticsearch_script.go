@@ -0,0 +1,207 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic5 "gopkg.in/olivere/elastic.v5"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchScript() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch stored script, e.g. a Painless script reused by queries and ingest pipelines.",
+		Create:      resourceElasticsearchScriptCreate,
+		Read:        resourceElasticsearchScriptRead,
+		Update:      resourceElasticsearchScriptUpdate,
+		Delete:      resourceElasticsearchScriptDelete,
+		Schema: map[string]*schema.Schema{
+			"script_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the stored script.",
+			},
+			"lang": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "painless",
+				ForceNew:    true,
+				Description: "The language of the script, e.g. `painless` or `mustache` for search templates.",
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The source of the script.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchScriptCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchPutScript(d, meta); err != nil {
+		return err
+	}
+
+	d.SetId(d.Get("script_id").(string))
+	return resourceElasticsearchScriptRead(d, meta)
+}
+
+func resourceElasticsearchScriptRead(d *schema.ResourceData, meta interface{}) error {
+	path, err := uritemplates.Expand("/_scripts/{id}", map[string]string{
+		"id": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for script: %+v", err)
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method:       "GET",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		var res *elastic5.Response
+		res, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "GET", path, nil, nil, http.StatusNotFound)
+		if err == nil {
+			body = res.Body
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	response := new(scriptResponse)
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("error unmarshalling script body: %+v: %+v", err, string(body))
+	}
+	if !response.Found || response.Script == nil {
+		log.Printf("[WARN] Script (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("script_id", d.Id())
+	ds.set("lang", response.Script.Lang)
+	ds.set("source", response.Script.Source)
+	return ds.err
+}
+
+func resourceElasticsearchScriptUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchPutScript(d, meta); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchScriptRead(d, meta)
+}
+
+func resourceElasticsearchScriptDelete(d *schema.ResourceData, meta interface{}) error {
+	path, err := uritemplates.Expand("/_scripts/{id}", map[string]string{
+		"id": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for script: %+v", err)
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method:       "DELETE",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method:       "DELETE",
+			Path:         path,
+			IgnoreErrors: []int{http.StatusNotFound},
+		})
+	default:
+		_, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "DELETE", path, nil, nil, http.StatusNotFound)
+	}
+
+	return err
+}
+
+func resourceElasticsearchPutScript(d *schema.ResourceData, meta interface{}) error {
+	path, err := uritemplates.Expand("/_scripts/{id}", map[string]string{
+		"id": d.Get("script_id").(string),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for script: %+v", err)
+	}
+
+	body := map[string]interface{}{
+		"script": map[string]interface{}{
+			"lang":   d.Get("lang").(string),
+			"source": d.Get("source").(string),
+		},
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "PUT",
+			Path:   path,
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "PUT",
+			Path:   path,
+			Body:   body,
+		})
+	default:
+		_, err = client.(*elastic5.Client).PerformRequest(context.TODO(), "PUT", path, nil, body)
+	}
+
+	return err
+}
+
+type scriptResponse struct {
+	ID     string `json:"_id"`
+	Found  bool   `json:"found"`
+	Script *struct {
+		Lang   string `json:"lang"`
+		Source string `json:"source"`
+	} `json:"script"`
+}
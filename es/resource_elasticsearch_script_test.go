@@ -0,0 +1,108 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestElasticsearchScriptCreateUpdate(t *testing.T) {
+	var stored map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/_scripts/calculate-score":
+			var body struct {
+				Script map[string]interface{} `json:"script"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			stored = body.Script
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "GET" && r.URL.Path == "/_scripts/calculate-score":
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"_id": "calculate-score", "found": false}`)
+				return
+			}
+			script, _ := json.Marshal(stored)
+			fmt.Fprintf(w, `{"_id": "calculate-score", "found": true, "script": %s}`, script)
+		case r.Method == "DELETE" && r.URL.Path == "/_scripts/calculate-score":
+			stored = nil
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchScript().Schema, map[string]interface{}{
+		"script_id": "calculate-score",
+		"source":    "Math.log(_score * 2) + params['my_modifier']",
+	})
+	if err := resourceElasticsearchScriptCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "calculate-score" || resourceData.Get("lang") != "painless" {
+		t.Errorf("expected a painless script calculate-score, got %s in %v", resourceData.Id(), resourceData.Get("lang"))
+	}
+
+	state := &terraform.InstanceState{
+		ID: "calculate-score",
+		Attributes: map[string]string{
+			"id":        "calculate-score",
+			"script_id": "calculate-score",
+			"lang":      "painless",
+			"source":    "Math.log(_score * 2) + params['my_modifier']",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"script_id": "calculate-score",
+		"source":    "Math.log(_score * 3) + params['my_modifier']",
+	})
+	diff, err := resourceElasticsearchScript().Diff(state, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Error("expected the source to be updated in place")
+	}
+	state, err = resourceElasticsearchScript().Apply(state, diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if stored["source"] != "Math.log(_score * 3) + params['my_modifier']" || state.Attributes["source"] != stored["source"] {
+		t.Errorf("expected the source to be updated, got %v", stored)
+	}
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"script_id": "calculate-score",
+		"lang":      "expression",
+		"source":    "_score * 3",
+	})
+	diff, err = resourceElasticsearchScript().Diff(state, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Error("expected a changed lang to recreate the script")
+	}
+
+	// scripts deleted outside of terraform are removed from the state
+	stored = nil
+	resourceData = resourceElasticsearchScript().Data(state)
+	if err := resourceElasticsearchScriptRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "" {
+		t.Errorf("expected the missing script to be removed from the state, got %s", resourceData.Id())
+	}
+}
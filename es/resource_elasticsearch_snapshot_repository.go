@@ -2,8 +2,10 @@ package es
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic5 "gopkg.in/olivere/elastic.v5"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -23,12 +25,19 @@ func resourceElasticsearchSnapshotRepository() *schema.Resource {
 			},
 			"type": {
 				Type:     schema.TypeString,
+				ForceNew: true,
 				Required: true,
 			},
 			"settings": {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Verify that the repository works on all nodes after it is created or updated, and fail if it doesn't.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -37,12 +46,12 @@ func resourceElasticsearchSnapshotRepository() *schema.Resource {
 }
 
 func resourceElasticsearchSnapshotRepositoryCreate(d *schema.ResourceData, meta interface{}) error {
-	err := resourceElasticsearchSnapshotRepositoryUpdate(d, meta)
+	err := resourceElasticsearchSnapshotRepositoryPut(d, meta)
 	if err != nil {
 		return err
 	}
 	d.SetId(d.Get("name").(string))
-	return nil
+	return resourceElasticsearchSnapshotRepositoryVerify(d, meta)
 }
 
 func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta interface{}) error {
@@ -69,6 +78,16 @@ func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta in
 		return err
 	}
 
+	// Only read back the declared settings, to not diff on the defaults added
+	// by the server, unless the repository is being imported
+	if declared, ok := d.GetOk("settings"); ok {
+		for k := range settings {
+			if _, ok := declared.(map[string]interface{})[k]; !ok {
+				delete(settings, k)
+			}
+		}
+	}
+
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", id)
 	ds.set("type", repositoryType)
@@ -104,6 +123,14 @@ func elastic5SnapshotGetRepository(client *elastic5.Client, id string) (string,
 }
 
 func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceElasticsearchSnapshotRepositoryPut(d, meta); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchSnapshotRepositoryVerify(d, meta)
+}
+
+func resourceElasticsearchSnapshotRepositoryPut(d *schema.ResourceData, meta interface{}) error {
 	repositoryType := d.Get("type").(string)
 	name := d.Get("name").(string)
 
@@ -161,6 +188,48 @@ func elastic5SnapshotCreateRepository(client *elastic5.Client, name string, repo
 	return err
 }
 
+// resourceElasticsearchSnapshotRepositoryVerify verifies the repository on
+// all nodes of the cluster, if verify is set.
+func resourceElasticsearchSnapshotRepositoryVerify(d *schema.ResourceData, meta interface{}) error {
+	if !d.Get("verify").(bool) {
+		return nil
+	}
+
+	name := d.Get("name").(string)
+	path, err := uritemplates.Expand("/_snapshot/{name}/_verify", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for snapshot repository: %+v", err)
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+		})
+	default:
+		elastic5Client := client.(*elastic5.Client)
+		_, err = elastic5Client.PerformRequest(context.TODO(), "POST", path, nil, nil)
+	}
+
+	if err != nil {
+		return fmt.Errorf("snapshot repository %s failed verification: %+v", name, err)
+	}
+
+	return nil
+}
+
 func resourceElasticsearchSnapshotRepositoryDelete(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
@@ -3,6 +3,9 @@ package es
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -10,6 +13,7 @@ import (
 	elastic6 "gopkg.in/olivere/elastic.v6"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -29,6 +33,71 @@ func TestAccElasticsearchSnapshotRepository(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchSnapshotRepository_verify(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchSnapshotRepositoryDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchSnapshotRepositoryVerify,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchSnapshotRepositoryExists("elasticsearch_snapshot_repository.test"),
+					resource.TestCheckResourceAttr("elasticsearch_snapshot_repository.test", "verify", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_snapshot_repository.test", "settings.%", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestSnapshotRepositoryReadDeclaredSettings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"terraform-test": {"type": "fs", "settings": {"location": "/tmp/elasticsearch", "compress": "true"}}}`)
+	}))
+	defer ts.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchSnapshotRepository().Schema, map[string]interface{}{
+		"name": "terraform-test",
+		"type": "fs",
+		"settings": map[string]interface{}{
+			"location": "/tmp/elasticsearch",
+		},
+	})
+	d.SetId("terraform-test")
+	if err := resourceElasticsearchSnapshotRepositoryRead(d, testProviderMeta(t, ts.URL)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	settings := d.Get("settings").(map[string]interface{})
+	if len(settings) != 1 || settings["location"] != "/tmp/elasticsearch" {
+		t.Errorf("expected only the declared settings, got %v", settings)
+	}
+}
+
+func TestSnapshotRepositoryVerifyFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/_snapshot/terraform-test/_verify" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": {"type": "repository_verification_exception", "reason": "[terraform-test] path  is not accessible on master node"}, "status": 500}`)
+	}))
+	defer ts.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchSnapshotRepository().Schema, map[string]interface{}{
+		"name":   "terraform-test",
+		"type":   "fs",
+		"verify": true,
+	})
+	err := resourceElasticsearchSnapshotRepositoryVerify(d, testProviderMeta(t, ts.URL))
+	if err == nil || !strings.Contains(err.Error(), "failed verification") {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+}
+
 func TestAccElasticsearchSnapshotRepository_importBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -125,3 +194,16 @@ resource "elasticsearch_snapshot_repository" "test" {
   }
 }
 `
+
+var testAccElasticsearchSnapshotRepositoryVerify = `
+resource "elasticsearch_snapshot_repository" "test" {
+  name   = "terraform-test"
+  type   = "fs"
+  verify = true
+
+  settings = {
+    location = "/tmp/elasticsearch"
+    compress = "true"
+  }
+}
+`
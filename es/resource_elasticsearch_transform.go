@@ -0,0 +1,407 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+// transformImmutableFields are the fields of a transform which can't be
+// updated, so that changing them recreates the transform: pivot and latest of
+// Elasticsearch, and the indices, groups and aggregations of OpenDistro.
+var transformImmutableFields = []string{"pivot", "latest", "source_index", "target_index", "data_selection_query", "groups", "aggregations"}
+
+var transformSchema = map[string]*schema.Schema{
+	"transform_id": {
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The ID of the transform.",
+	},
+	"body": {
+		Type:             schema.TypeString,
+		Required:         true,
+		DiffSuppressFunc: diffSuppressTransform,
+		StateFunc: func(v interface{}) string {
+			json, _ := structure.NormalizeJsonString(v)
+			return json
+		},
+		ValidateFunc: validation.StringIsJSON,
+		Description:  "The transform document, without the transform ID. On OpenDistro and OpenSearch, the document is wrapped in a `transform` object by the provider.",
+	},
+	"enabled": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether the transform is started, with the `_start` and `_stop` APIs.",
+	},
+}
+
+func resourceElasticsearchTransform() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceElasticsearchTransformCreate,
+		Read:          resourceElasticsearchTransformRead,
+		Update:        resourceElasticsearchTransformUpdate,
+		Delete:        resourceElasticsearchTransformDelete,
+		CustomizeDiff: resourceElasticsearchTransformCustomizeDiff,
+		Schema:        transformSchema,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+// resourceElasticsearchTransformCustomizeDiff recreates the transform when
+// fields which can't be updated change.
+func resourceElasticsearchTransformCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("body") {
+		return nil
+	}
+
+	o, n := d.GetChange("body")
+	var oldTransform, newTransform map[string]interface{}
+	if err := json.Unmarshal([]byte(o.(string)), &oldTransform); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(n.(string)), &newTransform); err != nil {
+		return nil
+	}
+
+	for _, field := range transformImmutableFields {
+		if !reflect.DeepEqual(oldTransform[field], newTransform[field]) {
+			log.Printf("[INFO] Field %s of transform %s can't be updated, recreating the transform", field, d.Id())
+			return d.ForceNew("body")
+		}
+	}
+
+	return nil
+}
+
+func resourceElasticsearchTransformCreate(d *schema.ResourceData, m interface{}) error {
+	api, err := resourceElasticsearchTransformAPI(m)
+	if err != nil {
+		return err
+	}
+
+	id := d.Get("transform_id").(string)
+	body, err := api.body(d.Get("body").(string), false)
+	if err != nil {
+		return err
+	}
+	if _, err := resourceElasticsearchTransformRequest("PUT", api.path+"/{id}", id, nil, body, m); err != nil {
+		log.Printf("[INFO] Failed to put transform: %+v", err)
+		return err
+	}
+
+	d.SetId(id)
+	log.Printf("[INFO] Object ID: %s", d.Id())
+
+	if d.Get("enabled").(bool) {
+		if err := resourceElasticsearchTransformJob(api, d.Id(), "_start", m); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchTransformRead(d, m)
+}
+
+func resourceElasticsearchTransformRead(d *schema.ResourceData, m interface{}) error {
+	api, err := resourceElasticsearchTransformAPI(m)
+	if err != nil {
+		return err
+	}
+
+	transform, enabled, err := api.get(d.Id(), m)
+	if elastic7.IsNotFound(err) || (err == nil && transform == nil) {
+		log.Printf("[WARN] Transform (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	transformJSON, err := json.Marshal(transform)
+	if err != nil {
+		return err
+	}
+	transformJSONNormalized, err := structure.NormalizeJsonString(string(transformJSON))
+	if err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("transform_id", d.Id())
+	ds.set("body", transformJSONNormalized)
+	ds.set("enabled", enabled)
+	return ds.err
+}
+
+func resourceElasticsearchTransformUpdate(d *schema.ResourceData, m interface{}) error {
+	api, err := resourceElasticsearchTransformAPI(m)
+	if err != nil {
+		return err
+	}
+
+	o, _ := d.GetChange("enabled")
+	running := o.(bool)
+
+	if d.HasChange("body") {
+		if api.openDistro {
+			err = api.putOpenDistro(d.Id(), d.Get("body").(string), d.Get("enabled").(bool), m)
+			running = d.Get("enabled").(bool)
+		} else {
+			err = api.update(d.Id(), d.Get("body").(string), m)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if enabled := d.Get("enabled").(bool); enabled != running {
+		action := "_stop"
+		if enabled {
+			action = "_start"
+		}
+		if err := resourceElasticsearchTransformJob(api, d.Id(), action, m); err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchTransformRead(d, m)
+}
+
+func resourceElasticsearchTransformDelete(d *schema.ResourceData, m interface{}) error {
+	api, err := resourceElasticsearchTransformAPI(m)
+	if err != nil {
+		return err
+	}
+
+	// a started transform can't be deleted
+	if d.Get("enabled").(bool) {
+		if err := resourceElasticsearchTransformJob(api, d.Id(), "_stop", m); err != nil {
+			return err
+		}
+	}
+
+	_, err = resourceElasticsearchTransformRequest("DELETE", api.path+"/{id}", d.Id(), nil, nil, m)
+	if elastic7.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// resourceElasticsearchTransformJob starts or stops a transform, with the
+// _start or _stop action. Stopping waits for the transform to be stopped on
+// Elasticsearch, so that it can be updated or deleted afterwards.
+func resourceElasticsearchTransformJob(api *transformAPI, id, action string, m interface{}) error {
+	params := url.Values{}
+	if action == "_stop" && !api.openDistro {
+		params.Set("wait_for_completion", "true")
+	}
+
+	_, err := resourceElasticsearchTransformRequest("POST", api.path+"/{id}/"+action, id, params, nil, m)
+	if err != nil {
+		return fmt.Errorf("error calling %s of transform (%s): %+v", action, id, err)
+	}
+	return nil
+}
+
+// transformAPI is the transforms API of the distribution of the cluster:
+// _transform of Elasticsearch, or the index management plugin of OpenDistro
+// and OpenSearch.
+type transformAPI struct {
+	path       string
+	openDistro bool
+}
+
+// resourceElasticsearchTransformAPI returns the transforms API of the
+// distribution of the cluster. The OSS distribution of Elasticsearch doesn't
+// include transforms, so it is assumed to be OpenDistro.
+func resourceElasticsearchTransformAPI(m interface{}) (*transformAPI, error) {
+	conf := m.(*ProviderConf)
+	if conf.flavor == OpenSearch {
+		return &transformAPI{path: "/_plugins/_transform", openDistro: true}, nil
+	}
+
+	info, err := getRootInfo(conf)
+	if err != nil {
+		return nil, fmt.Errorf("error determining the distribution of the cluster for its transforms API: %+v", err)
+	}
+	switch {
+	case info.Version.Distribution == "opensearch":
+		return &transformAPI{path: "/_plugins/_transform", openDistro: true}, nil
+	case info.Version.BuildFlavor == "oss":
+		return &transformAPI{path: "/_opendistro/_transform", openDistro: true}, nil
+	}
+
+	return &transformAPI{path: "/_transform"}, nil
+}
+
+// body returns the request body creating or updating the transform, which
+// is wrapped in a transform object with its enabled state on OpenDistro.
+func (api *transformAPI) body(transformJSON string, enabled bool) (interface{}, error) {
+	if !api.openDistro {
+		return transformJSON, nil
+	}
+
+	var transform map[string]interface{}
+	if err := json.Unmarshal([]byte(transformJSON), &transform); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	transform["enabled"] = enabled
+	return map[string]interface{}{"transform": transform}, nil
+}
+
+// get returns the normalized transform, or nil if it doesn't exist, and
+// whether it is started.
+func (api *transformAPI) get(id string, m interface{}) (map[string]interface{}, bool, error) {
+	if api.openDistro {
+		var response openDistroTransformResponse
+		if err := resourceElasticsearchTransformRequestInto("GET", api.path+"/{id}", id, nil, &response, m); err != nil {
+			return nil, false, err
+		}
+		enabled, _ := response.Transform["enabled"].(bool)
+		normalizeTransform(response.Transform)
+		return response.Transform, enabled, nil
+	}
+
+	var response transformsResponse
+	if err := resourceElasticsearchTransformRequestInto("GET", api.path+"/{id}", id, nil, &response, m); err != nil {
+		return nil, false, err
+	}
+	if len(response.Transforms) == 0 {
+		return nil, false, nil
+	}
+
+	var stats transformStatsResponse
+	if err := resourceElasticsearchTransformRequestInto("GET", api.path+"/{id}/_stats", id, nil, &stats, m); err != nil {
+		return nil, false, err
+	}
+	enabled := false
+	if len(stats.Transforms) > 0 {
+		switch stats.Transforms[0].State {
+		case "started", "indexing":
+			enabled = true
+		}
+	}
+
+	normalizeTransform(response.Transforms[0])
+	return response.Transforms[0], enabled, nil
+}
+
+// update updates the fields of an Elasticsearch transform which can be
+// updated, which excludes its pivot or latest configuration.
+func (api *transformAPI) update(id, transformJSON string, m interface{}) error {
+	var transform map[string]interface{}
+	if err := json.Unmarshal([]byte(transformJSON), &transform); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	for _, field := range transformImmutableFields {
+		delete(transform, field)
+	}
+
+	_, err := resourceElasticsearchTransformRequest("POST", api.path+"/{id}/_update", id, nil, transform, m)
+	return err
+}
+
+// putOpenDistro updates an OpenDistro transform, which requires the sequence
+// number and primary term of the transform.
+func (api *transformAPI) putOpenDistro(id, transformJSON string, enabled bool, m interface{}) error {
+	var current openDistroTransformResponse
+	if err := resourceElasticsearchTransformRequestInto("GET", api.path+"/{id}", id, nil, &current, m); err != nil {
+		return err
+	}
+
+	body, err := api.body(transformJSON, enabled)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("if_seq_no", strconv.Itoa(current.SeqNo))
+	params.Set("if_primary_term", strconv.Itoa(current.PrimaryTerm))
+
+	_, err = resourceElasticsearchTransformRequest("PUT", api.path+"/{id}", id, params, body, m)
+	if elastic7.IsConflict(err) {
+		return fmt.Errorf("transform (%s) was modified while it was updated, try again: %+v", id, err)
+	}
+	return err
+}
+
+// resourceElasticsearchTransformRequestInto sends a request to the
+// transforms API, unmarshalling the response into response.
+func resourceElasticsearchTransformRequestInto(method, template, id string, params url.Values, response interface{}, m interface{}) error {
+	body, err := resourceElasticsearchTransformRequest(method, template, id, params, nil, m)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("error unmarshalling transform body: %+v: %+v", err, string(body))
+	}
+	return nil
+}
+
+// resourceElasticsearchTransformRequest sends a request to the transforms
+// API. The template is expanded with the ID of the transform.
+func resourceElasticsearchTransformRequest(method, template, id string, params url.Values, body interface{}, m interface{}) (json.RawMessage, error) {
+	path, err := uritemplates.Expand(template, map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	var resBody json.RawMessage
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: method,
+			Path:   path,
+			Params: params,
+			Body:   body,
+		})
+		if err == nil {
+			resBody = res.Body
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "transform", MinimumVersion: "v7"}
+	}
+
+	return resBody, err
+}
+
+type transformsResponse struct {
+	Count      int                      `json:"count"`
+	Transforms []map[string]interface{} `json:"transforms"`
+}
+
+type transformStatsResponse struct {
+	Transforms []struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	} `json:"transforms"`
+}
+
+type openDistroTransformResponse struct {
+	ID          string                 `json:"_id"`
+	PrimaryTerm int                    `json:"_primary_term"`
+	SeqNo       int                    `json:"_seq_no"`
+	Transform   map[string]interface{} `json:"transform"`
+}
@@ -0,0 +1,212 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+var testTransformBody = `{
+  "source": {"index": ["kibana_sample_data_ecommerce"]},
+  "dest": {"index": "ecommerce-customers"},
+  "frequency": "5m",
+  "sync": {"time": {"field": "order_date", "delay": "60s"}},
+  "pivot": {
+    "group_by": {"customer_id": {"terms": {"field": "customer_id"}}},
+    "aggregations": {"total_spent": {"sum": {"field": "taxful_total_price"}}}
+  }
+}`
+
+func TestElasticsearchTransformCreateAndToggle(t *testing.T) {
+	var requests []string
+	started := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/" {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "default"}}`)
+		case r.Method == "PUT" && r.URL.Path == "/_transform/ecommerce-customers":
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "POST" && r.URL.Path == "/_transform/ecommerce-customers/_start":
+			started = true
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "POST" && r.URL.Path == "/_transform/ecommerce-customers/_stop":
+			if r.URL.Query().Get("wait_for_completion") != "true" {
+				t.Error("expected stopping the transform to wait for it to be stopped")
+			}
+			started = false
+			fmt.Fprint(w, `{"acknowledged": true}`)
+		case r.Method == "GET" && r.URL.Path == "/_transform/ecommerce-customers":
+			var transform map[string]interface{}
+			if err := json.Unmarshal([]byte(testTransformBody), &transform); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			transform["id"] = "ecommerce-customers"
+			transform["version"] = "7.10.2"
+			transform["create_time"] = 1609459200000
+			transform["settings"] = map[string]interface{}{}
+			json.NewEncoder(w).Encode(map[string]interface{}{"count": 1, "transforms": []interface{}{transform}})
+		case r.Method == "GET" && r.URL.Path == "/_transform/ecommerce-customers/_stats":
+			state := "stopped"
+			if started {
+				state = "started"
+			}
+			fmt.Fprintf(w, `{"count": 1, "transforms": [{"id": "ecommerce-customers", "state": %q}]}`, state)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, transformSchema, map[string]interface{}{
+		"transform_id": "ecommerce-customers",
+		"body":         testTransformBody,
+		"enabled":      true,
+	})
+	if err := resourceElasticsearchTransformCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"PUT /_transform/ecommerce-customers",
+		"POST /_transform/ecommerce-customers/_start",
+		"GET /_transform/ecommerce-customers",
+		"GET /_transform/ecommerce-customers/_stats",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+	if !resourceData.Get("enabled").(bool) {
+		t.Error("expected the transform to be started")
+	}
+	if !diffSuppressTransform("body", resourceData.Get("body").(string), testTransformBody, nil) {
+		t.Errorf("expected the body to round-trip, got %s", resourceData.Get("body").(string))
+	}
+
+	// disabling the transform only stops it
+	requests = nil
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"transform_id": "ecommerce-customers",
+		"body":         testTransformBody,
+		"enabled":      false,
+	})
+	diff, err := resourceElasticsearchTransform().Diff(resourceData.State(), config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := resourceElasticsearchTransform().Apply(resourceData.State(), diff, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected = []string{
+		"POST /_transform/ecommerce-customers/_stop",
+		"GET /_transform/ecommerce-customers",
+		"GET /_transform/ecommerce-customers/_stats",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+	if started || state.Attributes["enabled"] != "false" {
+		t.Errorf("expected the transform to be stopped, got enabled %s", state.Attributes["enabled"])
+	}
+}
+
+func TestElasticsearchTransformOpenDistro(t *testing.T) {
+	transformBody := `{"description": "customers", "source_index": "orders", "target_index": "customers", "page_size": 100, "schedule": {"interval": {"period": 1, "unit": "Minutes"}}, "groups": [{"terms": {"source_field": "customer_id", "target_field": "customer_id"}}]}`
+	var put map[string]map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "oss"}}`)
+		case r.Method == "PUT" && r.URL.Path == "/_opendistro/_transform/customers":
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"_id": "customers", "_version": 1, "_seq_no": 0, "_primary_term": 1}`)
+		case r.Method == "GET" && r.URL.Path == "/_opendistro/_transform/customers":
+			transform := put["transform"]
+			transform["transform_id"] = "customers"
+			transform["schema_version"] = 7
+			transform["updated_at"] = 1609459200000
+			transform["metadata_id"] = nil
+			json.NewEncoder(w).Encode(map[string]interface{}{"_id": "customers", "_version": 1, "_seq_no": 0, "_primary_term": 1, "transform": transform})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, transformSchema, map[string]interface{}{
+		"transform_id": "customers",
+		"body":         transformBody,
+	})
+	if err := resourceElasticsearchTransformCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if enabled, ok := put["transform"]["enabled"]; !ok || enabled != false {
+		t.Errorf("expected the transform to be created disabled, got %v", put)
+	}
+	if resourceData.Get("enabled").(bool) {
+		t.Error("expected the transform to be disabled")
+	}
+	if !diffSuppressTransform("body", resourceData.Get("body").(string), transformBody, nil) {
+		t.Errorf("expected the body to round-trip, got %s", resourceData.Get("body").(string))
+	}
+}
+
+func TestElasticsearchTransformCustomizeDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "ecommerce-customers",
+		Attributes: map[string]string{
+			"id":           "ecommerce-customers",
+			"transform_id": "ecommerce-customers",
+			"body":         `{"dest":{"index":"ecommerce-customers"},"pivot":{"group_by":{"customer_id":{"terms":{"field":"customer_id"}}}},"source":{"index":["orders"]}}`,
+			"enabled":      "false",
+		},
+	}
+
+	cases := []struct {
+		body        string
+		requiresNew bool
+	}{
+		{`{"dest":{"index":"ecommerce-customers"},"pivot":{"group_by":{"user_id":{"terms":{"field":"user_id"}}}},"source":{"index":["orders"]}}`, true},
+		{`{"description":"customers","dest":{"index":"ecommerce-customers"},"pivot":{"group_by":{"customer_id":{"terms":{"field":"customer_id"}}}},"source":{"index":["orders"]}}`, false},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"transform_id": "ecommerce-customers",
+			"body":         c.body,
+		})
+		diff, err := resourceElasticsearchTransform().Diff(state, config, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if diff == nil {
+			t.Fatalf("expected a diff for %s", c.body)
+		}
+		if diff.RequiresNew() != c.requiresNew {
+			t.Errorf("expected replacement to be %t for %s, got %t", c.requiresNew, c.body, diff.RequiresNew())
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 
@@ -13,34 +14,63 @@ import (
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
+var xPackLicenseSchema = map[string]*schema.Schema{
+	"license": {
+		Type:             schema.TypeString,
+		Optional:         true,
+		DiffSuppressFunc: diffSuppressLicense,
+	},
+	"use_basic_license": {
+		Type:     schema.TypeBool,
+		Required: true,
+	},
+	"license_json": {
+		Type:     schema.TypeString,
+		Computed: true,
+	},
+	"status": {
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The status of the license in the cluster, e.g. `active` or `expired`.",
+	},
+}
+
 func resourceElasticsearchXpackLicense() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchLicenseCreate,
-		Read:   resourceElasticsearchLicenseRead,
-		Update: resourceElasticsearchLicenseUpdate,
-		Delete: resourceElasticsearchLicenseDelete,
-		Schema: map[string]*schema.Schema{
-			"license": {
-				Type:             schema.TypeString,
-				Optional:         true,
-				DiffSuppressFunc: diffSuppressLicense,
-			},
-			"use_basic_license": {
-				Type:     schema.TypeBool,
-				Required: true,
-			},
-			"license_json": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-		},
+		Create:        resourceElasticsearchLicenseCreate,
+		Read:          resourceElasticsearchLicenseRead,
+		Update:        resourceElasticsearchLicenseUpdate,
+		Delete:        resourceElasticsearchLicenseDelete,
+		CustomizeDiff: resourceElasticsearchLicenseCustomizeDiff,
+		Schema:        xPackLicenseSchema,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 	}
 }
 
+// resourceElasticsearchLicenseCustomizeDiff plans to post the license again
+// when the license in the cluster has expired, so that replacing an expired
+// license shows up as a transition of its status, even when the server
+// normalized fields of the new license make its body look unchanged.
+func resourceElasticsearchLicenseCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || d.Get("use_basic_license").(bool) {
+		return nil
+	}
+
+	if o, _ := d.GetChange("status"); o.(string) == "expired" {
+		log.Printf("[INFO] License %s has expired, planning to post the license again", d.Id())
+		return d.SetNew("status", "active")
+	}
+
+	return nil
+}
+
 func resourceElasticsearchLicenseCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := checkXPackDistribution(meta, "licenses", "X-Pack"); err != nil {
+		return err
+	}
+
 	id, err := resourceElasticsearchCreateXpackLicense(d, meta)
 	if err != nil {
 		return err
@@ -68,6 +98,7 @@ func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{})
 	ds := &resourceDataSetter{d: d}
 	ds.set("use_basic_license", d.Get("use_basic_license").(bool))
 	ds.set("license", d.Get("license").(string))
+	ds.set("status", l.Status)
 
 	out, err := json.Marshal(l)
 	if err != nil {
@@ -77,28 +108,18 @@ func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{})
 	return ds.err
 }
 
+// resourceElasticsearchLicenseDelete reverts the cluster to a basic license,
+// as deleting a license through the API leaves the cluster without one.
 func resourceElasticsearchLicenseDelete(d *schema.ResourceData, meta interface{}) error {
-	var err error
-
-	esClient, err := getClient(meta.(*ProviderConf))
+	l, err := resourceElasticsearchGetXpackLicense(meta)
 	if err != nil {
 		return err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   "/_license",
-		})
-	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   "/_xpack/license",
-		})
-	default:
-		return errors.New("License is only supported by the elasticsearch >= v6!")
-	}
-	if err != nil {
+
+	// starting a basic license fails when it is already in use
+	if l.Type == "basic" {
+		log.Printf("[INFO] skipping starting basic license because already enabled %s", d.Id())
+	} else if _, err := resourceElasticsearchPostBasicLicense(meta); err != nil {
 		return err
 	}
 
@@ -123,14 +144,18 @@ func resourceElasticsearchGetXpackLicense(meta interface{}) (License, error) {
 			Method: "GET",
 			Path:   "/_license",
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
 			Method: "GET",
 			Path:   "/_xpack/license",
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
 		return *license, errors.New("License is only supported by the elasticsearch >= v6!")
 	}
@@ -154,8 +179,8 @@ func resourceElasticsearchCreateXpackLicense(d *schema.ResourceData, meta interf
 	var l License
 	var err error
 	if !useBasicLicense {
-		l, err = resourceElasticsearchPutEnterpriseLicense(license, meta)
-	} else if d.Id() == "" {
+		l, err = resourceElasticsearchPostEnterpriseLicense(license, meta)
+	} else if d.Id() == "" || d.HasChange("use_basic_license") {
 		l, err = resourceElasticsearchPostBasicLicense(meta)
 	} else {
 		log.Printf("[INFO] skipping creating basic license because already enabled %s", d.Id())
@@ -168,8 +193,13 @@ func resourceElasticsearchCreateXpackLicense(d *schema.ResourceData, meta interf
 	return l.Uid, nil
 }
 
-func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (License, error) {
+// resourceElasticsearchPostEnterpriseLicense posts the license and returns the
+// license in use afterwards. The license is acknowledged, as it may disable
+// features of the current license.
+func resourceElasticsearchPostEnterpriseLicense(l string, meta interface{}) (License, error) {
 	request := fmt.Sprintf(`{"licenses": [%s]}`, l)
+	params := url.Values{}
+	params.Set("acknowledge", "true")
 
 	var emptyLicense License
 	var body json.RawMessage
@@ -182,19 +212,25 @@ func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (Lice
 	case *elastic7.Client:
 		var res *elastic7.Response
 		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "PUT",
-			Path:   "/_license?acknowledge=true",
+			Method: "POST",
+			Path:   "/_license",
+			Params: params,
 			Body:   request,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	case *elastic6.Client:
 		var res *elastic6.Response
 		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "PUT",
-			Path:   "/_xpack/license?acknowledge=true",
+			Method: "POST",
+			Path:   "/_xpack/license",
+			Params: params,
 			Body:   request,
 		})
-		body = res.Body
+		if err == nil {
+			body = res.Body
+		}
 	default:
 		return emptyLicense, errors.New("License is only supported by the elastic library >= v6!")
 	}
@@ -202,13 +238,19 @@ func resourceElasticsearchPutEnterpriseLicense(l string, meta interface{}) (Lice
 	if err != nil {
 		return emptyLicense, err
 	}
-	var licenseResponse map[string][]License
+	var licenseResponse struct {
+		Acknowledged  bool   `json:"acknowledged"`
+		LicenseStatus string `json:"license_status"`
+	}
 
 	if err := json.Unmarshal(body, &licenseResponse); err != nil {
 		return emptyLicense, fmt.Errorf("Error unmarshalling license body: %+v: %+v", err, body)
 	}
+	if licenseResponse.LicenseStatus != "valid" {
+		return emptyLicense, fmt.Errorf("license was not accepted, the license status is %q", licenseResponse.LicenseStatus)
+	}
 
-	return licenseResponse["licenses"][0], err
+	return resourceElasticsearchGetXpackLicense(meta)
 }
 
 func resourceElasticsearchPostBasicLicense(meta interface{}) (License, error) {
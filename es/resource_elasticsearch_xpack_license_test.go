@@ -2,9 +2,14 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -61,6 +66,90 @@ func TestAccElasticsearchXpackLicense_Basic(t *testing.T) {
 	})
 }
 
+func TestElasticsearchXpackLicenseCreate(t *testing.T) {
+	var posted map[string][]map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "default"}}`)
+		case r.Method == "POST" && r.URL.Path == "/_license":
+			if r.URL.Query().Get("acknowledge") != "true" {
+				t.Errorf("expected the license to be acknowledged, got %s", r.URL.RawQuery)
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &posted); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"acknowledged": true, "license_status": "valid"}`)
+		case r.Method == "GET" && r.URL.Path == "/_license":
+			fmt.Fprint(w, `{"license": {"status": "active", "uid": "893361dc-9749-4997-93cb-802e3d7fa4xx", "type": "platinum", "issue_date": "2014-09-29T00:00:00.000Z", "issue_date_in_millis": 1411948800000, "max_nodes": 1, "issued_to": "issuedTo", "issuer": "issuer"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, xPackLicenseSchema, map[string]interface{}{
+		"use_basic_license": false,
+		"license":           `{"uid":"893361dc-9749-4997-93cb-802e3d7fa4xx","type":"platinum","issue_date_in_millis":1411948800000,"max_nodes":1,"issued_to":"issuedTo","issuer":"issuer","signature":"xx"}`,
+	})
+	if err := resourceElasticsearchLicenseCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(posted["licenses"]) != 1 || posted["licenses"][0]["signature"] != "xx" {
+		t.Errorf("expected the license to be posted in a list of licenses, got %+v", posted)
+	}
+	if resourceData.Id() != "893361dc-9749-4997-93cb-802e3d7fa4xx" {
+		t.Errorf("expected the ID to be the uid of the license, got %s", resourceData.Id())
+	}
+	if status := resourceData.Get("status").(string); status != "active" {
+		t.Errorf("expected the license to be active, got %s", status)
+	}
+}
+
+func TestElasticsearchXpackLicenseUnsupportedDistribution(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && r.URL.Path == "/" {
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "oss"}}`)
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, xPackLicenseSchema, map[string]interface{}{
+		"use_basic_license": true,
+	})
+	err := resourceElasticsearchLicenseCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "does not include X-Pack") {
+		t.Errorf("expected an error about X-Pack being unavailable, got %v", err)
+	}
+}
+
+func TestDiffSuppressLicense(t *testing.T) {
+	configured := `{"uid":"893361dc","type":"platinum","issue_date_in_millis":1411948800000,"signature":"xx"}`
+	normalized := `{"uid":"893361dc","type":"platinum","issue_date":"2014-09-29T00:00:00.000Z","issue_date_in_millis":1411948800000,"status":"active","signature":"xx"}`
+	if !diffSuppressLicense("license", normalized, configured, nil) {
+		t.Error("expected the dates derived by the server to be ignored")
+	}
+
+	renewed := `{"uid":"4c2b3d1f","type":"platinum","issue_date_in_millis":1614948800000,"signature":"yy"}`
+	if diffSuppressLicense("license", configured, renewed, nil) {
+		t.Error("expected a renewed license to be a diff")
+	}
+}
+
 func testCheckElasticsearchLicenseExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -62,6 +62,10 @@ func resourceElasticsearchXpackRoleMapping() *schema.Resource {
 }
 
 func resourceElasticsearchXpackRoleMappingCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkXPackDistribution(m, "role mappings", "X-Pack security"); err != nil {
+		return err
+	}
+
 	name := d.Get("role_mapping_name").(string)
 
 	reqBody, err := buildPutRoleMappingBody(d, m)
@@ -81,6 +85,11 @@ func resourceElasticsearchXpackRoleMappingRead(d *schema.ResourceData, m interfa
 	roleMapping, err := xpackGetRoleMapping(d, m, d.Id())
 	if err != nil {
 		fmt.Println("Error during read")
+		if err == errObjNotFound {
+			fmt.Printf("[WARN] Role mapping %s not found. Removing from state\n", d.Id())
+			d.SetId("")
+			return nil
+		}
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
 			fmt.Printf("[WARN] Role mapping %s not found. Removing from state\n", d.Id())
 			d.SetId("")
@@ -142,6 +151,7 @@ func resourceElasticsearchXpackRoleMappingDelete(d *schema.ResourceData, m inter
 			d.SetId("")
 			return nil
 		}
+		return err
 	}
 	d.SetId("")
 	return nil
@@ -244,7 +254,10 @@ func elastic6GetRoleMapping(client *elastic6.Client, name string) (XPackSecurity
 	if err != nil {
 		return XPackSecurityRoleMapping{}, err
 	}
-	obj := (*res)[name]
+	obj, ok := (*res)[name]
+	if !ok {
+		return XPackSecurityRoleMapping{}, errObjNotFound
+	}
 	roleMapping := XPackSecurityRoleMapping{}
 	roleMapping.Name = name
 	roleMapping.Roles = obj.Roles
@@ -268,7 +281,10 @@ func elastic7GetRoleMapping(client *elastic7.Client, name string) (XPackSecurity
 	if err != nil {
 		return XPackSecurityRoleMapping{}, err
 	}
-	obj := (*res)[name]
+	obj, ok := (*res)[name]
+	if !ok {
+		return XPackSecurityRoleMapping{}, errObjNotFound
+	}
 	roleMapping := XPackSecurityRoleMapping{}
 	roleMapping.Name = name
 	roleMapping.Roles = obj.Roles
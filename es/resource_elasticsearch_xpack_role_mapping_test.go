@@ -2,7 +2,12 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -229,6 +234,103 @@ resource "elasticsearch_xpack_role_mapping" "test" {
 `, resourceName)
 }
 
+func TestElasticsearchXpackRoleMappingCreate(t *testing.T) {
+	var put map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "default"}}`)
+		case r.Method == "PUT" && r.URL.Path == "/_security/role_mapping/admins":
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			fmt.Fprint(w, `{"role_mapping": {"created": true}}`)
+		case r.Method == "GET" && r.URL.Path == "/_security/role_mapping/admins":
+			// the roles are returned in another order than they were put
+			fmt.Fprint(w, `{"admins": {"enabled": true, "roles": ["superuser", "kibana_admin"], "rules": {"field": {"username": "alice"}}, "metadata": {"version": 1}}}`)
+		case r.Method == "GET" && r.URL.Path == "/_security/role_mapping/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	rules := `{"field": {"username": "alice"}}`
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchXpackRoleMapping().Schema, map[string]interface{}{
+		"role_mapping_name": "admins",
+		"roles":             []interface{}{"kibana_admin", "superuser"},
+		"rules":             rules,
+		"metadata":          `{"version": 1}`,
+	})
+	if err := resourceElasticsearchXpackRoleMappingCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"enabled":  true,
+		"roles":    []interface{}{"kibana_admin", "superuser"},
+		"rules":    map[string]interface{}{"field": map[string]interface{}{"username": "alice"}},
+		"metadata": map[string]interface{}{"version": float64(1)},
+	}
+	// the roles are put in the order of the set
+	if roles, _ := put["roles"].([]interface{}); len(roles) == 2 && roles[0] == "superuser" {
+		roles[0], roles[1] = roles[1], roles[0]
+	}
+	if !reflect.DeepEqual(put, expected) {
+		t.Errorf("expected the role mapping %v to be put, got %v", expected, put)
+	}
+	if resourceData.Id() != "admins" {
+		t.Errorf("expected the ID admins, got %q", resourceData.Id())
+	}
+	if roles := resourceData.Get("roles").(*schema.Set); roles.Len() != 2 || !roles.Contains("superuser") || !roles.Contains("kibana_admin") {
+		t.Errorf("expected the roles to be read back, got %v", roles.List())
+	}
+	if !suppressEquivalentJson("rules", resourceData.Get("rules").(string), rules, nil) {
+		t.Errorf("expected the rules to round-trip, got %s", resourceData.Get("rules").(string))
+	}
+
+	resourceData.SetId("missing")
+	if err := resourceElasticsearchXpackRoleMappingRead(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resourceData.Id() != "" {
+		t.Errorf("expected a missing role mapping to be removed from the state, got ID %q", resourceData.Id())
+	}
+}
+
+func TestElasticsearchXpackRoleMappingUnsupportedDistribution(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && r.URL.Path == "/" {
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "oss"}}`)
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchXpackRoleMapping().Schema, map[string]interface{}{
+		"role_mapping_name": "admins",
+		"roles":             []interface{}{"superuser"},
+		"rules":             `{"field": {"username": "alice"}}`,
+	})
+	err := resourceElasticsearchXpackRoleMappingCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "does not include X-Pack security") {
+		t.Errorf("expected an error about X-Pack security being unavailable, got %v", err)
+	}
+}
+
 func TestAccRoleMappingResource_importBasic(t *testing.T) {
 	provider := Provider().(*schema.Provider)
 	err := provider.Configure(&terraform.ResourceConfig{})
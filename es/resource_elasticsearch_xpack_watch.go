@@ -3,7 +3,6 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 
@@ -31,6 +30,12 @@ var xPackWatchSchema = map[string]*schema.Schema{
 			return json
 		},
 	},
+	"active": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Whether the watch is active. Inactive watches are stored but never triggered.",
+	},
 }
 
 func resourceElasticsearchDeprecatedWatch() *schema.Resource {
@@ -61,6 +66,10 @@ func resourceElasticsearchXpackWatch() *schema.Resource {
 }
 
 func resourceElasticsearchWatchCreate(d *schema.ResourceData, m interface{}) error {
+	if err := resourceElasticsearchCheckWatcher(m); err != nil {
+		return err
+	}
+
 	// Determine whether the watch already exists, otherwise the API will
 	// override an existing watch with the name.
 	watchID := d.Get("watch_id").(string)
@@ -100,6 +109,7 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	}
 
 	var watch []byte
+	active := true
 
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
@@ -109,11 +119,22 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	case *elastic7.Client:
 		watchResponse := res.(*elastic7.XPackWatcherGetWatchResponse)
 		watch, err = json.Marshal(watchResponse.Watch)
+		if watchResponse.Status != nil && watchResponse.Status.State != nil {
+			active = watchResponse.Status.State.Active
+		}
 	case *elastic6.Client:
 		watchResponse := res.(*elastic6.XPackWatcherGetWatchResponse)
 		watch, err = json.Marshal(watchResponse.Watch)
+		if watchResponse.Status != nil && watchResponse.Status.State != nil {
+			active = watchResponse.Status.State.Active
+		}
+	}
+
+	if err != nil {
+		return err
 	}
 
+	watch, err = normalizeWatch(watch)
 	if err != nil {
 		return err
 	}
@@ -121,12 +142,20 @@ func resourceElasticsearchWatchRead(d *schema.ResourceData, m interface{}) error
 	ds := &resourceDataSetter{d: d}
 	ds.set("body", string(watch))
 	ds.set("watch_id", d.Id())
+	ds.set("active", active)
 
 	return ds.err
 }
 
 func resourceElasticsearchWatchUpdate(d *schema.ResourceData, m interface{}) error {
-	_, err := resourceElasticsearchPutWatch(d, m)
+	// Putting the watch resets its execution status, so only toggle the
+	// activation state when the body itself is unchanged.
+	var err error
+	if d.HasChange("body") {
+		_, err = resourceElasticsearchPutWatch(d, m)
+	} else if d.HasChange("active") {
+		err = resourceElasticsearchActivateWatch(d.Id(), d.Get("active").(bool), m)
+	}
 
 	if err != nil {
 		return err
@@ -147,7 +176,7 @@ func resourceElasticsearchWatchDelete(d *schema.ResourceData, m interface{}) err
 	case *elastic6.Client:
 		_, err = client.XPackWatchDelete(d.Id()).Do(context.TODO())
 	default:
-		err = errors.New("watch resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "watch", MinimumVersion: "v6"}
 	}
 
 	return err
@@ -166,7 +195,7 @@ func resourceElasticsearchGetWatch(watchID string, m interface{}) (interface{},
 	case *elastic6.Client:
 		res, err = client.XPackWatchGet(watchID).Do(context.TODO())
 	default:
-		err = errors.New("watch resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "watch", MinimumVersion: "v6"}
 	}
 
 	return res, err
@@ -175,6 +204,7 @@ func resourceElasticsearchGetWatch(watchID string, m interface{}) (interface{},
 func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (string, error) {
 	watchID := d.Get("watch_id").(string)
 	watchJSON := d.Get("body").(string)
+	active := d.Get("active").(bool)
 
 	var err error
 	esClient, err := getClient(m.(*ProviderConf))
@@ -185,13 +215,15 @@ func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (strin
 	case *elastic7.Client:
 		_, err = client.XPackWatchPut(watchID).
 			Body(watchJSON).
+			Active(active).
 			Do(context.TODO())
 	case *elastic6.Client:
 		_, err = client.XPackWatchPut(watchID).
 			Body(watchJSON).
+			Active(active).
 			Do(context.TODO())
 	default:
-		err = errors.New("watch resource not implemented prior to Elastic v6")
+		err = &UnsupportedVersionError{Resource: "watch", MinimumVersion: "v6"}
 	}
 
 	if err != nil {
@@ -200,3 +232,48 @@ func resourceElasticsearchPutWatch(d *schema.ResourceData, m interface{}) (strin
 
 	return watchID, nil
 }
+
+func resourceElasticsearchActivateWatch(watchID string, active bool, m interface{}) error {
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		if active {
+			_, err = client.XPackWatchActivate(watchID).Do(context.TODO())
+		} else {
+			_, err = client.XPackWatchDeactivate(watchID).Do(context.TODO())
+		}
+	case *elastic6.Client:
+		if active {
+			_, err = client.XPackWatchActivate(watchID).Do(context.TODO())
+		} else {
+			_, err = client.XPackWatchDeactivate(watchID).Do(context.TODO())
+		}
+	default:
+		err = &UnsupportedVersionError{Resource: "watch", MinimumVersion: "v6"}
+	}
+
+	return err
+}
+
+// resourceElasticsearchCheckWatcher returns an error when the cluster is a
+// distribution that does not ship Watcher, rather than surfacing the opaque
+// "no handler found" response of the _watcher endpoints.
+func resourceElasticsearchCheckWatcher(m interface{}) error {
+	return checkXPackDistribution(m, "watches", "Watcher")
+}
+
+// normalizeWatch removes the execution status the server adds to a watch, so
+// that it is not reported as a difference from the configured body.
+func normalizeWatch(watch []byte) ([]byte, error) {
+	var body map[string]interface{}
+	if err := json.Unmarshal(watch, &body); err != nil {
+		return nil, err
+	}
+	delete(body, "status")
+	delete(body, "_status")
+
+	return json.Marshal(body)
+}
@@ -3,6 +3,10 @@ package es
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -151,3 +155,121 @@ resource "elasticsearch_xpack_watch" "test_watch" {
 EOF
 }
 `
+
+func TestElasticsearchWatchActive(t *testing.T) {
+	var requests []string
+	stored, active := false, true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/":
+			fmt.Fprint(w, `{"version": {"number": "7.10.0", "build_flavor": "default"}}`)
+		case r.Method == "GET" && r.URL.Path == "/_watcher/watch/my-watch":
+			if !stored {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"_id": "my-watch", "found": false}`)
+				return
+			}
+			fmt.Fprintf(w, `{
+  "_id": "my-watch",
+  "found": true,
+  "status": {"state": {"active": %t, "timestamp": "2021-01-01T00:00:00.000Z"}, "version": 1},
+  "watch": {
+    "trigger": {"schedule": {"interval": "10m"}},
+    "input": {"simple": {}},
+    "actions": {"log": {"logging": {"text": "hello"}}},
+    "status": {"state": {"active": %t, "timestamp": "2021-01-01T00:00:00.000Z"}, "version": 1}
+  }
+}`, active, active)
+		case r.Method == "PUT" && r.URL.Path == "/_watcher/watch/my-watch":
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), "status") {
+				t.Errorf("expected the watch status not to be sent, got %s", body)
+			}
+			stored, active = true, r.URL.Query().Get("active") != "false"
+			fmt.Fprint(w, `{"_id": "my-watch", "_version": 1, "created": true}`)
+		case r.Method == "PUT" && r.URL.Path == "/_watcher/watch/my-watch/_deactivate":
+			active = false
+			fmt.Fprint(w, `{"status": {"state": {"active": false}}}`)
+		case r.Method == "PUT" && r.URL.Path == "/_watcher/watch/my-watch/_activate":
+			active = true
+			fmt.Fprint(w, `{"status": {"state": {"active": true}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	meta := testProviderMeta(t, ts.URL)
+
+	body := `{"trigger": {"schedule": {"interval": "10m"}}, "input": {"simple": {}}, "actions": {"log": {"logging": {"text": "hello"}}}}`
+	resourceData := schema.TestResourceDataRaw(t, xPackWatchSchema, map[string]interface{}{
+		"watch_id": "my-watch",
+		"body":     body,
+	})
+	if err := resourceElasticsearchWatchCreate(resourceData, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !resourceData.Get("active").(bool) {
+		t.Error("expected the watch to be active")
+	}
+	if strings.Contains(resourceData.Get("body").(string), "status") {
+		t.Errorf("expected the watch status to be removed from the body, got %s", resourceData.Get("body"))
+	}
+
+	for _, want := range []bool{false, true} {
+		requests = nil
+		state := resourceData.State()
+		if state == nil {
+			t.Fatal("expected the watch to be in the state")
+		}
+		state.Attributes["active"] = fmt.Sprintf("%t", !want)
+		diff := &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"active": {Old: fmt.Sprintf("%t", !want), New: fmt.Sprintf("%t", want)},
+			},
+		}
+		var err error
+		resourceData, err = schema.InternalMap(xPackWatchSchema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := resourceElasticsearchWatchUpdate(resourceData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if active != want || resourceData.Get("active").(bool) != want {
+			t.Errorf("expected the watch active state to be %t", want)
+		}
+		for _, r := range requests {
+			if r == "PUT /_watcher/watch/my-watch" {
+				t.Error("expected toggling active not to put the watch again")
+			}
+		}
+	}
+}
+
+func TestElasticsearchWatchUnsupportedDistribution(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && r.URL.Path == "/" {
+			fmt.Fprint(w, `{"version": {"number": "7.10.2", "build_flavor": "oss"}}`)
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"elasticsearch_version": "7.10.2",
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, xPackWatchSchema, map[string]interface{}{
+		"watch_id": "my-watch",
+		"body":     `{"trigger": {"schedule": {"interval": "10m"}}}`,
+	})
+	err := resourceElasticsearchWatchCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "does not include Watcher") {
+		t.Errorf("expected an error about Watcher being unavailable, got %v", err)
+	}
+}
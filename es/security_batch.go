@@ -0,0 +1,157 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+// securityBatchWindow is how long changes to the security plugin are
+// collected, from the first change of a batch, before they are sent as a
+// single patch. Terraform applies resources concurrently, so the changes of an
+// apply made within the window share a batch.
+const securityBatchWindow = 500 * time.Millisecond
+
+// patchOperation is a JSON patch operation of the security plugin API.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type patchBatch struct {
+	ops  []patchOperation
+	done chan struct{}
+	errs []error
+}
+
+// patchBatcher coalesces the patch operations of resources applied
+// concurrently into a single PATCH request per API path and time window.
+// Patches are atomic, so a failed batch is sent again one operation at a
+// time, failing only the operations which fail on their own.
+type patchBatcher struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*patchBatch
+	flush   func(path string, ops []patchOperation) error
+}
+
+func newPatchBatcher(window time.Duration, flush func(path string, ops []patchOperation) error) *patchBatcher {
+	return &patchBatcher{
+		window:  window,
+		pending: make(map[string]*patchBatch),
+		flush:   flush,
+	}
+}
+
+// add queues an operation for the API path and waits until the batch it is
+// part of has been sent, returning the error of the batch.
+func (b *patchBatcher) add(path string, op patchOperation) error {
+	b.mu.Lock()
+	batch, ok := b.pending[path]
+	if !ok {
+		batch = &patchBatch{done: make(chan struct{})}
+		b.pending[path] = batch
+		time.AfterFunc(b.window, func() {
+			b.send(path, batch)
+		})
+	}
+	i := len(batch.ops)
+	batch.ops = append(batch.ops, op)
+	b.mu.Unlock()
+
+	<-batch.done
+	return batch.errs[i]
+}
+
+func (b *patchBatcher) send(path string, batch *patchBatch) {
+	b.mu.Lock()
+	delete(b.pending, path)
+	b.mu.Unlock()
+
+	log.Printf("[INFO] Sending %d batched operations to %s", len(batch.ops), path)
+	batch.errs = make([]error, len(batch.ops))
+	err := b.flush(path, batch.ops)
+	if err != nil && len(batch.ops) > 1 {
+		log.Printf("[WARN] Batch of %d operations to %s failed, sending them one at a time: %+v", len(batch.ops), path, err)
+		for i, op := range batch.ops {
+			batch.errs[i] = b.flush(path, []patchOperation{op})
+		}
+	} else {
+		for i := range batch.errs {
+			batch.errs[i] = err
+		}
+	}
+	close(batch.done)
+}
+
+// securityPatchFlush returns a function sending operations to the security
+// plugin API of the cluster of the configuration.
+func securityPatchFlush(conf *ProviderConf) func(path string, ops []patchOperation) error {
+	return func(path string, ops []patchOperation) error {
+		body, err := json.Marshal(ops)
+		if err != nil {
+			return err
+		}
+
+		esClient, err := getClient(conf)
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = securityPerformRequest(client, elastic7.PerformRequestOptions{
+				Method: "PATCH",
+				Path:   path,
+				Body:   string(body),
+			})
+		default:
+			err = errors.New("batching of security changes not implemented prior to Elastic v7")
+		}
+
+		if err != nil {
+			return fmt.Errorf("error patching %s with a batch of %d operations: %+v", path, len(ops), err)
+		}
+		return nil
+	}
+}
+
+// jsonPointer returns the JSON pointer of a top level key, as used in the
+// paths of patch operations.
+func jsonPointer(key string) string {
+	return "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// securityConflictRetries bounds the retries of a write to the security plugin
+// API which conflicts with a concurrent write.
+const securityConflictRetries = 5
+
+// securityConflictBackoff is the delay before the first retry of a
+// conflicting write, doubled for each further retry.
+var securityConflictBackoff = 250 * time.Millisecond
+
+// securityPerformRequest performs a write to the security plugin API,
+// retrying it unchanged with backoff on version conflicts. The security config
+// index serializes writes, so concurrent writes of roles, users or mappings
+// can fail with a 409 even if they change different objects, and the same
+// write succeeds once the concurrent writes are done.
+func securityPerformRequest(client *elastic7.Client, opts elastic7.PerformRequestOptions) (*elastic7.Response, error) {
+	backoff := securityConflictBackoff
+	for retries := 0; ; retries++ {
+		res, err := client.PerformRequest(context.TODO(), opts)
+		if !elastic7.IsConflict(err) || retries == securityConflictRetries {
+			return res, err
+		}
+
+		log.Printf("[INFO] Version conflict writing %s, retrying in %s: %+v", opts.Path, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
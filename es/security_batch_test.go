@@ -0,0 +1,161 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestPatchBatcher(t *testing.T) {
+	var flushes [][]patchOperation
+	var mu sync.Mutex
+	batcher := newPatchBatcher(50*time.Millisecond, func(path string, ops []patchOperation) error {
+		mu.Lock()
+		defer mu.Unlock()
+		flushes = append(flushes, ops)
+		if len(flushes) > 1 {
+			return errors.New("failed batch")
+		}
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := batcher.add("/roles", patchOperation{Op: "add", Path: jsonPointer(fmt.Sprintf("role%d", i))})
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(flushes) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(flushes))
+	}
+	if len(flushes[0]) != 5 {
+		t.Errorf("expected 5 operations in the batch, got %d", len(flushes[0]))
+	}
+
+	// a later change is sent in a new batch, which reports its error
+	if err := batcher.add("/roles", patchOperation{Op: "remove", Path: "/role0"}); err == nil {
+		t.Error("expected the error of the batch")
+	}
+	if len(flushes) != 2 {
+		t.Errorf("expected 2 batches, got %d", len(flushes))
+	}
+
+	// a failed batch is sent again one operation at a time, so only the
+	// failing operation fails
+	flushes = nil
+	batcher = newPatchBatcher(50*time.Millisecond, func(path string, ops []patchOperation) error {
+		mu.Lock()
+		defer mu.Unlock()
+		flushes = append(flushes, ops)
+		for _, op := range ops {
+			if op.Path == "/missing" {
+				return errors.New("no such path")
+			}
+		}
+		return nil
+	})
+	errs := make(map[string]error)
+	for _, path := range []string{"/role1", "/missing", "/role2"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			err := batcher.add("/roles", patchOperation{Op: "remove", Path: path})
+			mu.Lock()
+			errs[path] = err
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	if len(flushes) != 4 {
+		t.Errorf("expected the batch and 3 single operations to be sent, got %d", len(flushes))
+	}
+	if errs["/role1"] != nil || errs["/role2"] != nil || errs["/missing"] == nil {
+		t.Errorf("expected only the operation on /missing to fail, got %v", errs)
+	}
+}
+
+func TestJsonPointer(t *testing.T) {
+	cases := map[string]string{
+		"readall":  "/readall",
+		"a/b":      "/a~1b",
+		"tilde~me": "/tilde~0me",
+	}
+	for key, expected := range cases {
+		if actual := jsonPointer(key); actual != expected {
+			t.Errorf("expected pointer of %q to be %q, got %q", key, expected, actual)
+		}
+	}
+}
+
+func TestOpenDistroRoleBatchSecurityRequests(t *testing.T) {
+	var requests []*http.Request
+	var ops []patchOperation
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+			t.Errorf("err: %s", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "OK", "message": "Resource updated."}`)
+	}))
+	defer ts.Close()
+
+	meta := testProviderMetaWith(t, ts.URL, map[string]interface{}{
+		"batch_security_requests": true,
+	})
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"reader", "writer", "admin"} {
+		resourceData := schema.TestResourceDataRaw(t, resourceElasticsearchOpenDistroRole().Schema, map[string]interface{}{
+			"role_name":           name,
+			"cluster_permissions": []interface{}{"cluster_monitor"},
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := resourceElasticsearchPutOpenDistroRole(resourceData, meta); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(requests) != 1 {
+		t.Fatalf("expected the changes to be sent in 1 request, got %d", len(requests))
+	}
+	if requests[0].Method != "PATCH" || requests[0].URL.Path != "/_opendistro/_security/api/roles" {
+		t.Errorf("expected PATCH /_opendistro/_security/api/roles, got %s %s", requests[0].Method, requests[0].URL.Path)
+	}
+
+	var paths []string
+	for _, op := range ops {
+		if op.Op != "add" {
+			t.Errorf("expected add operations, got %s", op.Op)
+		}
+		paths = append(paths, op.Path)
+	}
+	sort.Strings(paths)
+	expected := []string{"/admin", "/reader", "/writer"}
+	if fmt.Sprint(paths) != fmt.Sprint(expected) {
+		t.Errorf("expected operations on %v, got %v", expected, paths)
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"log"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
@@ -76,6 +80,58 @@ func normalizeDestination(tpl map[string]interface{}) {
 	delete(tpl, "id")
 	delete(tpl, "last_update_time")
 	delete(tpl, "schema_version")
+	delete(tpl, "user")
+	delete(tpl, "reserved")
+}
+
+// alertingUserName returns the name of the user who saved an object of the
+// alerting plugin, from the user block the security plugin adds to it.
+func alertingUserName(object map[string]interface{}) string {
+	user, _ := object["user"].(map[string]interface{})
+	name, _ := user["name"].(string)
+	return name
+}
+
+// alertingTimestamp returns a time of an object of the alerting plugin, in
+// milliseconds since the epoch, as an RFC3339 timestamp.
+func alertingTimestamp(object map[string]interface{}, key string) string {
+	ms, ok := object[key].(float64)
+	if !ok {
+		return ""
+	}
+
+	return time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
+// normalizedScalars returns v with booleans and numbers given as strings
+// converted to JSON booleans and numbers, e.g. "true" to true and "8080" to
+// 8080, and without null values of objects, which are equivalent to missing
+// keys.
+func normalizedScalars(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, inner := range value {
+			if inner == nil {
+				delete(value, k)
+			} else {
+				value[k] = normalizedScalars(inner)
+			}
+		}
+	case []interface{}:
+		for i, inner := range value {
+			value[i] = normalizedScalars(inner)
+		}
+	case string:
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+		var f float64
+		if err := json.Unmarshal([]byte(value), &f); err == nil {
+			return f
+		}
+	}
+
+	return v
 }
 
 func normalizeMonitor(tpl map[string]interface{}) {
@@ -83,24 +139,110 @@ func normalizeMonitor(tpl map[string]interface{}) {
 		normalizeMonitorTriggers(triggers)
 	}
 
+	if inputs, ok := tpl["inputs"].([]interface{}); ok {
+		normalizeMonitorInputs(inputs)
+	}
+
 	delete(tpl, "id")
 	delete(tpl, "last_update_time")
 	delete(tpl, "enabled_time")
 	delete(tpl, "schema_version")
+	delete(tpl, "user")
+}
+
+// normalizeAnomalyDetector removes the fields set by the server, including
+// the IDs it generates for features and the user who saved the detector.
+func normalizeAnomalyDetector(detector map[string]interface{}) {
+	if features, ok := detector["feature_attributes"].([]interface{}); ok {
+		for _, f := range features {
+			if feature, ok := f.(map[string]interface{}); ok {
+				delete(feature, "feature_id")
+			}
+		}
+	}
+
+	delete(detector, "last_update_time")
+	delete(detector, "schema_version")
+	delete(detector, "user")
+}
+
+// normalizeTransform removes the fields set by the server: the ID, version
+// and creation time of Elasticsearch transforms, and the metadata and enabled
+// state of OpenDistro transforms, which is managed by the enabled attribute.
+func normalizeTransform(transform map[string]interface{}) {
+	for _, field := range []string{"id", "version", "create_time", "authorization", "transform_id", "schema_version", "updated_at", "enabled", "enabled_at", "metadata_id", "user"} {
+		delete(transform, field)
+	}
+
+	// Elasticsearch returns empty settings for transforms created without any
+	if settings, ok := transform["settings"].(map[string]interface{}); ok && len(settings) == 0 {
+		delete(transform, "settings")
+	}
+}
+
+// normalizeLicense removes the fields the server derives from the others when
+// returning a license, e.g. the formatted dates of the millisecond timestamps.
+func normalizeLicense(license map[string]interface{}) {
+	delete(license, "issue_date")
+	delete(license, "expiry_date")
+	delete(license, "start_date")
+	delete(license, "status")
 }
 
 func normalizeMonitorTriggers(triggers []interface{}) {
 	for _, t := range triggers {
 		if trigger, ok := t.(map[string]interface{}); ok {
-			delete(trigger, "id")
+			normalizeMonitorTrigger(trigger)
 
-			if actions, ok := trigger["actions"].([]interface{}); ok {
-				normalizeMonitorTriggerActions(actions)
+			// bucket level, document level and chained alert triggers are
+			// wrapped in an object named after their type
+			for k, v := range trigger {
+				if inner, ok := v.(map[string]interface{}); ok && strings.HasSuffix(k, "_trigger") {
+					normalizeMonitorTrigger(inner)
+				}
 			}
 		}
 	}
 }
 
+// normalizeMonitorInputs removes the defaults the server adds to the uri
+// inputs of cluster metrics monitors, and the url it derives from the
+// api_type and path.
+func normalizeMonitorInputs(inputs []interface{}) {
+	for _, i := range inputs {
+		input, _ := i.(map[string]interface{})
+		uri, ok := input["uri"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if apiType, _ := uri["api_type"].(string); apiType != "" {
+			delete(uri, "url")
+		}
+		if uri["path_params"] == "" {
+			delete(uri, "path_params")
+		}
+		if clusters, ok := uri["clusters"].([]interface{}); ok && len(clusters) == 0 {
+			delete(uri, "clusters")
+		}
+	}
+}
+
+func normalizeMonitorTrigger(trigger map[string]interface{}) {
+	delete(trigger, "id")
+
+	// painless is the default language of trigger conditions
+	if condition, ok := trigger["condition"].(map[string]interface{}); ok {
+		if script, ok := condition["script"].(map[string]interface{}); ok && script["lang"] == "painless" {
+			delete(script, "lang")
+		}
+	}
+
+	if actions, ok := trigger["actions"].([]interface{}); ok {
+		normalizeMonitorTriggerActions(actions)
+	}
+}
+
 func normalizeMonitorTriggerActions(actions []interface{}) {
 	for _, a := range actions {
 		action := a.(map[string]interface{})
@@ -108,19 +250,154 @@ func normalizeMonitorTriggerActions(actions []interface{}) {
 	}
 }
 
+// normalizeIngestPipelineOwner removes the _meta.managed_by stamped by the
+// ownership guard of ingest pipelines, and _meta if nothing else is left.
+func normalizeIngestPipelineOwner(pipeline map[string]interface{}) {
+	pipelineMeta, ok := pipeline["_meta"].(map[string]interface{})
+	if !ok || pipelineMeta["managed_by"] != ingestPipelineManagedBy {
+		return
+	}
+
+	delete(pipelineMeta, "managed_by")
+	if len(pipelineMeta) == 0 {
+		delete(pipeline, "_meta")
+	}
+}
+
 func normalizePolicy(tpl map[string]interface{}) {
+	// bodies of the resource wrap the policy like the PUT request
+	if policy, ok := tpl["policy"].(map[string]interface{}); ok && len(tpl) == 1 {
+		tpl = policy
+	}
+
 	delete(tpl, "last_updated_time")
 	delete(tpl, "policy_id")
+	delete(tpl, "schema_version")
 	// ignore if set to null in response (ie not specified)
 	if error_notification, ok := tpl["error_notification"]; ok {
 		if error_notification == nil {
 			delete(tpl, "error_notification")
 		}
 	}
+	if ismTemplate, ok := tpl["ism_template"]; ok && ismTemplate == nil {
+		delete(tpl, "ism_template")
+	}
+
+	states, _ := tpl["states"].([]interface{})
+	for _, s := range states {
+		state, _ := s.(map[string]interface{})
+		// terminal states have no transitions, which may be an empty array or
+		// omitted, and the server returns either
+		if transitions, _ := state["transitions"].([]interface{}); len(transitions) == 0 {
+			delete(state, "transitions")
+		}
+		actions, _ := state["actions"].([]interface{})
+		for _, a := range actions {
+			action, _ := a.(map[string]interface{})
+			if reflect.DeepEqual(action["retry"], defaultPolicyActionRetry) {
+				delete(action, "retry")
+			}
+		}
+	}
+
+	normalizePolicySchedules(tpl)
+}
+
+// policyTimeValueKeys are the keys of the time values of ISM policies, e.g.
+// the min_index_age of transitions and rollovers, or the interval of the
+// schedule of a rollup.
+var policyTimeValueKeys = map[string]bool{
+	"min_index_age":    true,
+	"min_rollover_age": true,
+	"timeout":          true,
+	"delay":            true,
+	"interval":         true,
+}
+
+// policyTimeUnits are the units of the periods of the job scheduler used by
+// ISM, as returned by the server.
+var policyTimeUnits = map[string]time.Duration{
+	"nanos":   time.Nanosecond,
+	"micros":  time.Microsecond,
+	"millis":  time.Millisecond,
+	"seconds": time.Second,
+	"minutes": time.Minute,
+	"hours":   time.Hour,
+	"days":    24 * time.Hour,
+}
+
+// normalizePolicySchedules canonicalizes the time values and cron schedules
+// anywhere in the policy, which the server may return in an equivalent form,
+// e.g. a time value as an object of a period and a unit.
+func normalizePolicySchedules(v interface{}) {
+	switch o := v.(type) {
+	case map[string]interface{}:
+		for k, value := range o {
+			if policyTimeValueKeys[k] {
+				o[k] = canonicalPolicyTimeValue(value)
+				continue
+			}
+			if cron, ok := value.(map[string]interface{}); ok && k == "cron" {
+				if expression, ok := cron["expression"].(string); ok {
+					cron["expression"] = strings.Join(strings.Fields(expression), " ")
+				}
+			}
+			normalizePolicySchedules(value)
+		}
+	case []interface{}:
+		for _, value := range o {
+			normalizePolicySchedules(value)
+		}
+	}
+}
+
+// canonicalPolicyTimeValue returns a time value of a policy, either a string
+// like 1d or an object like {"period": 1, "unit": "Days"}, as a Go duration
+// string. The start_time the server adds to intervals is ignored. Values
+// which can't be parsed are returned as is.
+func canonicalPolicyTimeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return canonicalTimeValue(value)
+	case map[string]interface{}:
+		period, ok := value["period"].(float64)
+		if !ok {
+			return v
+		}
+		unitName, _ := value["unit"].(string)
+		unit, ok := policyTimeUnits[strings.ToLower(unitName)]
+		if !ok {
+			return v
+		}
+		for k := range value {
+			if k != "period" && k != "unit" && k != "start_time" {
+				return v
+			}
+		}
+		return time.Duration(period * float64(unit)).String()
+	}
+
+	return v
+}
+
+// defaultPolicyActionRetry is added by ISM to actions without a retry.
+var defaultPolicyActionRetry = map[string]interface{}{
+	"count":   float64(3),
+	"backoff": "exponential",
+	"delay":   "1m",
 }
 
 func normalizeIndexTemplate(tpl map[string]interface{}) {
 	delete(tpl, "version")
+	// the default order and empty sections are omitted in responses
+	if order, ok := tpl["order"].(float64); ok && order == 0 {
+		delete(tpl, "order")
+	}
+	for _, k := range []string{"aliases", "mappings"} {
+		if m, ok := tpl[k].(map[string]interface{}); ok && len(m) == 0 {
+			delete(tpl, k)
+		}
+	}
 	if settings, ok := tpl["settings"]; ok {
 		if settingsMap, ok := settings.(map[string]interface{}); ok {
 			tpl["settings"] = normalizedIndexSettings(settingsMap)
@@ -145,6 +422,224 @@ func normalizeComposableIndexTemplate(tpl map[string]interface{}) {
 	}
 }
 
+func normalizeIndexAliases(aliases map[string]interface{}) {
+	for _, a := range aliases {
+		alias, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// routing is a shorthand for setting both index and search routing
+		if routing, ok := alias["routing"]; ok {
+			alias["index_routing"] = routing
+			alias["search_routing"] = routing
+			delete(alias, "routing")
+		}
+		for _, k := range []string{"index_routing", "search_routing"} {
+			if v, ok := alias[k]; ok {
+				alias[k] = fmt.Sprintf("%v", v)
+			}
+		}
+
+		if filter, ok := alias["filter"]; ok {
+			alias["filter"] = normalizedQuery(filter)
+		}
+	}
+}
+
+// normalizedQuery returns a query where the clauses of bool queries are
+// sorted, as their order doesn't change the result of the query.
+func normalizedQuery(query interface{}) interface{} {
+	switch q := query.(type) {
+	case map[string]interface{}:
+		for k, v := range q {
+			q[k] = normalizedQuery(v)
+		}
+
+		if b, ok := q["bool"].(map[string]interface{}); ok {
+			for _, occur := range []string{"must", "filter", "should", "must_not"} {
+				clauses, ok := b[occur]
+				if !ok {
+					continue
+				}
+				// a single clause is equivalent to a list of one
+				list, ok := clauses.([]interface{})
+				if !ok {
+					list = []interface{}{clauses}
+				}
+				sort.Slice(list, func(i, j int) bool {
+					ci, _ := json.Marshal(list[i])
+					cj, _ := json.Marshal(list[j])
+					return string(ci) < string(cj)
+				})
+				b[occur] = list
+			}
+		}
+
+		return q
+	case []interface{}:
+		for i, v := range q {
+			q[i] = normalizedQuery(v)
+		}
+
+		return q
+	default:
+		return q
+	}
+}
+
+// timeUnits are the units of time values of Elasticsearch, longest suffix
+// first.
+var timeUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"nanos", time.Nanosecond},
+	{"micros", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+}
+
+// timeValueRegexp matches the time values of Elasticsearch with a unit, e.g.
+// 30s.
+var timeValueRegexp = regexp.MustCompile(`^[0-9]+(nanos|micros|ms|s|m|h|d)$`)
+
+// canonicalTimeValue returns a time value as a Go duration string, e.g. 1s
+// for 1000ms, and -1 for any negative value, which disables e.g. refreshes.
+// Values which can't be parsed are returned as is.
+func canonicalTimeValue(interval string) string {
+	interval = strings.TrimSpace(interval)
+	if interval == "" {
+		return interval
+	}
+
+	number, unit := interval, time.Duration(0)
+	for _, u := range timeUnits {
+		if strings.HasSuffix(interval, u.suffix) {
+			number, unit = strings.TrimSuffix(interval, u.suffix), u.unit
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return interval
+	}
+	if value < 0 {
+		return "-1"
+	}
+	if unit == 0 {
+		// only 0 and -1 are accepted without a unit
+		if value == 0 {
+			return time.Duration(0).String()
+		}
+		return interval
+	}
+
+	return time.Duration(value * float64(unit)).String()
+}
+
+// byteSizeUnits are the units of byte size values of Elasticsearch, longest
+// suffix first.
+var byteSizeUnits = []struct {
+	suffix string
+	unit   float64
+}{
+	{"pb", 1 << 50},
+	{"tb", 1 << 40},
+	{"gb", 1 << 30},
+	{"mb", 1 << 20},
+	{"kb", 1 << 10},
+	{"b", 1},
+}
+
+// canonicalByteSize returns a byte size value as a number of bytes, e.g. 2mb
+// and 2048kb both become 2097152b, and -1 for any negative value. Units are
+// case-insensitive. Values which can't be parsed are returned as is.
+func canonicalByteSize(size string) string {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return size
+	}
+
+	number, unit := strings.ToLower(size), float64(0)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(number, u.suffix) {
+			number, unit = strings.TrimSuffix(number, u.suffix), u.unit
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return size
+	}
+	if value < 0 {
+		return "-1"
+	}
+	if unit == 0 {
+		// only 0 and -1 are accepted without a unit
+		if value == 0 {
+			return "0b"
+		}
+		return size
+	}
+
+	return strconv.FormatInt(int64(value*unit), 10) + "b"
+}
+
+// indexTimeSettings and indexByteSizeSettings are the index settings, without
+// the index. prefix, whose values have units which the server accepts in
+// several spellings, e.g. 1s and 1000ms.
+var (
+	indexTimeSettings = []string{
+		"refresh_interval",
+		"search.idle.after",
+		"gc_deletes",
+		"translog.sync_interval",
+		"unassigned.node_left.delayed_timeout",
+		"soft_deletes.retention_lease.period",
+	}
+	indexByteSizeSettings = []string{
+		"merge.policy.floor_segment",
+		"merge.policy.max_merged_segment",
+		"translog.flush_threshold_size",
+		"translog.generation_threshold_size",
+	}
+)
+
+// canonicalIndexSettingValue returns the value of an index setting, without
+// the index. prefix, with its unit canonicalized for time and byte size
+// settings. Other values are returned as is.
+func canonicalIndexSettingValue(key, value string) string {
+	for _, k := range indexTimeSettings {
+		if k == key {
+			return canonicalTimeValue(value)
+		}
+	}
+	for _, k := range indexByteSizeSettings {
+		if k == key {
+			return canonicalByteSize(value)
+		}
+	}
+	return value
+}
+
+// canonicalIndexSettings returns flattened settings with keys without the
+// index. prefix, e.g. both {"index": {"number_of_replicas": 1}} and
+// {"index.number_of_replicas": 1} become {"number_of_replicas": 1}.
+func canonicalIndexSettings(settings map[string]interface{}) map[string]interface{} {
+	f := make(map[string]interface{})
+	for k, v := range flattenMap(settings) {
+		f[strings.TrimPrefix(k, "index.")] = v
+	}
+
+	return f
+}
+
 func normalizedIndexSettings(settings map[string]interface{}) map[string]interface{} {
 	f := flattenMap(settings)
 	for k, v := range f {
@@ -154,6 +649,9 @@ func normalizedIndexSettings(settings map[string]interface{}) map[string]interfa
 			delete(f, k)
 		}
 	}
+	for k, v := range f {
+		f[k] = canonicalIndexSettingValue(strings.TrimPrefix(k, "index."), v.(string))
+	}
 
 	return f
 }
@@ -376,7 +874,7 @@ func flattenIndexPermissions(permissions []IndexPermissions, d *schema.ResourceD
 		}
 
 		useDeprecatedFls := false
-		if len(indexPermission) > 0 {
+		if idx < len(indexPermission) {
 			indexPermissionSchema := indexPermission[idx].(map[string]interface{})
 			fls := indexPermissionSchema["fls"].(*schema.Set).List()
 			useDeprecatedFls = len(fls) > 0
@@ -465,6 +963,51 @@ func hashSum(contents interface{}) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents.(string))))
 }
 
+// unquotedTemplatePrefix marks security plugin template variables which are
+// used as JSON values, e.g. ${user.roles}, while comparing DLS queries.
+const unquotedTemplatePrefix = "__unquoted_template__"
+
+var unquotedTemplateRegexp = regexp.MustCompile(`"` + unquotedTemplatePrefix + `(\$\{[^}"]*\})"`)
+
+// normalizedDocumentLevelSecurity returns a DLS query in a canonical JSON
+// form to compare it. Template variables of the security plugin, e.g.
+// ${user.name} or ${attr.internal.department}, are kept as is, also when they
+// aren't quoted, which isn't valid JSON. Queries which can't be parsed are
+// returned as is.
+func normalizedDocumentLevelSecurity(dls string) string {
+	var quoted strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(dls); i++ {
+		c := dls[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && strings.HasPrefix(dls[i:], "${"):
+			if end := strings.IndexByte(dls[i:], '}'); end > 0 {
+				quoted.WriteString(strconv.Quote(unquotedTemplatePrefix + dls[i:i+end+1]))
+				i += end
+				continue
+			}
+		}
+		quoted.WriteByte(c)
+	}
+
+	var query interface{}
+	if err := json.Unmarshal([]byte(quoted.String()), &query); err != nil {
+		return dls
+	}
+	normalized, err := json.Marshal(query)
+	if err != nil {
+		return dls
+	}
+
+	return unquotedTemplateRegexp.ReplaceAllString(string(normalized), "$1")
+}
+
 func indexPermissionsHash(v interface{}) int {
 	var buf bytes.Buffer
 	m := v.(map[string]interface{})
@@ -484,8 +1027,11 @@ func indexPermissionsHash(v interface{}) int {
 		}
 	}
 
-	if v, ok := m["document_level_security"]; ok {
-		buf.WriteString(fmt.Sprintf("%s-", v.(string)))
+	// the DLS query is named dls in the OpenSearch role
+	for _, key := range []string{"document_level_security", "dls"} {
+		if v, ok := m[key]; ok {
+			buf.WriteString(fmt.Sprintf("%s-", normalizedDocumentLevelSecurity(v.(string))))
+		}
 	}
 
 	if v, ok := m["fls"]; ok {
@@ -583,3 +1129,54 @@ func elastic7GetVersion(client *elastic7.Client) (*version.Version, error) {
 	}
 	return version.NewVersion(versionString)
 }
+
+// UnsupportedVersionError is returned by resources used with a client for a
+// version of Elasticsearch older than the first one they support.
+type UnsupportedVersionError struct {
+	Resource       string
+	MinimumVersion string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s resource not implemented prior to Elastic %s", e.Resource, e.MinimumVersion)
+}
+
+// checkXPackDistribution returns an error when the cluster is a distribution
+// without the X-Pack feature, i.e. OpenSearch or the OSS distribution of
+// Elasticsearch. The objects of the feature are named in the error.
+func checkXPackDistribution(m interface{}, objects, feature string) error {
+	conf := m.(*ProviderConf)
+	if conf.flavor == OpenSearch {
+		return fmt.Errorf("%s are not supported by OpenSearch, which does not include %s", objects, feature)
+	}
+
+	info, err := getRootInfo(conf)
+	if err != nil {
+		log.Printf("[WARN] Unable to determine the cluster distribution: %+v", err)
+		return nil
+	}
+	switch {
+	case info.Version.Distribution == "opensearch":
+		return fmt.Errorf("%s are not supported by OpenSearch, which does not include %s", objects, feature)
+	case info.Version.BuildFlavor == "oss":
+		return fmt.Errorf("%s are not supported by the OSS distribution of Elasticsearch (including OpenDistro), which does not include %s", objects, feature)
+	}
+
+	return nil
+}
+
+// getOpenSearchClient returns the client for resources that only exist on
+// OpenSearch, e.g. those using the `_plugins` APIs.
+func getOpenSearchClient(conf *ProviderConf, resourceName string) (*elastic7.Client, error) {
+	esClient, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := esClient.(*elastic7.Client)
+	if !ok || conf.flavor != OpenSearch {
+		return nil, fmt.Errorf("%s is only supported on OpenSearch clusters", resourceName)
+	}
+
+	return client, nil
+}